@@ -3,10 +3,13 @@ package main
 import (
 	"NezordLauncher/pkg/auth"
 	"NezordLauncher/pkg/constants"
+	"NezordLauncher/pkg/downloader"
 	"NezordLauncher/pkg/instances"
 	"NezordLauncher/pkg/ipc"
 	"NezordLauncher/pkg/logging"
+	"NezordLauncher/pkg/services"
 	"NezordLauncher/pkg/settings"
+	"NezordLauncher/pkg/skins"
 	"context"
 	"fmt"
 	"os"
@@ -25,11 +28,19 @@ type App struct {
 	accountManager  *auth.AccountManager
 	instanceManager *instances.Manager
 	settingsManager *settings.Manager
+	skinCache       *skins.Cache
+	skinLibrary     *skins.Library
 
-	downloadCancel context.CancelFunc
-	downloadMu     sync.Mutex
+	localYggdrasil   *services.LocalYggdrasil
+	localYggdrasilMu sync.Mutex
+
+	downloads *downloader.Manager
+
+	loginCancel context.CancelFunc
+	loginMu     sync.Mutex
 
 	runningInstances map[string]*exec.Cmd
+	runningAccounts  map[string]string
 	runningMu        sync.Mutex
 }
 
@@ -57,6 +68,7 @@ type EventPayload struct {
 	Timestamp  string      `json:"timestamp"`
 	Source     string      `json:"source"`
 	InstanceID string      `json:"instanceId,omitempty"`
+	JobID      string      `json:"jobId,omitempty"`
 	Status     string      `json:"status,omitempty"`
 	Message    string      `json:"message,omitempty"`
 	Current      int         `json:"current,omitempty"`
@@ -70,12 +82,19 @@ type EventPayload struct {
 }
 
 func NewApp() *App {
-	return &App{
+	a := &App{
 		accountManager:   auth.NewAccountManager(),
 		instanceManager:  instances.NewManager(),
 		settingsManager:  settings.NewManager(),
+		skinCache:        skins.NewCache(),
+		skinLibrary:      skins.NewLibrary(),
+		downloads:        downloader.NewManager(settings.DefaultConcurrentDownloads, downloadWorkers),
 		runningInstances: make(map[string]*exec.Cmd),
+		runningAccounts:  make(map[string]string),
 	}
+	a.downloads.OnUpdate = a.emitDownloadJob
+	a.downloads.OnProgress = a.emitDownloadProgress
+	return a
 }
 
 func (a *App) EnableTestMode() {
@@ -133,6 +152,7 @@ func (a *App) startup(ctx context.Context) {
 	if err := a.settingsManager.Load(); err != nil {
 		logging.Error("Failed to load settings: %v", err)
 	}
+	a.applyDownloadSettings(a.settingsManager.Get())
 
 	if a.settingsManager.Data.DataPath != "" {
 		absPath, err := filepath.Abs(a.settingsManager.Data.DataPath)
@@ -164,6 +184,20 @@ func (a *App) startup(ctx context.Context) {
 		}
 	}
 
+	a.downloads.Index = downloader.OpenHashIndex(filepath.Join(constants.GetDataDir(), "cache", "hash-index.json"))
+
+	store, info, err := auth.OpenCredentialStore(a.settingsManager.Data.CredentialBackend, "")
+	if err != nil {
+		logging.Error("Failed to open credential store: %v", err)
+	} else {
+		auth.UseCredentialStore(store, info)
+		if info.FallbackReason != "" {
+			logging.Warn("OS keyring unavailable, using encrypted file store: %s", info.FallbackReason)
+		} else {
+			logging.Info("Using credential backend: %s", info.Active)
+		}
+	}
+
 	if err := a.accountManager.Load(); err != nil {
 		logging.Error("Failed to load accounts: %v", err)
 	}
@@ -171,6 +205,10 @@ func (a *App) startup(ctx context.Context) {
 	if err := a.instanceManager.Load(); err != nil {
 		logging.Error("Failed to load instances: %v", err)
 	}
+
+	if err := a.skinLibrary.Load(); err != nil {
+		logging.Error("Failed to load skin library: %v", err)
+	}
 }
 
 func (a *App) shutdown(ctx context.Context) {
@@ -188,11 +226,25 @@ func (a *App) shutdown(ctx context.Context) {
 	}
 	a.runningMu.Unlock()
 
-	// Cancel any active downloads
-	if a.downloadCancel != nil {
-		logging.Info("Cancelling active downloads")
-		a.downloadCancel()
+	a.loginMu.Lock()
+	if a.loginCancel != nil {
+		a.loginCancel()
+	}
+	a.loginMu.Unlock()
+
+	a.localYggdrasilMu.Lock()
+	if a.localYggdrasil != nil {
+		stopCtx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+		if err := a.localYggdrasil.Stop(stopCtx); err != nil {
+			logging.Error("Failed to stop local yggdrasil server: %v", err)
+		}
+		cancel()
 	}
+	a.localYggdrasilMu.Unlock()
+
+	// Cancel any active downloads
+	logging.Info("Cancelling active downloads")
+	a.downloads.CancelAll()
 
 	logging.Info("Application shutdown complete")
 	logging.Close()
@@ -2,8 +2,14 @@ package main
 
 import (
 	"NezordLauncher/pkg/auth"
+	"NezordLauncher/pkg/ipc"
+	"NezordLauncher/pkg/logging"
 	"NezordLauncher/pkg/validation"
+	"context"
 	"fmt"
+	"os"
+
+	wailsRun "github.com/wailsapp/wails/v2/pkg/runtime"
 )
 
 func (a *App) GetAccounts() []auth.Account {
@@ -24,6 +30,100 @@ func (a *App) LoginElyBy(username, password string) (*auth.Account, error) {
 	return a.accountManager.AddElyByAccount(username, password)
 }
 
+func (a *App) BeginElyByLogin(username, password string, keepRememberedProfile bool) (*auth.LoginResult, error) {
+	if username == "" || password == "" {
+		return nil, fmt.Errorf("username and password required")
+	}
+	return a.accountManager.BeginElyByLogin(username, password, keepRememberedProfile)
+}
+
+func (a *App) BeginYggdrasilLogin(serverURL, username, password string, keepRememberedProfile bool) (*auth.LoginResult, error) {
+	if err := validation.ValidateServerURL(serverURL); err != nil {
+		return nil, err
+	}
+	if username == "" || password == "" {
+		return nil, fmt.Errorf("username and password required")
+	}
+	return a.accountManager.BeginYggdrasilLogin(serverURL, username, password, keepRememberedProfile)
+}
+
+func (a *App) SelectLoginProfile(pendingID, profileID string) (*auth.Account, error) {
+	if err := validation.ValidateUUID(profileID); err != nil {
+		return nil, err
+	}
+	return a.accountManager.SelectProfile(pendingID, profileID)
+}
+
+func (a *App) LoginYggdrasil(serverURL, username, password string) (*auth.Account, error) {
+	if err := validation.ValidateServerURL(serverURL); err != nil {
+		return nil, err
+	}
+	if username == "" || password == "" {
+		return nil, fmt.Errorf("username and password required")
+	}
+	return a.accountManager.AddYggdrasilAccount(serverURL, username, password)
+}
+
+func (a *App) GetYggdrasilServerInfo(serverURL string) (*auth.YggdrasilServer, error) {
+	if err := validation.ValidateServerURL(serverURL); err != nil {
+		return nil, err
+	}
+	return auth.ResolveYggdrasilServer(serverURL)
+}
+
+func (a *App) LoginMicrosoft() (*auth.Account, error) {
+	a.loginMu.Lock()
+	if a.loginCancel != nil {
+		a.loginCancel()
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	a.loginCancel = cancel
+	a.loginMu.Unlock()
+
+	defer func() {
+		a.loginMu.Lock()
+		cancel()
+		a.loginCancel = nil
+		a.loginMu.Unlock()
+	}()
+
+	callbacks := auth.MicrosoftLoginCallbacks{
+		OnDeviceCode: func(code auth.DeviceCodeResponse) {
+			payload := newEventPayload("backend.auth", "", "waiting", code.Message)
+			payload.Meta = map[string]interface{}{
+				"userCode":        code.UserCode,
+				"verificationUri": code.VerificationURI,
+				"expiresIn":       code.ExpiresIn,
+			}
+			a.emit(ipc.EventAuthDeviceCode, payload)
+		},
+		OnStatus: func(message string) {
+			a.emitAuthStatus("running", message)
+		},
+	}
+
+	account, err := a.accountManager.AddMicrosoftAccount(ctx, callbacks)
+	if err != nil {
+		if ctx.Err() == context.Canceled {
+			a.emitAuthStatus("cancelled", "Microsoft login cancelled")
+			return nil, fmt.Errorf("cancelled")
+		}
+		a.emitAuthError(ErrCodeAuthMicrosoftFailed, "Microsoft login failed", err)
+		return nil, err
+	}
+
+	a.emitAuthStatus("completed", fmt.Sprintf("Logged in as %s", account.Username))
+	return account, nil
+}
+
+func (a *App) CancelMicrosoftLogin() {
+	a.loginMu.Lock()
+	defer a.loginMu.Unlock()
+	if a.loginCancel != nil {
+		a.loginCancel()
+	}
+}
+
 func (a *App) SetActiveAccount(uuid string) error {
 	if err := validation.ValidateUUID(uuid); err != nil {
 		return err
@@ -39,5 +139,138 @@ func (a *App) RemoveAccount(uuid string) error {
 	if err := validation.ValidateUUID(uuid); err != nil {
 		return err
 	}
-	return a.accountManager.RemoveAccount(uuid)
+	if err := a.accountManager.RemoveAccount(uuid); err != nil {
+		return err
+	}
+	a.skinCache.Invalidate(uuid)
+	return nil
+}
+
+func (a *App) SignOutAccount(uuid, password string) error {
+	if err := validation.ValidateUUID(uuid); err != nil {
+		return err
+	}
+	return a.accountManager.SignOutAccount(uuid, password)
+}
+
+func (a *App) emitAuthStatus(status, message string) {
+	a.emit(ipc.EventAuthStatus, newEventPayload("backend.auth", "", status, message))
+}
+
+func (a *App) emitAuthError(code, message string, err error) {
+	payload := newEventPayload("backend.auth", "", "failed", message)
+	payload.Error = &EventError{
+		Code:    code,
+		Message: message,
+	}
+	if err != nil {
+		payload.Error.Cause = err.Error()
+	}
+	a.emit(ipc.EventAuthError, payload)
+}
+
+func (a *App) GetCredentialBackend() auth.CredentialBackendInfo {
+	return auth.GetCredentialBackendInfo()
+}
+
+func (a *App) SetCredentialBackend(backend, passphrase string) (auth.CredentialBackendInfo, error) {
+	store, info, err := auth.OpenCredentialStore(backend, passphrase)
+	if err != nil {
+		return auth.GetCredentialBackendInfo(), err
+	}
+	if info.Locked {
+		return auth.GetCredentialBackendInfo(), auth.ErrCredentialsLocked
+	}
+
+	if err := a.accountManager.SwitchCredentialStore(store, info); err != nil {
+		return auth.GetCredentialBackendInfo(), err
+	}
+
+	s := a.settingsManager.Get()
+	s.CredentialBackend = backend
+	if err := a.settingsManager.Update(s); err != nil {
+		return info, err
+	}
+	return info, nil
+}
+
+func (a *App) UnlockCredentials(passphrase string) error {
+	store, info, err := auth.OpenCredentialStore(auth.CredentialBackendPassphrase, passphrase)
+	if err != nil {
+		return err
+	}
+	auth.UseCredentialStore(store, info)
+	return a.accountManager.Load()
+}
+
+const (
+	accountBundleExt       = ".nezord-accounts"
+	accountBundleSkinsFile = "skins/library.json"
+)
+
+type AccountImportResult struct {
+	Accounts      []auth.ImportedAccount `json:"accounts"`
+	Relogin       []auth.ImportedAccount `json:"relogin"`
+	SkinsImported int                    `json:"skinsImported"`
+}
+
+// ExportAccounts asks for a destination and writes an encrypted bundle. An empty path means the dialog was cancelled.
+func (a *App) ExportAccounts(passphrase string) (string, error) {
+	path, err := wailsRun.SaveFileDialog(a.ctx, wailsRun.SaveDialogOptions{
+		Title:           "Export accounts",
+		DefaultFilename: "accounts" + accountBundleExt,
+		Filters:         []wailsRun.FileFilter{{DisplayName: "Nezord account bundle", Pattern: "*" + accountBundleExt}},
+	})
+	if err != nil || path == "" {
+		return "", err
+	}
+	return path, a.exportAccountsTo(path, passphrase)
+}
+
+func (a *App) ImportAccounts(passphrase string) (*AccountImportResult, error) {
+	path, err := wailsRun.OpenFileDialog(a.ctx, wailsRun.OpenDialogOptions{
+		Title:   "Import accounts",
+		Filters: []wailsRun.FileFilter{{DisplayName: "Nezord account bundle", Pattern: "*" + accountBundleExt}},
+	})
+	if err != nil || path == "" {
+		return nil, err
+	}
+	return a.importAccountsFrom(path, passphrase)
+}
+
+func (a *App) exportAccountsTo(path, passphrase string) error {
+	library, err := a.skinLibrary.Export()
+	if err != nil {
+		return err
+	}
+	data, err := a.accountManager.ExportBundle(passphrase, map[string][]byte{accountBundleSkinsFile: library})
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0600)
+}
+
+func (a *App) importAccountsFrom(path, passphrase string) (*AccountImportResult, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read account bundle: %w", err)
+	}
+
+	report, files, err := a.accountManager.ImportBundle(context.Background(), data, passphrase)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &AccountImportResult{Accounts: report.Accounts, Relogin: report.NeedsRelogin()}
+	if library, ok := files[accountBundleSkinsFile]; ok {
+		count, err := a.skinLibrary.Import(library)
+		result.SkinsImported = count
+		if err != nil {
+			logging.Warn("Skin library import incomplete: %v", err)
+		}
+	}
+	for _, acc := range report.Accounts {
+		a.skinCache.Invalidate(acc.UUID)
+	}
+	return result, nil
 }
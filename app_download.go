@@ -3,12 +3,20 @@ package main
 import (
 	"NezordLauncher/pkg/downloader"
 	"NezordLauncher/pkg/ipc"
+	"NezordLauncher/pkg/logging"
+	"NezordLauncher/pkg/network"
+	"NezordLauncher/pkg/settings"
 	"NezordLauncher/pkg/validation"
 	"context"
+	"errors"
 	"fmt"
-	"time"
+	"os"
+	"strings"
 )
 
+// downloadWorkers is the number of files each download job fetches in parallel.
+const downloadWorkers = 10
+
 func (a *App) StartInstanceDownload(instanceID string) error {
 	inst, ok := a.instanceManager.Get(instanceID)
 	if !ok {
@@ -43,91 +51,140 @@ func (a *App) downloadVersion(instanceID, versionID string) error {
 	if err := validation.ValidateVersionID(versionID); err != nil {
 		return err
 	}
-	a.downloadMu.Lock()
-	if a.downloadCancel != nil {
-		a.downloadCancel()
+
+	if err := downloader.CheckReceipt(versionID); err == nil {
+		a.emitDownloadStatus(instanceID, "", "completed", fmt.Sprintf("%s is installed and unchanged", versionID))
+		a.emitDownloadComplete(instanceID)
+		return nil
+	} else if !os.IsNotExist(err) {
+		logging.Info("Install receipt for %s is stale, verifying files: %v", versionID, err)
+	}
+
+	var fetcher *downloader.ArtifactFetcher
+	job := a.downloads.Submit("version:"+versionID, versionID, instanceID, func(ctx context.Context, pool *downloader.WorkerPool) error {
+		fetcher = downloader.NewArtifactFetcher(pool)
+		return fetcher.DownloadVersion(ctx, versionID)
+	})
+	a.emitDownloadStatus(instanceID, job.ID, "starting", fmt.Sprintf("Starting download for: %s", versionID))
+
+	err := job.Wait(context.Background())
+	switch {
+	case err == nil:
+	case errors.Is(err, context.Canceled):
+		a.emitDownloadStatus(instanceID, job.ID, "cancelled", "Download cancelled")
+		return fmt.Errorf("cancelled")
+	case errors.Is(err, downloader.ErrIncomplete):
+		a.emitDownloadError(instanceID, job.ID, ErrCodeDownloadTaskErrors, err)
+		a.emitDownloadStatus(instanceID, job.ID, "completed_with_errors", "Artifacts verification completed with errors")
+		return nil
+	default:
+		a.emitDownloadError(instanceID, job.ID, ErrCodeDownloadVersionFailed, err)
+		return fmt.Errorf("download failed: %w", err)
 	}
-	ctx, cancel := context.WithCancel(context.Background())
-	a.downloadCancel = cancel
-	a.downloadMu.Unlock()
-
-	defer func() {
-		a.downloadMu.Lock()
-		if a.downloadCancel != nil {
-			a.downloadCancel()
-			a.downloadCancel = nil
+
+	// fetcher stays nil when another caller's job for this version was already active.
+	if fetcher != nil {
+		if err := fetcher.WriteReceipt(versionID); err != nil {
+			logging.Warn("Failed to write install receipt for %s: %v", versionID, err)
 		}
-		a.downloadMu.Unlock()
-	}()
+	}
+
+	a.emitDownloadStatus(instanceID, job.ID, "completed", "Artifacts verification complete")
+	a.emitDownloadComplete(instanceID)
+	return nil
+}
+
+// CancelDownload cancels every queued, running and paused download job.
+func (a *App) CancelDownload() {
+	a.emitDownloadStatus("", "", "stopping", "Stopping...")
+	a.downloads.CancelAll()
+}
+
+func (a *App) GetDownloadJobs() []downloader.JobInfo {
+	return a.downloads.Jobs()
+}
+
+func (a *App) CancelDownloadJob(jobID string) error {
+	return a.downloads.Cancel(jobID)
+}
 
-	pool := downloader.NewWorkerPool(10, 100)
+func (a *App) PauseDownloadJob(jobID string) error {
+	return a.downloads.Pause(jobID)
+}
 
-	_ = versionID
+func (a *App) ResumeDownloadJob(jobID string) error {
+	return a.downloads.Resume(jobID)
+}
 
-	pool.Start(ctx)
-	fetcher := downloader.NewArtifactFetcher(pool)
+// RetryDownloadJob restarts a failed job; files it already finished are not checked again.
+func (a *App) RetryDownloadJob(jobID string) error {
+	return a.downloads.Retry(jobID)
+}
 
-	a.emitDownloadStatus(instanceID, "starting", fmt.Sprintf("Starting download for: %s", versionID))
+func (a *App) ClearFinishedDownloads() {
+	a.downloads.ClearFinished()
+}
 
-	progressTicker := time.NewTicker(100 * time.Millisecond)
-	go func() {
-		for {
-			select {
-			case <-progressTicker.C:
-				current, total, currentBytes, totalBytes, speed, eta := pool.Progress.GetMetrics()
-				a.emitDownloadProgress(instanceID, current, total, currentBytes, totalBytes, speed, eta)
-			case <-ctx.Done():
-				progressTicker.Stop()
-				return
+// validateMirrorSettings rejects rules that could never match or be fetched. Mirror URLs need a
+// scheme because rewrites keep everything after the upstream prefix.
+func validateMirrorSettings(s settings.LauncherSettings) error {
+	if _, ok := network.MirrorPresets[s.MirrorPreset]; s.MirrorPreset != "" && !ok {
+		return fmt.Errorf("unknown mirror preset: %s", s.MirrorPreset)
+	}
+	for _, rule := range s.Mirrors {
+		for _, u := range append([]string{rule.Upstream}, rule.Mirrors...) {
+			if !strings.Contains(u, "://") {
+				return fmt.Errorf("mirror URL needs a scheme: %s", u)
+			}
+			if err := validation.ValidateServerURL(u); err != nil {
+				return fmt.Errorf("invalid mirror URL %s: %w", u, err)
 			}
 		}
-	}()
-
-	if err := fetcher.DownloadVersion(ctx, versionID); err != nil {
-		pool.Wait()
-		progressTicker.Stop()
-		if err == context.Canceled {
-			a.emitDownloadStatus(instanceID, "cancelled", "Download cancelled")
-			return fmt.Errorf("cancelled")
-		}
-		a.emitDownloadError(instanceID, ErrCodeDownloadVersionFailed, err)
-		return fmt.Errorf("download failed: %w", err)
 	}
-
-	pool.Wait()
-	progressTicker.Stop()
-	if len(pool.Errors()) > 0 {
-		err := fmt.Errorf("download finished with %d task errors", len(pool.Errors()))
-		a.emitDownloadError(instanceID, ErrCodeDownloadTaskErrors, err)
-		a.emitDownloadStatus(instanceID, "completed_with_errors", "Artifacts verification completed with errors")
-		return nil
-	}
-	a.emitDownloadStatus(instanceID, "completed", "Artifacts verification complete")
-	a.emitDownloadComplete(instanceID)
 	return nil
 }
 
-func (a *App) CancelDownload() {
-	a.downloadMu.Lock()
-	defer a.downloadMu.Unlock()
-	if a.downloadCancel != nil {
-		a.downloadCancel()
-		a.emitDownloadStatus("", "stopping", "Stopping...")
+func (a *App) applyDownloadSettings(s settings.LauncherSettings) {
+	n := s.MaxConcurrentDownloads
+	if n <= 0 {
+		n = settings.DefaultConcurrentDownloads
 	}
+	a.downloads.SetMaxConcurrent(n)
+
+	rules := append([]network.MirrorRule(nil), s.Mirrors...)
+	if s.MirrorPreset != "" {
+		preset, ok := network.MirrorPresets[s.MirrorPreset]
+		if !ok {
+			logging.Warn("Unknown mirror preset %q, using official servers", s.MirrorPreset)
+		}
+		rules = append(rules, preset...)
+	}
+	network.SetMirrors(rules)
+}
+
+func (a *App) emitDownloadStatus(instanceID, jobID, status, message string) {
+	payload := newEventPayload("backend.download", instanceID, status, message)
+	payload.JobID = jobID
+	a.emit(ipc.EventDownloadStatus, payload)
 }
 
-func (a *App) emitDownloadStatus(instanceID, status, message string) {
-	a.emit(ipc.EventDownloadStatus, newEventPayload("backend.download", instanceID, status, message))
+// emitDownloadJob reports a job state change; the job snapshot is carried in meta.
+func (a *App) emitDownloadJob(job downloader.JobInfo) {
+	payload := newEventPayload("backend.download", job.InstanceID, "job_"+string(job.State), fmt.Sprintf("Download %s: %s", job.Label, job.State))
+	payload.JobID = job.ID
+	payload.Meta = job
+	a.emit(ipc.EventDownloadStatus, payload)
 }
 
-func (a *App) emitDownloadProgress(instanceID string, current, total int, currentBytes, totalBytes int64, speed, eta float64) {
-	payload := newEventPayload("backend.download", instanceID, "running", "Download progress")
-	payload.Current = current
-	payload.Total = total
-	payload.CurrentBytes = currentBytes
-	payload.TotalBytes = totalBytes
-	payload.Speed = speed
-	payload.Eta = eta
+func (a *App) emitDownloadProgress(job downloader.JobInfo) {
+	payload := newEventPayload("backend.download", job.InstanceID, "running", "Download progress")
+	payload.JobID = job.ID
+	payload.Current = job.Current
+	payload.Total = job.Total
+	payload.CurrentBytes = job.CurrentBytes
+	payload.TotalBytes = job.TotalBytes
+	payload.Speed = job.Speed
+	payload.Eta = job.Eta
 	a.emit(ipc.EventDownloadProgress, payload)
 }
 
@@ -135,8 +192,9 @@ func (a *App) emitDownloadComplete(instanceID string) {
 	a.emit(ipc.EventDownloadComplete, newEventPayload("backend.download", instanceID, "completed", "Download complete"))
 }
 
-func (a *App) emitDownloadError(instanceID, code string, err error) {
+func (a *App) emitDownloadError(instanceID, jobID, code string, err error) {
 	payload := newEventPayload("backend.download", instanceID, "failed", "Download failed")
+	payload.JobID = jobID
 	payload.Error = &EventError{
 		Code:    code,
 		Message: "Download failed",
@@ -3,6 +3,9 @@ package main
 const (
 	ErrCodeAppLogError = "APP_LOG_ERROR"
 
+	ErrCodeAuthMicrosoftFailed = "AUTH_MICROSOFT_FAILED"
+	ErrCodeAuthReloginRequired = "AUTH_RELOGIN_REQUIRED"
+
 	ErrCodeDownloadVersionFailed = "DOWNLOAD_VERSION_FAILED"
 	ErrCodeDownloadTaskErrors    = "DOWNLOAD_TASK_ERRORS"
 	ErrCodeDownloadRepairFailed  = "DOWNLOAD_REPAIR_FAILED"
@@ -12,4 +15,5 @@ const (
 	ErrCodeJavaPathSettingsInvalid = "JAVA_PATH_SETTINGS_INVALID"
 	ErrCodeLaunchCommandCreateFail = "LAUNCH_COMMAND_CREATE_FAILED"
 	ErrCodeLaunchRuntimeError      = "LAUNCH_RUNTIME_ERROR"
+	ErrCodeLaunchAccountInUse      = "LAUNCH_ACCOUNT_IN_USE"
 )
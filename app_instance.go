@@ -5,13 +5,20 @@ import (
 	"NezordLauncher/pkg/downloader"
 	"NezordLauncher/pkg/ipc"
 	"NezordLauncher/pkg/instances"
+	"NezordLauncher/pkg/modloader"
+	"NezordLauncher/pkg/mods"
+	"NezordLauncher/pkg/optifine"
 	"NezordLauncher/pkg/validation"
 	"context"
+	"errors"
 	"fmt"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"runtime"
+	"strings"
+
+	wailsRun "github.com/wailsapp/wails/v2/pkg/runtime"
 )
 
 func (a *App) CreateInstance(name, gameVersion, modloaderType, modloaderVersion string) (*instances.Instance, error) {
@@ -33,6 +40,11 @@ func (a *App) DeleteInstance(id string) error {
 }
 
 func (a *App) UpdateInstanceSettings(id string, settings instances.InstanceSettings) error {
+	if settings.AccountUUID != "" {
+		if _, err := a.accountManager.GetAccount(settings.AccountUUID); err != nil {
+			return err
+		}
+	}
 	return a.instanceManager.UpdateSettings(id, settings)
 }
 
@@ -77,39 +89,20 @@ func (a *App) RepairInstance(instanceID string) error {
 		return brokenMap[path]
 	}
 
-	a.downloadMu.Lock()
-	if a.downloadCancel != nil {
-		a.downloadCancel()
-	}
-	ctx, cancel := context.WithCancel(context.Background())
-	a.downloadCancel = cancel
-	a.downloadMu.Unlock()
+	job := a.downloads.Submit("repair:"+instanceID, "Repair "+inst.Name, instanceID, func(ctx context.Context, pool *downloader.WorkerPool) error {
+		// The files were found broken by hashing, so the index must not vouch for them.
+		pool.DeepVerify = true
+		fetcher := downloader.NewArtifactFetcher(pool)
+		fetcher.Filter = filter
+		return fetcher.DownloadVersion(ctx, inst.GameVersion)
+	})
 
-	defer func() {
-		a.downloadMu.Lock()
-		if a.downloadCancel != nil {
-			a.downloadCancel()
-			a.downloadCancel = nil
+	if err := job.Wait(context.Background()); err != nil {
+		if errors.Is(err, downloader.ErrIncomplete) {
+			a.emitDownloadError(instanceID, job.ID, ErrCodeDownloadRepairPartial, err)
+		} else {
+			a.emitDownloadError(instanceID, job.ID, ErrCodeDownloadRepairFailed, err)
 		}
-		a.downloadMu.Unlock()
-	}()
-
-	pool := downloader.NewWorkerPool(10, 100)
-	pool.Start(ctx)
-
-	fetcher := downloader.NewArtifactFetcher(pool)
-	fetcher.Filter = filter
-
-	if err := fetcher.DownloadVersion(ctx, inst.GameVersion); err != nil {
-		a.emitDownloadError(instanceID, ErrCodeDownloadRepairFailed, err)
-		return err
-	}
-
-	pool.Wait()
-
-	if len(pool.Errors()) > 0 {
-		err := fmt.Errorf("repair failed with %d errors", len(pool.Errors()))
-		a.emitDownloadError(instanceID, ErrCodeDownloadRepairPartial, err)
 		return err
 	}
 
@@ -140,3 +133,154 @@ func (a *App) emitInstanceUpdated(inst *instances.Instance) {
 	payload.Meta = inst
 	a.emit(ipc.EventInstanceUpdated, payload)
 }
+
+// AddOptiFine asks for an OptiFine installer jar and adds it to the instance. Vanilla instances
+// become standalone OptiFine instances; Forge instances get it in their mods folder. An empty
+// result means the dialog was cancelled.
+func (a *App) AddOptiFine(instanceID string) (string, error) {
+	path, err := wailsRun.OpenFileDialog(a.ctx, wailsRun.OpenDialogOptions{
+		Title:   "Select OptiFine installer",
+		Filters: []wailsRun.FileFilter{{DisplayName: "OptiFine installer", Pattern: "*.jar"}},
+	})
+	if err != nil || path == "" {
+		return "", err
+	}
+	return a.installOptiFineFrom(instanceID, path)
+}
+
+func (a *App) installOptiFineFrom(instanceID, installerPath string) (string, error) {
+	inst, ok := a.instanceManager.Get(instanceID)
+	if !ok {
+		return "", fmt.Errorf("instance not found: %s", instanceID)
+	}
+
+	info, err := optifine.Detect(installerPath)
+	if err != nil {
+		return "", err
+	}
+	if info.GameVersion != inst.GameVersion {
+		return "", fmt.Errorf("optifine %s targets %s, instance uses %s", info.Version(), info.GameVersion, inst.GameVersion)
+	}
+
+	switch inst.ModloaderType {
+	case instances.ModloaderForge:
+		modsDir := filepath.Join(constants.GetInstancesDir(), inst.ID, ".minecraft", "mods")
+		return optifine.InstallAsMod(installerPath, modsDir)
+	case instances.ModloaderVanilla, "":
+	default:
+		return "", fmt.Errorf("optifine can only be added to vanilla or forge instances")
+	}
+
+	if err := a.downloadVersion(instanceID, inst.GameVersion); err != nil {
+		return "", err
+	}
+	javaPath, err := a.resolveJavaPath(instanceID, inst, inst.GameVersion)
+	if err != nil {
+		return "", err
+	}
+
+	a.emitLaunchStatus(instanceID, "Installing OptiFine...")
+	versionID, err := optifine.Install(installerPath, optifine.InstallOptions{JavaPath: javaPath})
+	if err != nil {
+		return "", fmt.Errorf("failed to install optifine: %w", err)
+	}
+
+	inst.ModloaderType = instances.ModloaderOptiFine
+	inst.ModloaderVersion = info.Version()
+	if err := a.instanceManager.SaveInstance(inst); err != nil {
+		return "", err
+	}
+	a.emitInstanceUpdated(inst)
+	return versionID, nil
+}
+
+type InstanceVersionChange struct {
+	Applied   bool         `json:"applied"`
+	VersionID string       `json:"versionId"`
+	Report    *mods.Report `json:"report"`
+}
+
+// ChangeInstanceVersion moves an instance to another game version and modloader. The mods
+// folder is checked first; incompatible mods block the change unless force is set, and the
+// report is returned either way.
+func (a *App) ChangeInstanceVersion(instanceID, gameVersion, modloaderType, modloaderVersion string, force bool) (*InstanceVersionChange, error) {
+	inst, ok := a.instanceManager.Get(instanceID)
+	if !ok {
+		return nil, fmt.Errorf("instance not found: %s", instanceID)
+	}
+	if err := validation.ValidateVersionID(gameVersion); err != nil {
+		return nil, err
+	}
+
+	// The reservation also keeps a launch from reading the instance while it is reinstalled.
+	if err := a.reserveInstance(instanceID); err != nil {
+		return nil, err
+	}
+	defer a.releaseAccountSession(instanceID)
+
+	if modloaderType == "" {
+		modloaderType = string(instances.ModloaderVanilla)
+	}
+	resolvedLoader := ""
+	if modloaderType != string(instances.ModloaderVanilla) {
+		provider, err := modloader.Lookup(modloaderType)
+		if err != nil {
+			return nil, err
+		}
+		records, err := provider.LoaderVersions(gameVersion)
+		if err != nil {
+			return nil, err
+		}
+		versions := modloader.Versions(records)
+		if len(versions) == 0 {
+			return nil, fmt.Errorf("%s does not support Minecraft %s", provider.Name(), gameVersion)
+		}
+		resolvedLoader = versions[0]
+		if modloaderVersion != "" && modloaderVersion != "latest" {
+			// Forge-style versions may carry a game version prefix, which mod ranges never do.
+			trimmed := strings.TrimPrefix(modloaderVersion, gameVersion+"-")
+			if !containsVersion(versions, trimmed) {
+				return nil, fmt.Errorf("%s %s does not support Minecraft %s", provider.Name(), modloaderVersion, gameVersion)
+			}
+			resolvedLoader = trimmed
+		}
+	}
+
+	gameDir := filepath.Join(constants.GetInstancesDir(), inst.ID, ".minecraft")
+	report, err := mods.CheckDir(filepath.Join(gameDir, "mods"), gameVersion, modloaderType, resolvedLoader)
+	if err != nil {
+		return nil, err
+	}
+	result := &InstanceVersionChange{Report: report}
+	if !report.Compatible() && !force {
+		return result, nil
+	}
+
+	next := *inst
+	next.GameVersion = gameVersion
+	next.ModloaderType = instances.ModloaderType(modloaderType)
+	next.ModloaderVersion = modloaderVersion
+	javaPath := ""
+	versionID, err := a.installInstanceVersion(instanceID, &next, gameDir, &javaPath)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := a.instanceManager.UpdateVersion(instanceID, next.GameVersion, next.ModloaderType, next.ModloaderVersion); err != nil {
+		return nil, err
+	}
+	a.emitInstanceUpdated(inst)
+
+	result.Applied = true
+	result.VersionID = versionID
+	return result, nil
+}
+
+func containsVersion(versions []string, version string) bool {
+	for _, v := range versions {
+		if v == version {
+			return true
+		}
+	}
+	return false
+}
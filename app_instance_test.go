@@ -0,0 +1,103 @@
+package main
+
+import (
+	"NezordLauncher/pkg/constants"
+	"NezordLauncher/pkg/instances"
+	"NezordLauncher/pkg/modloader"
+	"archive/zip"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+type stubLoader struct {
+	modloader.Provider
+	versions []modloader.Version
+	err      error
+}
+
+func (s stubLoader) ID() string   { return "forge" }
+func (s stubLoader) Name() string { return "Forge" }
+
+func (s stubLoader) LoaderVersions(gameVersion string) ([]modloader.Version, error) {
+	return s.versions, s.err
+}
+
+func useStubForge(t *testing.T, stub stubLoader) {
+	original, _ := modloader.Get("forge")
+	modloader.Register(stub)
+	t.Cleanup(func() { modloader.Register(original) })
+}
+
+func writeModJar(t *testing.T, path, entry, content string) {
+	t.Helper()
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	zw := zip.NewWriter(f)
+	w, _ := zw.Create(entry)
+	w.Write([]byte(content))
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestChangeInstanceVersion(t *testing.T) {
+	t.Setenv("NEZORD_DATA_DIR", t.TempDir())
+	app := NewApp()
+	app.EnableTestMode()
+
+	inst, err := app.instanceManager.CreateInstance("Modded", "1.20.1", instances.ModloaderVanilla, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	modsDir := filepath.Join(constants.GetInstancesDir(), inst.ID, ".minecraft", "mods")
+	if err := os.MkdirAll(modsDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	writeModJar(t, filepath.Join(modsDir, "jei.jar"), "META-INF/mods.toml", `
+modLoader="javafml"
+[[mods]]
+modId="jei"
+[[dependencies.jei]]
+    modId="forge"
+    mandatory=true
+    versionRange="[47,)"
+`)
+	// A fabric mod keeps the change from going ahead, so nothing is installed.
+	writeModJar(t, filepath.Join(modsDir, "sodium.jar"), "fabric.mod.json", `{"id": "sodium"}`)
+
+	offline := errors.New("dial tcp: no such host")
+	useStubForge(t, stubLoader{err: offline})
+	if _, err := app.ChangeInstanceVersion(inst.ID, "1.20.1", "forge", "", false); !errors.Is(err, offline) {
+		t.Errorf("expected the provider error, got %v", err)
+	}
+
+	useStubForge(t, stubLoader{versions: []modloader.Version{{Version: "47.2.0"}}})
+	result, err := app.ChangeInstanceVersion(inst.ID, "1.20.1", "forge", "1.20.1-47.2.0", false)
+	if err != nil {
+		t.Fatalf("ChangeInstanceVersion failed: %v", err)
+	}
+	if result.Applied || result.Report.LoaderVersion != "47.2.0" {
+		t.Fatalf("unexpected result %+v", result.Report)
+	}
+	for _, issue := range result.Report.Issues {
+		if issue.File == "jei.jar" {
+			t.Errorf("forge mod flagged against the prefixed loader version: %s", issue.Reason)
+		}
+	}
+
+	if err := app.reserveInstance(inst.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := app.ChangeInstanceVersion(inst.ID, "1.20.1", "forge", "", false); err == nil {
+		t.Error("expected a launching instance to block the change")
+	}
+	app.releaseAccountSession(inst.ID)
+	if _, err := app.ChangeInstanceVersion(inst.ID, "1.20.1", "forge", "", false); err != nil {
+		t.Errorf("released instance should be changeable: %v", err)
+	}
+}
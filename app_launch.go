@@ -3,16 +3,18 @@ package main
 import (
 	"NezordLauncher/pkg/auth"
 	"NezordLauncher/pkg/constants"
-	"NezordLauncher/pkg/fabric"
 	"NezordLauncher/pkg/instances"
 	"NezordLauncher/pkg/ipc"
 	"NezordLauncher/pkg/javascanner"
 	"NezordLauncher/pkg/launch"
+	"NezordLauncher/pkg/logging"
 	"NezordLauncher/pkg/models"
+	"NezordLauncher/pkg/modloader"
 	"NezordLauncher/pkg/network"
-	"NezordLauncher/pkg/quilt"
 	"NezordLauncher/pkg/services"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"os/exec"
@@ -23,47 +25,42 @@ import (
 )
 
 func (a *App) LaunchInstance(instanceID string) error {
-	account := a.accountManager.GetActiveAccount()
-	if account == nil {
-		return fmt.Errorf("no active account selected")
-	}
-
 	inst, ok := a.instanceManager.Get(instanceID)
 	if !ok {
 		return fmt.Errorf("instance not found: %s", instanceID)
 	}
 
-	if err := a.downloadVersion(instanceID, inst.GameVersion); err != nil {
+	account, err := a.resolveLaunchAccount(inst)
+	if err != nil {
 		return err
 	}
 
-	finalVersionID := inst.GetLaunchVersionID()
-
-	if inst.ModloaderType == instances.ModloaderFabric {
-		a.emitLaunchStatus(instanceID, "Verifying Fabric...")
-		installedID, err := fabric.InstallFabric(inst.GameVersion, inst.ModloaderVersion)
-		if err != nil {
-			return fmt.Errorf("failed to install fabric: %w", err)
-		}
-		finalVersionID = installedID
-	} else if inst.ModloaderType == instances.ModloaderQuilt {
-		a.emitLaunchStatus(instanceID, "Verifying Quilt...")
-		installedID, err := quilt.InstallQuilt(inst.GameVersion, inst.ModloaderVersion)
-		if err != nil {
-			return fmt.Errorf("failed to install quilt: %w", err)
-		}
-		finalVersionID = installedID
+	// Reserve first: refreshing the tokens of an account that is already playing would end that
+	// session.
+	if err := a.reserveAccountSession(instanceID, account); err != nil {
+		a.emitLaunchError(instanceID, ErrCodeLaunchAccountInUse, "Account is already playing in another instance", err)
+		return err
 	}
+	started := false
+	defer func() {
+		if !started {
+			a.releaseAccountSession(instanceID)
+		}
+	}()
 
-	if finalVersionID != inst.GameVersion {
-		if err := a.downloadVersion(instanceID, finalVersionID); err != nil {
-			return err
+	account, err = a.accountManager.EnsureValidSession(context.Background(), account.UUID)
+	if err != nil {
+		if errors.Is(err, auth.ErrReloginRequired) {
+			a.emitLaunchError(instanceID, ErrCodeAuthReloginRequired, "Account session expired, please log in again", err)
 		}
+		return err
 	}
 
 	instanceDir := filepath.Join(constants.GetInstancesDir(), inst.ID, ".minecraft")
-	if err := os.MkdirAll(instanceDir, 0755); err != nil {
-		return fmt.Errorf("failed to create instance dir: %w", err)
+	javaPath := ""
+	finalVersionID, err := a.installInstanceVersion(instanceID, inst, instanceDir, &javaPath)
+	if err != nil {
+		return err
 	}
 
 	nativesDir := filepath.Join(constants.GetInstancesDir(), inst.ID, "natives")
@@ -77,43 +74,15 @@ func (a *App) LaunchInstance(instanceID string) error {
 
 	settings := a.settingsManager.Get()
 
-	javaPath := ""
-	if inst.Settings.OverrideJava && inst.Settings.JavaPath != "" {
-		a.emitLaunchStatus(instanceID, "Using custom Java from instance...")
-		if _, err := os.Stat(inst.Settings.JavaPath); err == nil {
-			javaPath = inst.Settings.JavaPath
-		} else {
-				a.emitLaunchError(instanceID, ErrCodeJavaPathInstanceInvalid, "Instance Java path invalid, falling back to settings or auto-detect", err)
-		}
-	}
-
-	if javaPath == "" && settings.DefaultJavaPath != "" {
-		a.emitLaunchStatus(instanceID, "Using Java from settings...")
-		if _, err := os.Stat(settings.DefaultJavaPath); err == nil {
-			javaPath = settings.DefaultJavaPath
-		} else {
-				a.emitLaunchError(instanceID, ErrCodeJavaPathSettingsInvalid, "Settings Java path invalid, falling back to auto-detect", err)
-		}
-	}
-
 	if javaPath == "" {
-		a.emitLaunchStatus(instanceID, "Scanning Java runtime...")
-		javaInstalls, err := javascanner.ScanJavaInstallations()
-		if err != nil {
-			return fmt.Errorf("java scan failed: %w", err)
-		}
-
 		javaTargetVersion := finalVersionID
 		if version.InheritsFrom != "" {
 			javaTargetVersion = version.InheritsFrom
 		}
-
-		selectedJava, err := javascanner.SelectJava(javaInstalls, javaTargetVersion)
+		javaPath, err = a.resolveJavaPath(instanceID, inst, javaTargetVersion)
 		if err != nil {
-			return fmt.Errorf("java selection failed: %w", err)
+			return err
 		}
-		javaPath = selectedJava.Path
-		a.emitLaunchStatus(instanceID, fmt.Sprintf("Using Java: %s (%s)", selectedJava.Version, selectedJava.Path))
 	}
 
 	a.emitLaunchStatus(instanceID, "Extracting native libraries...")
@@ -122,13 +91,29 @@ func (a *App) LaunchInstance(instanceID string) error {
 	}
 
 	authlibPath := ""
-	if account.Type == auth.AccountTypeElyBy {
+	authlibServer := ""
+	authlibPrefetched := ""
+	if account.Type == auth.AccountTypeElyBy || account.Type == auth.AccountTypeYggdrasil {
 		a.emitLaunchStatus(instanceID, "Verifying Authlib Injector...")
 		path, err := services.EnsureAuthlibInjector()
 		if err != nil {
 			return fmt.Errorf("failed to ensure authlib injector: %w", err)
 		}
 		authlibPath = path
+		if account.Type == auth.AccountTypeYggdrasil && account.Server != nil {
+			authlibServer = account.Server.APIRoot
+			authlibPrefetched = account.Server.PrefetchedMetadata()
+		}
+	} else if account.Type == auth.AccountTypeOffline && settings.LocalSkinServer {
+		path, err := services.EnsureAuthlibInjector()
+		if err == nil {
+			authlibServer, authlibPrefetched, err = a.offlineSkinServer(instanceID, account, settings)
+		}
+		if err == nil {
+			authlibPath = path
+		} else {
+			logging.Warn("Local skin server unavailable, launching without skins: %v", err)
+		}
 	}
 
 	ramMB := inst.Settings.RamMB
@@ -166,6 +151,8 @@ func (a *App) LaunchInstance(instanceID string) error {
 		Width:               width,
 		Height:              height,
 		AuthlibInjectorPath: authlibPath,
+		AuthlibServer:       authlibServer,
+		AuthlibPrefetched:   authlibPrefetched,
 	}
 	if strings.EqualFold(settings.WindowMode, "Fullscreen") {
 		opts.Fullscreen = true
@@ -266,11 +253,13 @@ func (a *App) LaunchInstance(instanceID string) error {
 	a.runningMu.Lock()
 	a.runningInstances[instanceID] = cmd
 	a.runningMu.Unlock()
+	started = true
 
 	go func() {
 		defer func() {
 			a.runningMu.Lock()
 			delete(a.runningInstances, instanceID)
+			delete(a.runningAccounts, instanceID)
 			a.runningMu.Unlock()
 			a.emitLaunchExit(instanceID, "success")
 		}()
@@ -285,6 +274,144 @@ func (a *App) LaunchInstance(instanceID string) error {
 	return nil
 }
 
+// installInstanceVersion downloads the instance's game version, installs its modloader and
+// returns the version ID to launch. A Java runtime resolved along the way is kept in javaPath.
+func (a *App) installInstanceVersion(instanceID string, inst *instances.Instance, instanceDir string, javaPath *string) (string, error) {
+	if err := a.downloadVersion(instanceID, inst.GameVersion); err != nil {
+		return "", err
+	}
+
+	finalVersionID := inst.GetLaunchVersionID()
+
+	if err := os.MkdirAll(instanceDir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create instance dir: %w", err)
+	}
+
+	if provider, ok := modloader.Get(string(inst.ModloaderType)); ok {
+		a.emitLaunchStatus(instanceID, fmt.Sprintf("Verifying %s...", provider.Name()))
+		installedID, err := provider.Install(inst.GameVersion, inst.ModloaderVersion, modloader.InstallOptions{
+			// Loaders with installer processors need Java before the version exists.
+			Java: func() (string, error) {
+				if *javaPath == "" {
+					path, err := a.resolveJavaPath(instanceID, inst, inst.GameVersion)
+					if err != nil {
+						return "", err
+					}
+					*javaPath = path
+				}
+				return *javaPath, nil
+			},
+			GameDir:  instanceDir,
+			OnStatus: func(message string) { a.emitLaunchStatus(instanceID, message) },
+		})
+		if err != nil {
+			return "", fmt.Errorf("failed to install %s: %w", provider.ID(), err)
+		}
+		finalVersionID = installedID
+	}
+
+	if finalVersionID != inst.GameVersion {
+		if err := a.downloadVersion(instanceID, finalVersionID); err != nil {
+			return "", err
+		}
+	}
+	return finalVersionID, nil
+}
+
+// resolveJavaPath picks the instance override, then the settings default, then a scanned runtime.
+func (a *App) resolveJavaPath(instanceID string, inst *instances.Instance, targetVersion string) (string, error) {
+	settings := a.settingsManager.Get()
+
+	if inst.Settings.OverrideJava && inst.Settings.JavaPath != "" {
+		a.emitLaunchStatus(instanceID, "Using custom Java from instance...")
+		_, err := os.Stat(inst.Settings.JavaPath)
+		if err == nil {
+			return inst.Settings.JavaPath, nil
+		}
+		a.emitLaunchError(instanceID, ErrCodeJavaPathInstanceInvalid, "Instance Java path invalid, falling back to settings or auto-detect", err)
+	}
+
+	if settings.DefaultJavaPath != "" {
+		a.emitLaunchStatus(instanceID, "Using Java from settings...")
+		_, err := os.Stat(settings.DefaultJavaPath)
+		if err == nil {
+			return settings.DefaultJavaPath, nil
+		}
+		a.emitLaunchError(instanceID, ErrCodeJavaPathSettingsInvalid, "Settings Java path invalid, falling back to auto-detect", err)
+	}
+
+	a.emitLaunchStatus(instanceID, "Scanning Java runtime...")
+	javaInstalls, err := javascanner.ScanJavaInstallations()
+	if err != nil {
+		return "", fmt.Errorf("java scan failed: %w", err)
+	}
+
+	selectedJava, err := javascanner.SelectJava(javaInstalls, targetVersion)
+	if err != nil {
+		return "", fmt.Errorf("java selection failed: %w", err)
+	}
+	a.emitLaunchStatus(instanceID, fmt.Sprintf("Using Java: %s (%s)", selectedJava.Version, selectedJava.Path))
+	return selectedJava.Path, nil
+}
+
+// resolveLaunchAccount prefers the account pinned in the instance settings.
+func (a *App) resolveLaunchAccount(inst *instances.Instance) (*auth.Account, error) {
+	if uuid := inst.Settings.AccountUUID; uuid != "" {
+		account, err := a.accountManager.GetAccount(uuid)
+		if err == nil {
+			return account, nil
+		}
+		logging.Warn("Pinned account %s for instance %s is gone, using the active account", uuid, inst.ID)
+	}
+
+	account := a.accountManager.GetActiveAccount()
+	if account == nil {
+		return nil, fmt.Errorf("no active account selected")
+	}
+	return account, nil
+}
+
+// reserveAccountSession records which account an instance runs under. Online accounts can only
+// hold one game session at a time; a second join would kick the first.
+func (a *App) reserveAccountSession(instanceID string, account *auth.Account) error {
+	a.runningMu.Lock()
+	defer a.runningMu.Unlock()
+
+	if _, running := a.runningAccounts[instanceID]; running {
+		return fmt.Errorf("instance is already running: %s", instanceID)
+	}
+	if account.Type != auth.AccountTypeOffline {
+		for otherID, uuid := range a.runningAccounts {
+			if uuid == account.UUID {
+				return fmt.Errorf("account %s is already in use by instance %s", account.Username, otherID)
+			}
+		}
+	}
+
+	a.runningAccounts[instanceID] = account.UUID
+	return nil
+}
+
+// reserveInstance claims an instance that is neither launching nor running, with no account.
+func (a *App) reserveInstance(instanceID string) error {
+	a.runningMu.Lock()
+	defer a.runningMu.Unlock()
+
+	_, reserved := a.runningAccounts[instanceID]
+	_, running := a.runningInstances[instanceID]
+	if reserved || running {
+		return fmt.Errorf("stop the instance before changing its version")
+	}
+	a.runningAccounts[instanceID] = ""
+	return nil
+}
+
+func (a *App) releaseAccountSession(instanceID string) {
+	a.runningMu.Lock()
+	delete(a.runningAccounts, instanceID)
+	a.runningMu.Unlock()
+}
+
 func (a *App) StopInstance(instanceID string) error {
 	a.runningMu.Lock()
 	cmd, ok := a.runningInstances[instanceID]
@@ -0,0 +1,98 @@
+package main
+
+import (
+	"NezordLauncher/pkg/auth"
+	"NezordLauncher/pkg/instances"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestReserveAccountSession(t *testing.T) {
+	app := NewApp()
+	online := &auth.Account{UUID: "online-uuid", Username: "Steve", Type: auth.AccountTypeMicrosoft}
+	offline := &auth.Account{UUID: "offline-uuid", Username: "Alex", Type: auth.AccountTypeOffline}
+
+	if err := app.reserveAccountSession("a", online); err != nil {
+		t.Fatalf("first reservation failed: %v", err)
+	}
+	if err := app.reserveAccountSession("b", online); err == nil {
+		t.Error("expected second session for the same online account to be rejected")
+	}
+	if err := app.reserveAccountSession("a", offline); err == nil {
+		t.Error("expected running instance to be rejected")
+	}
+
+	if err := app.reserveAccountSession("b", offline); err != nil {
+		t.Fatalf("offline reservation failed: %v", err)
+	}
+	if err := app.reserveAccountSession("c", offline); err != nil {
+		t.Errorf("offline accounts may run concurrently: %v", err)
+	}
+
+	app.releaseAccountSession("a")
+	if err := app.reserveAccountSession("d", online); err != nil {
+		t.Errorf("released account should be reusable: %v", err)
+	}
+}
+
+func TestLaunchInstanceReservesBeforeRefresh(t *testing.T) {
+	t.Setenv("NEZORD_DATA_DIR", t.TempDir())
+
+	var refreshes atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		refreshes.Add(1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+	t.Setenv("NEZORD_MS_CLIENT_ID", "test-client")
+	t.Setenv("NEZORD_MS_TOKEN_URL", server.URL+"/token")
+
+	store, err := auth.NewPassphraseFileStore(filepath.Join(t.TempDir(), "credentials.enc"), "test-passphrase")
+	if err != nil {
+		t.Fatal(err)
+	}
+	previous, previousInfo := auth.CurrentCredentialStore(), auth.GetCredentialBackendInfo()
+	auth.UseCredentialStore(store, auth.CredentialBackendInfo{Active: auth.CredentialBackendPassphrase, Encrypted: true})
+	t.Cleanup(func() { auth.UseCredentialStore(previous, previousInfo) })
+
+	app := NewApp()
+	app.EnableTestMode()
+	account := auth.Account{
+		UUID:         "3f1d6a2b8c9e4f0a1b2c3d4e5f6a7b8c",
+		Username:     "Steve",
+		Type:         auth.AccountTypeMicrosoft,
+		AccessToken:  "live-token",
+		RefreshToken: "refresh-token",
+		ExpiresAt:    time.Now().Add(-time.Hour),
+	}
+	app.accountManager.Data.Accounts = append(app.accountManager.Data.Accounts, account)
+	if err := app.accountManager.SetActiveAccount(account.UUID); err != nil {
+		t.Fatal(err)
+	}
+
+	inst, err := app.instanceManager.CreateInstance("Second", "1.20.1", instances.ModloaderVanilla, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := app.reserveAccountSession("running", &account); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := app.LaunchInstance(inst.ID); err == nil {
+		t.Fatal("expected launch to be rejected while the account is playing")
+	}
+	if n := refreshes.Load(); n != 0 {
+		t.Errorf("rejected launch must not refresh the live session, got %d token requests", n)
+	}
+
+	app.runningMu.Lock()
+	_, leaked := app.runningAccounts[inst.ID]
+	app.runningMu.Unlock()
+	if leaked {
+		t.Error("rejected launch should not keep a reservation")
+	}
+}
@@ -0,0 +1,384 @@
+package main
+
+import (
+	"NezordLauncher/pkg/auth"
+	"NezordLauncher/pkg/constants"
+	"NezordLauncher/pkg/logging"
+	"NezordLauncher/pkg/services"
+	"NezordLauncher/pkg/settings"
+	"NezordLauncher/pkg/skins"
+	"NezordLauncher/pkg/validation"
+	"bytes"
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"image"
+	"image/png"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	defaultAvatarSize = 64
+	maxAvatarSize     = 512
+	defaultBodyScale  = 8
+	maxBodyScale      = 16
+)
+
+func (a *App) GetAccountAvatar(uuid string, size int) (string, error) {
+	if size <= 0 {
+		size = defaultAvatarSize
+	}
+	if size > maxAvatarSize {
+		size = maxAvatarSize
+	}
+	return a.renderAccountSkin(uuid, false, func(skin image.Image, model string) (image.Image, error) {
+		return skins.RenderHead(skin, size)
+	})
+}
+
+func (a *App) GetAccountBody(uuid string, scale int) (string, error) {
+	if scale <= 0 {
+		scale = defaultBodyScale
+	}
+	if scale > maxBodyScale {
+		scale = maxBodyScale
+	}
+	return a.renderAccountSkin(uuid, false, func(skin image.Image, model string) (image.Image, error) {
+		return skins.RenderBody(skin, model == skins.ModelSlim, scale)
+	})
+}
+
+func (a *App) GetAccountTextures(uuid string) (*skins.Textures, error) {
+	if err := validation.ValidateUUID(uuid); err != nil {
+		return nil, err
+	}
+	acc, err := a.accountManager.GetAccount(uuid)
+	if err != nil {
+		return nil, err
+	}
+	return a.skinCache.ProfileTextures(*acc, false)
+}
+
+// RefreshAccountSkin drops cached textures so the next avatar request hits the network.
+func (a *App) RefreshAccountSkin(uuid string) (string, error) {
+	if err := validation.ValidateUUID(uuid); err != nil {
+		return "", err
+	}
+	a.skinCache.Invalidate(uuid)
+	return a.renderAccountSkin(uuid, true, func(skin image.Image, model string) (image.Image, error) {
+		return skins.RenderHead(skin, defaultAvatarSize)
+	})
+}
+
+// renderAccountSkin returns a PNG data URL, or an empty string when the account has no skin.
+func (a *App) renderAccountSkin(uuid string, force bool, render func(image.Image, string) (image.Image, error)) (string, error) {
+	if err := validation.ValidateUUID(uuid); err != nil {
+		return "", err
+	}
+	acc, err := a.accountManager.GetAccount(uuid)
+	if err != nil {
+		return "", err
+	}
+
+	var skin image.Image
+	var model string
+	if acc.Type == auth.AccountTypeOffline && acc.LocalSkinID != "" {
+		skin, model, err = a.librarySkin(acc.LocalSkinID)
+	} else {
+		skin, model, err = a.skinCache.Skin(*acc, force)
+	}
+	if err != nil {
+		if errors.Is(err, skins.ErrNoSkin) {
+			return "", nil
+		}
+		return "", err
+	}
+
+	img, err := render(skin, model)
+	if err != nil {
+		return "", err
+	}
+	return encodeDataURL(img)
+}
+
+func (a *App) ValidateSkin(pngBase64, model string) (*skins.SkinReport, error) {
+	data, err := decodePNGPayload(pngBase64)
+	if err != nil {
+		return nil, err
+	}
+	return skins.ValidateSkinPNG(data, model, true)
+}
+
+func (a *App) UploadAccountSkin(uuid, pngBase64, model string) error {
+	data, err := decodePNGPayload(pngBase64)
+	if err != nil {
+		return err
+	}
+	return a.changeAccountTextures(uuid, func(ctx context.Context, acc auth.Account) error {
+		return skins.UploadSkin(ctx, acc, data, model)
+	})
+}
+
+func (a *App) ResetAccountSkin(uuid string) error {
+	return a.changeAccountTextures(uuid, skins.ResetSkin)
+}
+
+func (a *App) GetAccountCapes(uuid string) ([]skins.ServicesCape, error) {
+	if err := validation.ValidateUUID(uuid); err != nil {
+		return nil, err
+	}
+	acc, err := a.accountManager.EnsureValidSession(context.Background(), uuid)
+	if err != nil {
+		return nil, err
+	}
+	profile, err := skins.FetchServicesProfile(context.Background(), *acc)
+	if err != nil {
+		return nil, err
+	}
+	return profile.Capes, nil
+}
+
+func (a *App) EquipAccountCape(uuid, capeID string) error {
+	if capeID == "" {
+		return fmt.Errorf("cape id is required")
+	}
+	return a.changeAccountTextures(uuid, func(ctx context.Context, acc auth.Account) error {
+		return skins.EquipCape(ctx, acc, capeID)
+	})
+}
+
+func (a *App) HideAccountCape(uuid string) error {
+	return a.changeAccountTextures(uuid, skins.HideCape)
+}
+
+func (a *App) UploadAccountCape(uuid, pngBase64 string) error {
+	data, err := decodePNGPayload(pngBase64)
+	if err != nil {
+		return err
+	}
+	return a.changeAccountTextures(uuid, func(ctx context.Context, acc auth.Account) error {
+		return skins.UploadCape(ctx, acc, data)
+	})
+}
+
+func (a *App) GetSkinLibrary() []skins.LibrarySkin {
+	return a.skinLibrary.List()
+}
+
+func (a *App) AddSkinToLibrary(name, pngBase64, model string) (*skins.LibrarySkin, error) {
+	data, err := decodePNGPayload(pngBase64)
+	if err != nil {
+		return nil, err
+	}
+	return a.skinLibrary.Add(name, data, model)
+}
+
+func (a *App) RemoveSkinFromLibrary(id string) error {
+	return a.skinLibrary.Remove(id)
+}
+
+func (a *App) GetLibrarySkinPreview(id string, scale int) (string, error) {
+	if scale <= 0 {
+		scale = defaultBodyScale
+	}
+	if scale > maxBodyScale {
+		scale = maxBodyScale
+	}
+	skin, model, err := a.librarySkin(id)
+	if err != nil {
+		return "", err
+	}
+	img, err := skins.RenderBody(skin, model == skins.ModelSlim, scale)
+	if err != nil {
+		return "", err
+	}
+	return encodeDataURL(img)
+}
+
+func (a *App) librarySkin(id string) (image.Image, string, error) {
+	entry, data, err := a.skinLibrary.Read(id)
+	if err != nil {
+		return nil, "", err
+	}
+	skin, err := png.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, "", err
+	}
+	return skin, entry.Model, nil
+}
+
+func (a *App) ApplyLibrarySkin(uuid, id string) error {
+	entry, data, err := a.skinLibrary.Read(id)
+	if err != nil {
+		return err
+	}
+	return a.changeAccountTextures(uuid, func(ctx context.Context, acc auth.Account) error {
+		return skins.UploadSkin(ctx, acc, data, entry.Model)
+	})
+}
+
+// changeAccountTextures runs a texture change with a fresh session and drops the cached profile.
+func (a *App) changeAccountTextures(uuid string, change func(context.Context, auth.Account) error) error {
+	if err := validation.ValidateUUID(uuid); err != nil {
+		return err
+	}
+	acc, err := a.accountManager.EnsureValidSession(context.Background(), uuid)
+	if err != nil {
+		return err
+	}
+	if err := change(context.Background(), *acc); err != nil {
+		return err
+	}
+	a.skinCache.Invalidate(uuid)
+	return nil
+}
+
+func decodePNGPayload(payload string) ([]byte, error) {
+	payload = strings.TrimPrefix(strings.TrimSpace(payload), "data:image/png;base64,")
+	data, err := base64.StdEncoding.DecodeString(payload)
+	if err != nil {
+		return nil, fmt.Errorf("invalid PNG payload: %w", err)
+	}
+	return data, nil
+}
+
+func encodeDataURL(img image.Image) (string, error) {
+	data, err := skins.EncodePNG(img)
+	if err != nil {
+		return "", err
+	}
+	return "data:image/png;base64," + base64.StdEncoding.EncodeToString(data), nil
+}
+
+type LocalSkinServerStatus struct {
+	Enabled bool     `json:"enabled"`
+	Running bool     `json:"running"`
+	URL     string   `json:"url,omitempty"`
+	LAN     bool     `json:"lan"`
+	LANURLs []string `json:"lanUrls,omitempty"`
+}
+
+func (a *App) SetOfflineAccountSkin(uuid, skinID string) error {
+	if err := validation.ValidateUUID(uuid); err != nil {
+		return err
+	}
+	if skinID != "" {
+		if _, _, err := a.skinLibrary.Read(skinID); err != nil {
+			return err
+		}
+	}
+	return a.accountManager.SetLocalSkin(uuid, skinID)
+}
+
+func (a *App) GetLocalSkinServerStatus() LocalSkinServerStatus {
+	s := a.settingsManager.Get()
+	status := LocalSkinServerStatus{Enabled: s.LocalSkinServer, LAN: s.LocalSkinServerLAN}
+
+	a.localYggdrasilMu.Lock()
+	defer a.localYggdrasilMu.Unlock()
+	if a.localYggdrasil != nil {
+		status.URL = a.localYggdrasil.URL()
+		status.Running = status.URL != ""
+		status.LANURLs = a.localYggdrasil.LANURLs()
+	}
+	return status
+}
+
+// offlineSkinServer returns the Yggdrasil server and prefetched metadata for an offline launch:
+// the configured LAN host once the account is published to it, otherwise the local server.
+func (a *App) offlineSkinServer(instanceID string, account *auth.Account, s settings.LauncherSettings) (string, string, error) {
+	if host := strings.TrimRight(strings.TrimSpace(s.LocalSkinServerHost), "/"); host != "" {
+		a.emitLaunchStatus(instanceID, "Joining LAN skin server...")
+		prefetched, err := services.PublishGuestProfile(context.Background(), host, a.guestProfile(account))
+		if err == nil {
+			return host, prefetched, nil
+		}
+		logging.Warn("LAN skin server %s unavailable, using the local one: %v", host, err)
+	}
+
+	a.emitLaunchStatus(instanceID, "Starting local skin server...")
+	server, url, err := a.ensureLocalYggdrasil(s)
+	if err != nil {
+		return "", "", err
+	}
+	return url, server.PrefetchedMetadata(), nil
+}
+
+func (a *App) guestProfile(account *auth.Account) services.GuestProfile {
+	guest := services.GuestProfile{ID: account.UUID, Name: account.Username}
+	if account.LocalSkinID != "" {
+		if entry, data, err := a.skinLibrary.Read(account.LocalSkinID); err == nil {
+			guest.Model = entry.Model
+			guest.Skin = data
+		}
+	}
+	return guest
+}
+
+// ensureLocalYggdrasil starts the Yggdrasil server that publishes offline account skins, on
+// loopback or, for a LAN host, on all interfaces.
+func (a *App) ensureLocalYggdrasil(s settings.LauncherSettings) (*services.LocalYggdrasil, string, error) {
+	a.localYggdrasilMu.Lock()
+	defer a.localYggdrasilMu.Unlock()
+
+	if a.localYggdrasil == nil {
+		key, err := services.LoadOrCreateSigningKey(filepath.Join(constants.GetConfigDir(), "local-yggdrasil.pem"))
+		if err != nil {
+			return nil, "", err
+		}
+		a.localYggdrasil = services.NewLocalYggdrasil(key, a.localProfiles, func(id string) ([]byte, error) {
+			_, data, err := a.skinLibrary.Read(id)
+			return data, err
+		})
+	}
+
+	addr := ""
+	if s.LocalSkinServerLAN {
+		port := s.LocalSkinServerPort
+		if port == 0 {
+			port = settings.DefaultLocalSkinServerPort
+		}
+		addr = fmt.Sprintf(":%d", port)
+	}
+	a.localYggdrasil.SetAcceptGuests(s.LocalSkinServerLAN)
+	url, err := a.localYggdrasil.Start(addr)
+	if err != nil {
+		return nil, "", err
+	}
+	return a.localYggdrasil, url, nil
+}
+
+func validateSkinServerSettings(s settings.LauncherSettings) error {
+	if p := s.LocalSkinServerPort; p != 0 && (p < 1024 || p > 65535) {
+		return fmt.Errorf("skin server port must be between 1024 and 65535")
+	}
+	if host := strings.TrimSpace(s.LocalSkinServerHost); host != "" {
+		if !strings.HasPrefix(host, "http://") && !strings.HasPrefix(host, "https://") {
+			return fmt.Errorf("LAN skin server URL needs a scheme: %s", host)
+		}
+		if err := validation.ValidateServerURL(host); err != nil {
+			return fmt.Errorf("invalid LAN skin server URL: %w", err)
+		}
+	}
+	return nil
+}
+
+func (a *App) localProfiles() []services.LocalProfile {
+	var profiles []services.LocalProfile
+	for _, acc := range a.accountManager.GetAccounts() {
+		if acc.Type != auth.AccountTypeOffline {
+			continue
+		}
+		profile := services.LocalProfile{UUID: acc.UUID, Name: acc.Username}
+		if acc.LocalSkinID != "" {
+			if entry, _, err := a.skinLibrary.Read(acc.LocalSkinID); err == nil {
+				profile.SkinID = entry.ID
+				profile.Model = entry.Model
+			}
+		}
+		profiles = append(profiles, profile)
+	}
+	return profiles
+}
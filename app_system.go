@@ -3,11 +3,10 @@ package main
 import (
 	"NezordLauncher/pkg/constants"
 	"NezordLauncher/pkg/downloader"
-	"NezordLauncher/pkg/fabric"
 	"NezordLauncher/pkg/javascanner"
 	"NezordLauncher/pkg/models"
+	"NezordLauncher/pkg/modloader"
 	"NezordLauncher/pkg/network"
-	"NezordLauncher/pkg/quilt"
 	"NezordLauncher/pkg/settings"
 	"NezordLauncher/pkg/system"
 	"NezordLauncher/pkg/updater"
@@ -20,46 +19,53 @@ import (
 	"runtime"
 )
 
+// GetVanillaVersions lists releases and the old alphas and betas, which loaders such as Babric
+// target. Snapshots are left out.
 func (a *App) GetVanillaVersions() ([]models.Version, error) {
 	manifest, err := downloader.FetchVersionManifest()
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch manifest: %w", err)
 	}
 
-	var releases []models.Version
+	var versions []models.Version
 	for _, v := range manifest.Versions {
-		if v.Type == "release" {
-			releases = append(releases, v)
+		switch v.Type {
+		case "release", "old_beta", "old_alpha":
+			versions = append(versions, v)
 		}
 	}
 
-	return releases, nil
+	return versions, nil
 }
 
-func (a *App) GetFabricLoaders(mcVersion string) ([]string, error) {
-	loaders, err := fabric.GetLoaderVersions(mcVersion)
-	if err != nil {
-		return nil, err
-	}
+type ModloaderInfo struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
 
-	var versions []string
-	for _, l := range loaders {
-		versions = append(versions, l.Loader.Version)
+func (a *App) GetModloaders() []ModloaderInfo {
+	var result []ModloaderInfo
+	for _, p := range modloader.List() {
+		result = append(result, ModloaderInfo{ID: p.ID(), Name: p.Name()})
 	}
-	return versions, nil
+	return result
 }
 
-func (a *App) GetQuiltLoaders(mcVersion string) ([]string, error) {
-	loaders, err := quilt.GetLoaderVersions(mcVersion)
+func (a *App) GetLoaderVersions(loaderType, mcVersion string) ([]modloader.Version, error) {
+	provider, err := modloader.Lookup(loaderType)
 	if err != nil {
 		return nil, err
 	}
+	return provider.LoaderVersions(mcVersion)
+}
 
-	var versions []string
-	for _, l := range loaders {
-		versions = append(versions, l.Loader.Version)
+// GetLoaderGameVersions lists the game versions a loader can run, so the UI can hide the others.
+func (a *App) GetLoaderGameVersions(loaderType string) ([]modloader.Version, error) {
+	provider, err := modloader.Lookup(loaderType)
+	if err != nil {
+		return nil, err
 	}
-	return versions, nil
+	return provider.GameVersions()
 }
 
 func (a *App) GetSystemPlatform() system.SystemInfo {
@@ -71,7 +77,17 @@ func (a *App) GetSettings() settings.LauncherSettings {
 }
 
 func (a *App) UpdateGlobalSettings(s settings.LauncherSettings) error {
-	return a.settingsManager.Update(s)
+	if err := validateMirrorSettings(s); err != nil {
+		return err
+	}
+	if err := validateSkinServerSettings(s); err != nil {
+		return err
+	}
+	if err := a.settingsManager.Update(s); err != nil {
+		return err
+	}
+	a.applyDownloadSettings(s)
+	return nil
 }
 
 // CheckForUpdates checks if a new version is available
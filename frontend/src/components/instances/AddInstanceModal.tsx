@@ -1,4 +1,4 @@
-import { useState, useEffect } from "react";
+import { useState, useEffect, useMemo } from "react";
 import { X, Loader2 } from "lucide-react";
 import { Button } from "@/components/ui/button";
 import { Input } from "@/components/ui/input";
@@ -21,6 +21,7 @@ export function AddInstanceModal({ isOpen, onClose }: AddInstanceModalProps) {
   const {
     minecraftVersions,
     fetchModloaders,
+    fetchLoaderGameVersions,
     createInstance,
     refreshInstances,
   } = useInstanceStore();
@@ -31,6 +32,8 @@ export function AddInstanceModal({ isOpen, onClose }: AddInstanceModalProps) {
   const [loaderVersion, setLoaderVersion] = useState("");
 
   const [availableLoaders, setAvailableLoaders] = useState<string[]>([]);
+  const [supportedVersions, setSupportedVersions] =
+    useState<Set<string> | null>(null);
   const [isLoadingLoaders, setIsLoadingLoaders] = useState(false);
   const [isCreating, setIsCreating] = useState(false);
 
@@ -43,6 +46,34 @@ export function AddInstanceModal({ isOpen, onClose }: AddInstanceModalProps) {
     }
   }, [isOpen, minecraftVersions]);
 
+  useEffect(() => {
+    let cancelled = false;
+    setSupportedVersions(null);
+    fetchLoaderGameVersions(modloader).then((supported) => {
+      if (!cancelled) setSupportedVersions(supported);
+    });
+    return () => {
+      cancelled = true;
+    };
+  }, [modloader]);
+
+  const gameVersions = useMemo(
+    () =>
+      supportedVersions
+        ? minecraftVersions.filter((v) => supportedVersions.has(v.id))
+        : minecraftVersions,
+    [minecraftVersions, supportedVersions],
+  );
+
+  useEffect(() => {
+    if (
+      gameVersions.length > 0 &&
+      !gameVersions.some((v) => v.id === gameVersion)
+    ) {
+      setGameVersion(gameVersions[0].id);
+    }
+  }, [gameVersions]);
+
   useEffect(() => {
     if (modloader === "vanilla" || !gameVersion) {
       setAvailableLoaders([]);
@@ -129,12 +160,12 @@ export function AddInstanceModal({ isOpen, onClose }: AddInstanceModalProps) {
                 </label>
                 <Select
                   value={
-                    minecraftVersions.some((v) => v.id === gameVersion)
+                    gameVersions.some((v) => v.id === gameVersion)
                       ? gameVersion
                       : undefined
                   }
                   onValueChange={setGameVersion}
-                  disabled={minecraftVersions.length === 0}
+                  disabled={gameVersions.length === 0}
                 >
                   <SelectTrigger className="w-full bg-zinc-900 border-zinc-800 text-white text-sm h-10 focus:ring-1 focus:ring-primary/50">
                     <SelectValue placeholder="Select version" />
@@ -143,7 +174,7 @@ export function AddInstanceModal({ isOpen, onClose }: AddInstanceModalProps) {
                     className="max-h-60 bg-zinc-900 border-zinc-800 text-zinc-300 w-[var(--radix-select-trigger-width)]"
                     position="popper"
                   >
-                    {minecraftVersions.map((v) => (
+                    {gameVersions.map((v) => (
                       <SelectItem
                         key={v.id}
                         value={v.id}
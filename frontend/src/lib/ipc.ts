@@ -9,6 +9,9 @@ export const IPC_EVENTS = {
   LAUNCH_ERROR: "launch.error",
   LAUNCH_GAME_LOG: "launch.game.log",
   LAUNCH_EXIT: "launch.exit",
+  AUTH_STATUS: "auth.status",
+  AUTH_DEVICE_CODE: "auth.device.code",
+  AUTH_ERROR: "auth.error",
 } as const;
 
 export type IpcEventName = (typeof IPC_EVENTS)[keyof typeof IPC_EVENTS];
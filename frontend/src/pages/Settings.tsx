@@ -112,6 +112,13 @@ export function SettingsPage() {
       autoUpdateEnabled: launcherSettings.autoUpdateEnabled,
       gpuPreference,
       wrapperCommand,
+      maxConcurrentDownloads: launcherSettings.maxConcurrentDownloads,
+      mirrorPreset: launcherSettings.mirrorPreset,
+      mirrors: launcherSettings.mirrors,
+      localSkinServer: launcherSettings.localSkinServer,
+      localSkinServerLan: launcherSettings.localSkinServerLan,
+      localSkinServerPort: launcherSettings.localSkinServerPort,
+      localSkinServerHost: launcherSettings.localSkinServerHost,
     };
     updateLauncherSettings(next);
   }, [
@@ -158,6 +165,13 @@ export function SettingsPage() {
       autoUpdateEnabled: current?.autoUpdateEnabled ?? true,
       gpuPreference: current?.gpuPreference || gpuPreference,
       wrapperCommand: current?.wrapperCommand || wrapperCommand,
+      maxConcurrentDownloads: current?.maxConcurrentDownloads,
+      mirrorPreset: current?.mirrorPreset,
+      mirrors: current?.mirrors,
+      localSkinServer: current?.localSkinServer,
+      localSkinServerLan: current?.localSkinServerLan,
+      localSkinServerPort: current?.localSkinServerPort,
+      localSkinServerHost: current?.localSkinServerHost,
     };
     await updateLauncherSettings(next);
     setIsSavingPath(false);
@@ -9,8 +9,8 @@ import {
   CreateInstance,
   GetInstances,
   GetVanillaVersions,
-  GetFabricLoaders,
-  GetQuiltLoaders,
+  GetLoaderVersions,
+  GetLoaderGameVersions,
   UpdateInstanceSettings,
   DeleteInstance,
 } from "../wailsjs/go/main/App";
@@ -94,20 +94,28 @@ function useInstanceLogic() {
   ): Promise<string[]> => {
     if (type === "vanilla") return [];
     try {
-      if (type === "fabric") {
-        const res = await GetFabricLoaders(mcVersion);
-        return res || [];
-      }
-      if (type === "quilt") {
-        const res = await GetQuiltLoaders(mcVersion);
-        return res || [];
-      }
+      const res = await GetLoaderVersions(type, mcVersion);
+      return (res || []).map((v) => v.version);
     } catch (e) {
       console.error(`Failed to fetch ${type} loaders`, e);
     }
     return [];
   };
 
+  // Returns null when every game version is allowed.
+  const fetchLoaderGameVersions = async (
+    type: ModloaderType,
+  ): Promise<Set<string> | null> => {
+    if (type === "vanilla") return null;
+    try {
+      const res = await GetLoaderGameVersions(type);
+      return new Set((res || []).map((v) => v.version));
+    } catch (e) {
+      console.error(`Failed to fetch ${type} game versions`, e);
+    }
+    return null;
+  };
+
   return {
     minecraftVersions,
     instances,
@@ -116,6 +124,7 @@ function useInstanceLogic() {
     deleteInstance,
     refreshInstances,
     fetchModloaders,
+    fetchLoaderGameVersions,
   };
 }
 
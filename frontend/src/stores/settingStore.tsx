@@ -57,6 +57,13 @@ function useSettingsLogic() {
       autoUpdateEnabled: settings?.autoUpdateEnabled === false ? false : true,
       gpuPreference: settings?.gpuPreference || "auto",
       wrapperCommand: settings?.wrapperCommand || "",
+      maxConcurrentDownloads: settings?.maxConcurrentDownloads || 0,
+      mirrorPreset: settings?.mirrorPreset || "",
+      mirrors: settings?.mirrors || [],
+      localSkinServer: settings?.localSkinServer === true,
+      localSkinServerLan: settings?.localSkinServerLan === true,
+      localSkinServerPort: settings?.localSkinServerPort || 0,
+      localSkinServerHost: settings?.localSkinServerHost || "",
     };
   };
 
@@ -19,6 +19,7 @@ export interface InstanceSettings {
   overrideRam: boolean;
   gpuPreference: string;
   wrapperCommand: string;
+  accountUuid?: string;
 }
 
 export interface Instance {
@@ -60,6 +61,20 @@ export interface LauncherSettings {
   autoUpdateEnabled: boolean;
   gpuPreference: string;
   wrapperCommand: string;
+  credentialBackend?: string;
+  localSkinServer?: boolean;
+  localSkinServerLan?: boolean;
+  localSkinServerPort?: number;
+  localSkinServerHost?: string;
+  maxConcurrentDownloads?: number;
+  mirrorPreset?: string;
+  mirrors?: MirrorRule[];
+}
+
+export interface MirrorRule {
+  upstream: string;
+  mirrors: string[];
+  exclusive?: boolean;
 }
 
 export interface EventErrorPayload {
@@ -72,6 +87,7 @@ export interface EventPayload {
   timestamp: string;
   source: string;
   instanceId?: string;
+  jobId?: string;
   status?: string;
   message?: string;
   current?: number;
@@ -42,16 +42,20 @@ export function GetAppVersion() {
   return window['go']['main']['App']['GetAppVersion']();
 }
 
-export function GetFabricLoaders(arg1) {
-  return window['go']['main']['App']['GetFabricLoaders'](arg1);
-}
-
 export function GetInstances() {
   return window['go']['main']['App']['GetInstances']();
 }
 
-export function GetQuiltLoaders(arg1) {
-  return window['go']['main']['App']['GetQuiltLoaders'](arg1);
+export function GetLoaderGameVersions(arg1) {
+  return window['go']['main']['App']['GetLoaderGameVersions'](arg1);
+}
+
+export function GetLoaderVersions(arg1, arg2) {
+  return window['go']['main']['App']['GetLoaderVersions'](arg1, arg2);
+}
+
+export function GetModloaders() {
+  return window['go']['main']['App']['GetModloaders']();
 }
 
 export function GetRuntimeMeta() {
@@ -4,6 +4,7 @@ import {auth} from '../models';
 import {updater} from '../models';
 import {instances} from '../models';
 import {main} from '../models';
+import {modloader} from '../models';
 import {settings} from '../models';
 import {system} from '../models';
 import {models} from '../models';
@@ -31,11 +32,13 @@ export function GetActiveAccount():Promise<auth.Account>;
 
 export function GetAppVersion():Promise<string>;
 
-export function GetFabricLoaders(arg1:string):Promise<Array<string>>;
-
 export function GetInstances():Promise<Array<instances.Instance>>;
 
-export function GetQuiltLoaders(arg1:string):Promise<Array<string>>;
+export function GetLoaderGameVersions(arg1:string):Promise<Array<modloader.Version>>;
+
+export function GetLoaderVersions(arg1:string,arg2:string):Promise<Array<modloader.Version>>;
+
+export function GetModloaders():Promise<Array<main.ModloaderInfo>>;
 
 export function GetRuntimeMeta():Promise<main.AppRuntimeMeta>;
 
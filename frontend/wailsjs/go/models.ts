@@ -156,6 +156,21 @@ export namespace main {
 	    }
 	}
 
+	export class ModloaderInfo {
+	    id: string;
+	    name: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new ModloaderInfo(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.name = source["name"];
+	    }
+	}
+
 }
 
 export namespace models {
@@ -207,6 +222,29 @@ export namespace models {
 
 }
 
+export namespace modloader {
+	
+	export class Version {
+	    version: string;
+	    stable: boolean;
+	    build?: number;
+	    maven?: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new Version(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.version = source["version"];
+	        this.stable = source["stable"];
+	        this.build = source["build"];
+	        this.maven = source["maven"];
+	    }
+	}
+
+}
+
 export namespace settings {
 	
 	export class LauncherSettings {
@@ -0,0 +1,137 @@
+package auth
+
+import (
+	"NezordLauncher/pkg/network"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+const apiLocationHeader = "X-Authlib-Injector-API-Location"
+
+// YggdrasilServer is an authlib-injector compatible server (LittleSkin, Blessing Skin, self-hosted).
+type YggdrasilServer struct {
+	APIRoot  string          `json:"apiRoot"`
+	Name     string          `json:"name"`
+	Metadata json.RawMessage `json:"metadata,omitempty"`
+}
+
+type yggdrasilMeta struct {
+	Meta struct {
+		ServerName string `json:"serverName"`
+	} `json:"meta"`
+}
+
+func (s *YggdrasilServer) AuthServerURL() string {
+	return s.APIRoot + "/authserver"
+}
+
+func (s *YggdrasilServer) SessionServerURL() string {
+	return s.APIRoot + "/sessionserver"
+}
+
+// PrefetchedMetadata is the value for -Dauthlibinjector.yggdrasil.prefetched.
+func (s *YggdrasilServer) PrefetchedMetadata() string {
+	if len(s.Metadata) == 0 {
+		return ""
+	}
+	return base64.StdEncoding.EncodeToString(s.Metadata)
+}
+
+// ResolveYggdrasilServer follows API Location Indication and fetches the server metadata.
+func ResolveYggdrasilServer(rawURL string) (*YggdrasilServer, error) {
+	target := strings.TrimSpace(rawURL)
+	if !strings.Contains(target, "://") {
+		target = "https://" + target
+	}
+
+	client := network.NewHttpClient()
+
+	data, finalURL, err := fetchYggdrasilRoot(client, target)
+	if err != nil {
+		return nil, err
+	}
+
+	var meta yggdrasilMeta
+	if err := json.Unmarshal(data, &meta); err != nil {
+		return nil, fmt.Errorf("%s is not a yggdrasil api root: %w", finalURL, err)
+	}
+
+	name := meta.Meta.ServerName
+	if name == "" {
+		if parsed, err := url.Parse(finalURL); err == nil {
+			name = parsed.Host
+		}
+	}
+
+	return &YggdrasilServer{
+		APIRoot:  strings.TrimSuffix(finalURL, "/"),
+		Name:     name,
+		Metadata: json.RawMessage(data),
+	}, nil
+}
+
+func fetchYggdrasilRoot(client *network.HttpClient, target string) ([]byte, string, error) {
+	data, location, err := getWithAPILocation(client, target)
+	if err != nil {
+		return nil, "", err
+	}
+
+	if location != "" {
+		resolved, err := resolveAPILocation(target, location)
+		if err != nil {
+			return nil, "", err
+		}
+		if resolved != target {
+			data, _, err = getWithAPILocation(client, resolved)
+			if err != nil {
+				return nil, "", err
+			}
+			target = resolved
+		}
+	}
+
+	return data, target, nil
+}
+
+func getWithAPILocation(client *network.HttpClient, target string) ([]byte, string, error) {
+	req, err := http.NewRequest("GET", target, nil)
+	if err != nil {
+		return nil, "", err
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to reach yggdrasil server: %w", err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, "", err
+	}
+
+	location := resp.Header.Get(apiLocationHeader)
+	if resp.StatusCode != http.StatusOK && location == "" {
+		return nil, "", fmt.Errorf("yggdrasil server returned status %d", resp.StatusCode)
+	}
+
+	return data, location, nil
+}
+
+func resolveAPILocation(base, location string) (string, error) {
+	baseURL, err := url.Parse(base)
+	if err != nil {
+		return "", err
+	}
+	ref, err := url.Parse(location)
+	if err != nil {
+		return "", fmt.Errorf("invalid %s header: %w", apiLocationHeader, err)
+	}
+	return baseURL.ResolveReference(ref).String(), nil
+}
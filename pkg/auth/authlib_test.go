@@ -0,0 +1,45 @@
+package auth
+
+import (
+	"encoding/base64"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestResolveYggdrasilServer_APILocation(t *testing.T) {
+	metadata := `{"meta":{"serverName":"Community Skins"},"skinDomains":["skins.example.org"]}`
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/":
+			w.Header().Set("X-Authlib-Injector-API-Location", "/api/yggdrasil/")
+			w.Write([]byte("<html>landing page</html>"))
+		case "/api/yggdrasil/":
+			w.Write([]byte(metadata))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	resolved, err := ResolveYggdrasilServer(server.URL + "/")
+	if err != nil {
+		t.Fatalf("ResolveYggdrasilServer failed: %v", err)
+	}
+
+	if resolved.APIRoot != server.URL+"/api/yggdrasil" {
+		t.Errorf("Unexpected API root %s", resolved.APIRoot)
+	}
+	if resolved.Name != "Community Skins" {
+		t.Errorf("Unexpected server name %s", resolved.Name)
+	}
+	if resolved.AuthServerURL() != server.URL+"/api/yggdrasil/authserver" {
+		t.Errorf("Unexpected auth server URL %s", resolved.AuthServerURL())
+	}
+
+	decoded, err := base64.StdEncoding.DecodeString(resolved.PrefetchedMetadata())
+	if err != nil || string(decoded) != metadata {
+		t.Errorf("Prefetched metadata does not round-trip: %s", decoded)
+	}
+}
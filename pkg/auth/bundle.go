@@ -0,0 +1,216 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+const (
+	bundleVersion       = 1
+	bundleMode          = "bundle"
+	minBundlePassphrase = 8
+
+	ImportStatusValid      = "valid"
+	ImportStatusRelogin    = "relogin"
+	ImportStatusOffline    = "offline"
+	ImportStatusUnverified = "unverified"
+)
+
+var (
+	ErrInvalidBundle         = errors.New("not a valid account bundle")
+	ErrWrongBundlePassphrase = errors.New("wrong passphrase or corrupted account bundle")
+)
+
+type bundleAccount struct {
+	Account
+	AccessToken  string `json:"accessToken,omitempty"`
+	ClientToken  string `json:"clientToken,omitempty"`
+	RefreshToken string `json:"refreshToken,omitempty"`
+}
+
+type accountBundle struct {
+	CreatedAt  time.Time         `json:"createdAt"`
+	ActiveUUID string            `json:"activeUUID"`
+	Accounts   []bundleAccount   `json:"accounts"`
+	Files      map[string][]byte `json:"files,omitempty"`
+}
+
+type ImportedAccount struct {
+	UUID     string      `json:"uuid"`
+	Username string      `json:"username"`
+	Type     AccountType `json:"type"`
+	Status   string      `json:"status"`
+	Message  string      `json:"message,omitempty"`
+}
+
+type ImportReport struct {
+	Accounts []ImportedAccount `json:"accounts"`
+}
+
+// NeedsRelogin lists the imported accounts that the auth servers rejected.
+func (r *ImportReport) NeedsRelogin() []ImportedAccount {
+	var result []ImportedAccount
+	for _, acc := range r.Accounts {
+		if acc.Status == ImportStatusRelogin {
+			result = append(result, acc)
+		}
+	}
+	return result
+}
+
+// ExportBundle seals every account with its tokens into a passphrase-encrypted blob.
+// Files carries extra data that belongs with the accounts, such as the skin library.
+func (m *AccountManager) ExportBundle(passphrase string, files map[string][]byte) ([]byte, error) {
+	if len(passphrase) < minBundlePassphrase {
+		return nil, fmt.Errorf("passphrase must be at least %d characters", minBundlePassphrase)
+	}
+
+	m.mu.RLock()
+	bundle := accountBundle{
+		CreatedAt:  time.Now().UTC(),
+		ActiveUUID: m.Data.ActiveUUID,
+		Files:      files,
+	}
+	for _, acc := range m.Data.Accounts {
+		bundle.Accounts = append(bundle.Accounts, bundleAccount{
+			Account:      acc,
+			AccessToken:  acc.AccessToken,
+			ClientToken:  acc.ClientToken,
+			RefreshToken: acc.RefreshToken,
+		})
+	}
+	m.mu.RUnlock()
+
+	plaintext, err := json.Marshal(bundle)
+	if err != nil {
+		return nil, err
+	}
+
+	salt := make([]byte, 16)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+	key := deriveKey([]byte(passphrase), salt, fileStoreIterations)
+	nonce, ciphertext, err := encryptGCM(key, plaintext)
+	if err != nil {
+		return nil, err
+	}
+
+	return json.MarshalIndent(encryptedFile{
+		Version:    bundleVersion,
+		Mode:       bundleMode,
+		KDF:        "pbkdf2-sha256",
+		Iterations: fileStoreIterations,
+		Salt:       salt,
+		Nonce:      nonce,
+		Ciphertext: ciphertext,
+	}, "", "  ")
+}
+
+// ImportBundle merges the accounts of a bundle into the manager, replacing accounts with the
+// same UUID, then checks every online session. The bundle's extra files are returned as-is.
+func (m *AccountManager) ImportBundle(ctx context.Context, data []byte, passphrase string) (*ImportReport, map[string][]byte, error) {
+	bundle, err := openBundle(data, passphrase)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	merged := make([]Account, 0, len(bundle.Accounts))
+	m.mu.Lock()
+	for _, imported := range bundle.Accounts {
+		acc := imported.Account
+		acc.AccessToken = imported.AccessToken
+		acc.ClientToken = imported.ClientToken
+		acc.RefreshToken = imported.RefreshToken
+		acc.NeedsRelogin = false
+
+		replaced := false
+		for i := range m.Data.Accounts {
+			if m.Data.Accounts[i].UUID == acc.UUID {
+				m.Data.Accounts[i] = acc
+				replaced = true
+				break
+			}
+		}
+		if !replaced {
+			m.Data.Accounts = append(m.Data.Accounts, acc)
+		}
+		merged = append(merged, acc)
+	}
+	if m.Data.ActiveUUID == "" {
+		m.Data.ActiveUUID = bundle.ActiveUUID
+	}
+	saveErr := m.saveInternal()
+	m.mu.Unlock()
+
+	if saveErr != nil {
+		return nil, nil, saveErr
+	}
+
+	report := &ImportReport{}
+	for _, acc := range merged {
+		report.Accounts = append(report.Accounts, m.checkImportedAccount(ctx, acc))
+	}
+	return report, bundle.Files, nil
+}
+
+func (m *AccountManager) checkImportedAccount(ctx context.Context, acc Account) ImportedAccount {
+	result := ImportedAccount{UUID: acc.UUID, Username: acc.Username, Type: acc.Type, Status: ImportStatusValid}
+	if acc.Type == AccountTypeOffline {
+		result.Status = ImportStatusOffline
+		return result
+	}
+
+	// EnsureValidSession tolerates unreachable Yggdrasil servers; the report should not.
+	if client := yggdrasilClientFor(acc); client != nil && acc.AccessToken != "" {
+		if err := client.Validate(acc.AccessToken, acc.ClientToken); err != nil && !isInvalidTokenError(err) {
+			result.Status = ImportStatusUnverified
+			result.Message = err.Error()
+			return result
+		}
+	}
+
+	if _, err := m.EnsureValidSession(ctx, acc.UUID); err != nil {
+		result.Status = ImportStatusUnverified
+		if errors.Is(err, ErrReloginRequired) {
+			result.Status = ImportStatusRelogin
+		}
+		result.Message = err.Error()
+	}
+	return result
+}
+
+func openBundle(data []byte, passphrase string) (*accountBundle, error) {
+	var file encryptedFile
+	if err := json.Unmarshal(data, &file); err != nil || file.Mode != bundleMode {
+		return nil, ErrInvalidBundle
+	}
+	if file.Version != bundleVersion {
+		return nil, fmt.Errorf("unsupported account bundle version %d", file.Version)
+	}
+	// The iteration count comes from the imported file; an arbitrary one could stall the import.
+	if file.Iterations != fileStoreIterations {
+		return nil, ErrInvalidBundle
+	}
+
+	key := deriveKey([]byte(passphrase), file.Salt, file.Iterations)
+	plaintext, err := decryptGCM(key, file.Nonce, file.Ciphertext)
+	if err != nil {
+		return nil, ErrWrongBundlePassphrase
+	}
+
+	var bundle accountBundle
+	if err := json.Unmarshal(plaintext, &bundle); err != nil {
+		return nil, fmt.Errorf("failed to parse account bundle: %w", err)
+	}
+	for _, acc := range bundle.Accounts {
+		if acc.UUID == "" || acc.Type == "" {
+			return nil, ErrInvalidBundle
+		}
+	}
+	return &bundle, nil
+}
@@ -0,0 +1,100 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"path/filepath"
+	"testing"
+)
+
+func TestAccountBundle_RoundTrip(t *testing.T) {
+	server := newYggdrasilStub(t, http.StatusForbidden)
+	defer server.Close()
+	useTestCredentialStore(t)
+	t.Setenv("NEZORD_ELYBY_AUTH_URL", server.URL+"/auth/authenticate")
+
+	source := newSessionTestManager(t, Account{
+		UUID:        "3f1d6a2b8c9e4f0a1b2c3d4e5f6a7b8c",
+		Username:    "Valid",
+		Type:        AccountTypeElyBy,
+		AccessToken: "fresh-token",
+		ClientToken: "client",
+	})
+	source.Data.Accounts = append(source.Data.Accounts,
+		Account{UUID: "0a1b2c3d4e5f60718293a4b5c6d7e8f9", Username: "Revoked", Type: AccountTypeElyBy, AccessToken: "old", ClientToken: "client"},
+		Account{UUID: GenerateOfflineUUID("Steve"), Username: "Steve", Type: AccountTypeOffline, LocalSkinID: "abc"},
+	)
+
+	if _, err := source.ExportBundle("short", nil); err == nil {
+		t.Error("expected short passphrase to be rejected")
+	}
+
+	data, err := source.ExportBundle("correct horse", map[string][]byte{"skins/library.json": []byte("[]")})
+	if err != nil {
+		t.Fatalf("ExportBundle failed: %v", err)
+	}
+
+	target := &AccountManager{filePath: filepath.Join(t.TempDir(), "accounts.json")}
+
+	if _, _, err := target.ImportBundle(context.Background(), data, "wrong passphrase"); !errors.Is(err, ErrWrongBundlePassphrase) {
+		t.Fatalf("expected ErrWrongBundlePassphrase, got %v", err)
+	}
+
+	report, files, err := target.ImportBundle(context.Background(), data, "correct horse")
+	if err != nil {
+		t.Fatalf("ImportBundle failed: %v", err)
+	}
+	if string(files["skins/library.json"]) != "[]" {
+		t.Errorf("bundle files not returned: %v", files)
+	}
+
+	statuses := map[string]string{}
+	for _, acc := range report.Accounts {
+		statuses[acc.Username] = acc.Status
+	}
+	if statuses["Valid"] != ImportStatusValid || statuses["Revoked"] != ImportStatusRelogin || statuses["Steve"] != ImportStatusOffline {
+		t.Errorf("unexpected statuses: %v", statuses)
+	}
+	if relogin := report.NeedsRelogin(); len(relogin) != 1 || relogin[0].Username != "Revoked" {
+		t.Errorf("unexpected relogin list: %v", relogin)
+	}
+
+	valid, err := target.GetAccount("3f1d6a2b8c9e4f0a1b2c3d4e5f6a7b8c")
+	if err != nil || valid.AccessToken != "fresh-token" {
+		t.Errorf("tokens not restored: %+v %v", valid, err)
+	}
+	steve, _ := target.GetAccount(GenerateOfflineUUID("Steve"))
+	if steve == nil || steve.LocalSkinID != "abc" {
+		t.Errorf("offline account metadata not restored: %+v", steve)
+	}
+	if target.Data.ActiveUUID != "3f1d6a2b8c9e4f0a1b2c3d4e5f6a7b8c" {
+		t.Errorf("active account not restored: %s", target.Data.ActiveUUID)
+	}
+}
+
+func TestAccountBundle_RejectsForeignFiles(t *testing.T) {
+	target := &AccountManager{filePath: filepath.Join(t.TempDir(), "accounts.json")}
+	if _, _, err := target.ImportBundle(context.Background(), []byte(`{"mode":"machine"}`), "whatever"); !errors.Is(err, ErrInvalidBundle) {
+		t.Errorf("expected ErrInvalidBundle, got %v", err)
+	}
+}
+
+func TestAccountBundle_RejectsForeignIterations(t *testing.T) {
+	target := &AccountManager{filePath: filepath.Join(t.TempDir(), "accounts.json")}
+	for _, iterations := range []int{0, 1, 1 << 40} {
+		data, _ := json.Marshal(encryptedFile{
+			Version:    bundleVersion,
+			Mode:       bundleMode,
+			KDF:        "pbkdf2-sha256",
+			Iterations: iterations,
+			Salt:       make([]byte, 16),
+			Nonce:      make([]byte, 12),
+			Ciphertext: make([]byte, 32),
+		})
+		if _, _, err := target.ImportBundle(context.Background(), data, "correct horse"); !errors.Is(err, ErrInvalidBundle) {
+			t.Errorf("iterations %d: expected ErrInvalidBundle, got %v", iterations, err)
+		}
+	}
+}
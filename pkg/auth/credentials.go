@@ -0,0 +1,183 @@
+package auth
+
+import (
+	"NezordLauncher/pkg/constants"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+const (
+	CredentialBackendAuto       = "auto"
+	CredentialBackendKeyring    = "keyring"
+	CredentialBackendFile       = "file"
+	CredentialBackendPassphrase = "passphrase"
+)
+
+var ErrCredentialsLocked = errors.New("credential store is locked, passphrase required")
+
+type CredentialStore interface {
+	Name() string
+	Set(account, tokenType, token string) error
+	Get(account, tokenType string) (string, error)
+	Delete(account, tokenType string) error
+}
+
+type CredentialBackendInfo struct {
+	Active           string `json:"active"`
+	Requested        string `json:"requested"`
+	KeyringAvailable bool   `json:"keyringAvailable"`
+	Encrypted        bool   `json:"encrypted"`
+	Locked           bool   `json:"locked"`
+	FallbackReason   string `json:"fallbackReason,omitempty"`
+}
+
+var (
+	storeMu     sync.RWMutex
+	activeStore CredentialStore = &KeyringStore{}
+	activeInfo                  = CredentialBackendInfo{Active: CredentialBackendKeyring, Requested: CredentialBackendAuto}
+)
+
+func GetCredentialsFilePath(backend string) string {
+	if backend == CredentialBackendPassphrase {
+		return filepath.Join(constants.GetConfigDir(), "credentials-passphrase.enc")
+	}
+	return filepath.Join(constants.GetConfigDir(), "credentials.enc")
+}
+
+// OpenCredentialStore picks a backend for the requested mode, falling back to the
+// machine-key file store when the OS keyring cannot be reached.
+func OpenCredentialStore(requested, passphrase string) (CredentialStore, CredentialBackendInfo, error) {
+	if requested == "" {
+		requested = CredentialBackendAuto
+	}
+	info := CredentialBackendInfo{Requested: requested}
+
+	keyringStore := &KeyringStore{}
+	keyringErr := keyringStore.Probe()
+	info.KeyringAvailable = keyringErr == nil
+
+	switch requested {
+	case CredentialBackendPassphrase:
+		info.Active = CredentialBackendPassphrase
+		info.Encrypted = true
+		if passphrase == "" {
+			info.Locked = true
+			return &lockedStore{}, info, nil
+		}
+		store, err := NewPassphraseFileStore(GetCredentialsFilePath(CredentialBackendPassphrase), passphrase)
+		if err != nil {
+			return nil, info, err
+		}
+		return store, info, nil
+	case CredentialBackendFile:
+		store, err := NewMachineFileStore(GetCredentialsFilePath(CredentialBackendFile))
+		if err != nil {
+			return nil, info, err
+		}
+		info.Active = CredentialBackendFile
+		info.Encrypted = true
+		return store, info, nil
+	case CredentialBackendKeyring, CredentialBackendAuto:
+		if keyringErr == nil {
+			info.Active = CredentialBackendKeyring
+			// An earlier start may have fallen back to the file store before the keyring
+			// daemon was up; its tokens move over as they are read.
+			filePath := GetCredentialsFilePath(CredentialBackendFile)
+			if _, err := os.Stat(filePath); requested == CredentialBackendAuto && err == nil {
+				if fileStore, err := NewMachineFileStore(filePath); err == nil {
+					return &migratingStore{primary: keyringStore, fallback: fileStore}, info, nil
+				}
+			}
+			return keyringStore, info, nil
+		}
+		store, err := NewMachineFileStore(GetCredentialsFilePath(CredentialBackendFile))
+		if err != nil {
+			return nil, info, fmt.Errorf("keyring unavailable (%v) and file store failed: %w", keyringErr, err)
+		}
+		info.Active = CredentialBackendFile
+		info.Encrypted = true
+		info.FallbackReason = keyringErr.Error()
+		return store, info, nil
+	default:
+		return nil, info, fmt.Errorf("unknown credential backend: %s", requested)
+	}
+}
+
+func UseCredentialStore(store CredentialStore, info CredentialBackendInfo) {
+	storeMu.Lock()
+	defer storeMu.Unlock()
+	activeStore = store
+	activeInfo = info
+}
+
+func CurrentCredentialStore() CredentialStore {
+	storeMu.RLock()
+	defer storeMu.RUnlock()
+	return activeStore
+}
+
+func GetCredentialBackendInfo() CredentialBackendInfo {
+	storeMu.RLock()
+	defer storeMu.RUnlock()
+	return activeInfo
+}
+
+func SetSecureToken(username, tokenType, token string) error {
+	return CurrentCredentialStore().Set(username, tokenType, token)
+}
+
+func GetSecureToken(username, tokenType string) (string, error) {
+	return CurrentCredentialStore().Get(username, tokenType)
+}
+
+func DeleteSecureToken(username, tokenType string) error {
+	return CurrentCredentialStore().Delete(username, tokenType)
+}
+
+// migratingStore serves tokens from primary and moves the ones it lacks over from fallback.
+type migratingStore struct {
+	primary  CredentialStore
+	fallback CredentialStore
+}
+
+func (s *migratingStore) Name() string { return s.primary.Name() }
+
+func (s *migratingStore) Set(account, tokenType, token string) error {
+	return s.primary.Set(account, tokenType, token)
+}
+
+func (s *migratingStore) Get(account, tokenType string) (string, error) {
+	token, err := s.primary.Get(account, tokenType)
+	if err != nil || token != "" {
+		return token, err
+	}
+	token, err = s.fallback.Get(account, tokenType)
+	if err != nil || token == "" {
+		return "", nil
+	}
+	if err := s.primary.Set(account, tokenType, token); err == nil {
+		_ = s.fallback.Delete(account, tokenType)
+	}
+	return token, nil
+}
+
+func (s *migratingStore) Delete(account, tokenType string) error {
+	err := s.primary.Delete(account, tokenType)
+	if fallbackErr := s.fallback.Delete(account, tokenType); err == nil {
+		err = fallbackErr
+	}
+	return err
+}
+
+type lockedStore struct{}
+
+func (s *lockedStore) Name() string { return CredentialBackendPassphrase }
+
+func (s *lockedStore) Set(account, tokenType, token string) error { return ErrCredentialsLocked }
+
+func (s *lockedStore) Get(account, tokenType string) (string, error) { return "", ErrCredentialsLocked }
+
+func (s *lockedStore) Delete(account, tokenType string) error { return ErrCredentialsLocked }
@@ -0,0 +1,229 @@
+package auth
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"golang.org/x/crypto/pbkdf2"
+)
+
+const (
+	fileStoreVersion    = 1
+	fileStoreIterations = 210000
+	fileStoreKeyLen     = 32
+
+	fileStoreModeMachine    = "machine"
+	fileStoreModePassphrase = "passphrase"
+)
+
+var ErrInvalidPassphrase = errors.New("invalid passphrase for credential store")
+
+type encryptedFile struct {
+	Version    int    `json:"version"`
+	Mode       string `json:"mode"`
+	KDF        string `json:"kdf"`
+	Iterations int    `json:"iterations"`
+	Salt       []byte `json:"salt"`
+	Nonce      []byte `json:"nonce"`
+	Ciphertext []byte `json:"ciphertext"`
+}
+
+// EncryptedFileStore keeps tokens in an AES-256-GCM encrypted file. The key is derived
+// with PBKDF2-SHA256 from either a user passphrase or a per-machine secret.
+type EncryptedFileStore struct {
+	mu     sync.Mutex
+	path   string
+	mode   string
+	salt   []byte
+	key    []byte
+	tokens map[string]string
+}
+
+func NewMachineFileStore(path string) (*EncryptedFileStore, error) {
+	secret, err := machineSecret()
+	if err != nil {
+		return nil, fmt.Errorf("failed to derive machine key: %w", err)
+	}
+	return openFileStore(path, fileStoreModeMachine, secret)
+}
+
+func NewPassphraseFileStore(path, passphrase string) (*EncryptedFileStore, error) {
+	if passphrase == "" {
+		return nil, fmt.Errorf("passphrase cannot be empty")
+	}
+	return openFileStore(path, fileStoreModePassphrase, []byte(passphrase))
+}
+
+func openFileStore(path, mode string, secret []byte) (*EncryptedFileStore, error) {
+	store := &EncryptedFileStore{
+		path:   path,
+		mode:   mode,
+		tokens: make(map[string]string),
+	}
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		store.salt = make([]byte, 16)
+		if _, err := rand.Read(store.salt); err != nil {
+			return nil, err
+		}
+		store.key = deriveKey(secret, store.salt, fileStoreIterations)
+		return store, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read credential file: %w", err)
+	}
+
+	var file encryptedFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse credential file: %w", err)
+	}
+	if file.Version != fileStoreVersion {
+		return nil, fmt.Errorf("unsupported credential file version %d", file.Version)
+	}
+	if file.Mode != mode {
+		return nil, fmt.Errorf("credential file uses %s mode, not %s", file.Mode, mode)
+	}
+
+	store.salt = file.Salt
+	store.key = deriveKey(secret, file.Salt, file.Iterations)
+
+	plaintext, err := decryptGCM(store.key, file.Nonce, file.Ciphertext)
+	if err != nil {
+		if mode == fileStoreModePassphrase {
+			return nil, ErrInvalidPassphrase
+		}
+		return nil, fmt.Errorf("failed to decrypt credential file: %w", err)
+	}
+	if err := json.Unmarshal(plaintext, &store.tokens); err != nil {
+		return nil, fmt.Errorf("failed to parse decrypted credentials: %w", err)
+	}
+
+	return store, nil
+}
+
+func (s *EncryptedFileStore) Name() string {
+	if s.mode == fileStoreModePassphrase {
+		return CredentialBackendPassphrase
+	}
+	return CredentialBackendFile
+}
+
+func (s *EncryptedFileStore) Set(account, tokenType, token string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.tokens[credentialKey(account, tokenType)] = token
+	return s.saveInternal()
+}
+
+func (s *EncryptedFileStore) Get(account, tokenType string) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.tokens[credentialKey(account, tokenType)], nil
+}
+
+func (s *EncryptedFileStore) Delete(account, tokenType string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key := credentialKey(account, tokenType)
+	if _, ok := s.tokens[key]; !ok {
+		return nil
+	}
+	delete(s.tokens, key)
+	return s.saveInternal()
+}
+
+func (s *EncryptedFileStore) saveInternal() error {
+	plaintext, err := json.Marshal(s.tokens)
+	if err != nil {
+		return err
+	}
+
+	nonce, ciphertext, err := encryptGCM(s.key, plaintext)
+	if err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(encryptedFile{
+		Version:    fileStoreVersion,
+		Mode:       s.mode,
+		KDF:        "pbkdf2-sha256",
+		Iterations: fileStoreIterations,
+		Salt:       s.salt,
+		Nonce:      nonce,
+		Ciphertext: ciphertext,
+	}, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	dir := filepath.Dir(s.path)
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(dir, "credentials-*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Chmod(0600); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), s.path)
+}
+
+func encryptGCM(key, plaintext []byte) ([]byte, []byte, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, nil, err
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, nil, err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, nil, err
+	}
+	return nonce, gcm.Seal(nil, nonce, plaintext, nil), nil
+}
+
+func decryptGCM(key, nonce, ciphertext []byte) ([]byte, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	if len(nonce) != gcm.NonceSize() {
+		return nil, fmt.Errorf("invalid nonce length")
+	}
+	return gcm.Open(nil, nonce, ciphertext, nil)
+}
+
+// deriveKey stretches a passphrase or machine secret into an AES-256 key with PBKDF2-SHA256.
+func deriveKey(secret, salt []byte, iterations int) []byte {
+	return pbkdf2.Key(secret, salt, iterations, fileStoreKeyLen, sha256.New)
+}
@@ -0,0 +1,139 @@
+package auth
+
+import (
+	"bytes"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/zalando/go-keyring"
+)
+
+func useTestCredentialStore(t *testing.T) *EncryptedFileStore {
+	store, err := NewPassphraseFileStore(filepath.Join(t.TempDir(), "credentials.enc"), "test-passphrase")
+	if err != nil {
+		t.Fatalf("failed to open test credential store: %v", err)
+	}
+
+	previous := CurrentCredentialStore()
+	previousInfo := GetCredentialBackendInfo()
+	UseCredentialStore(store, CredentialBackendInfo{Active: CredentialBackendPassphrase, Encrypted: true})
+	t.Cleanup(func() { UseCredentialStore(previous, previousInfo) })
+	return store
+}
+
+func TestDeriveKey(t *testing.T) {
+	// RFC 7914 section 11 test vector, truncated to the AES-256 key length. Matching it keeps
+	// files written by earlier versions readable.
+	got := deriveKey([]byte("passwd"), []byte("salt"), 1)
+	want := "55ac046e56e3089fec1691c22544b605f94185216dde0465e68b9d57c20dacbc"
+	if hex.EncodeToString(got) != want {
+		t.Errorf("pbkdf2 mismatch: got %x", got)
+	}
+}
+
+func TestEncryptedFileStore_RoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "credentials.enc")
+
+	store, err := NewPassphraseFileStore(path, "correct horse")
+	if err != nil {
+		t.Fatalf("failed to create store: %v", err)
+	}
+	if err := store.Set("uuid-1", "AccessToken", "super-secret-token"); err != nil {
+		t.Fatalf("Set failed: %v", err)
+	}
+
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("credential file not written: %v", err)
+	}
+	if bytes.Contains(raw, []byte("super-secret-token")) {
+		t.Fatal("token stored in plaintext")
+	}
+
+	reopened, err := NewPassphraseFileStore(path, "correct horse")
+	if err != nil {
+		t.Fatalf("failed to reopen store: %v", err)
+	}
+	token, _ := reopened.Get("uuid-1", "AccessToken")
+	if token != "super-secret-token" {
+		t.Errorf("expected token to survive reopen, got %q", token)
+	}
+
+	if _, err := NewPassphraseFileStore(path, "wrong"); err != ErrInvalidPassphrase {
+		t.Errorf("expected ErrInvalidPassphrase, got %v", err)
+	}
+}
+
+func TestSwitchCredentialStore_MigratesTokens(t *testing.T) {
+	source := useTestCredentialStore(t)
+
+	manager := &AccountManager{
+		filePath: filepath.Join(t.TempDir(), "accounts.json"),
+		Data: AccountData{Accounts: []Account{{
+			UUID:        "3f1d6a2b8c9e4f0a1b2c3d4e5f6a7b8c",
+			Username:    "Migrated",
+			Type:        AccountTypeElyBy,
+			AccessToken: "access",
+			ClientToken: "client",
+		}}},
+	}
+	if err := manager.saveInternal(); err != nil {
+		t.Fatalf("initial save failed: %v", err)
+	}
+
+	target, err := NewMachineFileStore(filepath.Join(t.TempDir(), "machine.enc"))
+	if err != nil {
+		t.Skipf("machine key unavailable: %v", err)
+	}
+	if err := manager.SwitchCredentialStore(target, CredentialBackendInfo{Active: CredentialBackendFile}); err != nil {
+		t.Fatalf("SwitchCredentialStore failed: %v", err)
+	}
+
+	if token, _ := target.Get("3f1d6a2b8c9e4f0a1b2c3d4e5f6a7b8c", "AccessToken"); token != "access" {
+		t.Errorf("token not migrated, got %q", token)
+	}
+	if token, _ := source.Get("3f1d6a2b8c9e4f0a1b2c3d4e5f6a7b8c", "AccessToken"); token != "" {
+		t.Errorf("token not removed from previous backend, got %q", token)
+	}
+	if GetCredentialBackendInfo().Active != CredentialBackendFile {
+		t.Errorf("active backend not updated")
+	}
+}
+
+func TestOpenCredentialStore_AutoReadsFileFallback(t *testing.T) {
+	t.Setenv("NEZORD_DATA_DIR", t.TempDir())
+
+	// A previous start fell back to the file store while the keyring was unreachable.
+	fileStore, err := NewMachineFileStore(GetCredentialsFilePath(CredentialBackendFile))
+	if err != nil {
+		t.Skipf("machine key unavailable: %v", err)
+	}
+	if err := fileStore.Set("3f1d6a2b8c9e4f0a1b2c3d4e5f6a7b8c", "AccessToken", "access"); err != nil {
+		t.Fatal(err)
+	}
+
+	keyring.MockInit()
+	store, info, err := OpenCredentialStore(CredentialBackendAuto, "")
+	if err != nil {
+		t.Fatalf("OpenCredentialStore failed: %v", err)
+	}
+	if info.Active != CredentialBackendKeyring {
+		t.Fatalf("expected keyring backend, got %s", info.Active)
+	}
+
+	if token, err := store.Get("3f1d6a2b8c9e4f0a1b2c3d4e5f6a7b8c", "AccessToken"); err != nil || token != "access" {
+		t.Fatalf("token from the fallback file not found: %q (%v)", token, err)
+	}
+	if token, _ := (&KeyringStore{}).Get("3f1d6a2b8c9e4f0a1b2c3d4e5f6a7b8c", "AccessToken"); token != "access" {
+		t.Errorf("token not migrated into the keyring, got %q", token)
+	}
+	reopened, err := NewMachineFileStore(GetCredentialsFilePath(CredentialBackendFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if token, _ := reopened.Get("3f1d6a2b8c9e4f0a1b2c3d4e5f6a7b8c", "AccessToken"); token != "" {
+		t.Errorf("migrated token should leave the file store, got %q", token)
+	}
+}
@@ -9,20 +9,26 @@ import (
 
 const (
 	serviceName = "NezordLauncher"
+	probeKey    = "nezord-probe"
 )
 
-func SetSecureToken(username, tokenType, token string) error {
-	key := fmt.Sprintf("%s:%s", username, tokenType)
-	err := keyring.Set(serviceName, key, token)
+// KeyringStore keeps tokens in the OS keyring (Secret Service, Keychain, Credential Manager).
+type KeyringStore struct{}
+
+func (s *KeyringStore) Name() string {
+	return CredentialBackendKeyring
+}
+
+func (s *KeyringStore) Set(account, tokenType, token string) error {
+	err := keyring.Set(serviceName, credentialKey(account, tokenType), token)
 	if err != nil {
 		return fmt.Errorf("failed to set secure token: %w", err)
 	}
 	return nil
 }
 
-func GetSecureToken(username, tokenType string) (string, error) {
-	key := fmt.Sprintf("%s:%s", username, tokenType)
-	token, err := keyring.Get(serviceName, key)
+func (s *KeyringStore) Get(account, tokenType string) (string, error) {
+	token, err := keyring.Get(serviceName, credentialKey(account, tokenType))
 	if errors.Is(err, keyring.ErrNotFound) {
 		return "", nil
 	}
@@ -32,9 +38,8 @@ func GetSecureToken(username, tokenType string) (string, error) {
 	return token, nil
 }
 
-func DeleteSecureToken(username, tokenType string) error {
-	key := fmt.Sprintf("%s:%s", username, tokenType)
-	err := keyring.Delete(serviceName, key)
+func (s *KeyringStore) Delete(account, tokenType string) error {
+	err := keyring.Delete(serviceName, credentialKey(account, tokenType))
 	if errors.Is(err, keyring.ErrNotFound) {
 		return nil
 	}
@@ -43,3 +48,23 @@ func DeleteSecureToken(username, tokenType string) error {
 	}
 	return nil
 }
+
+// Probe round-trips a throwaway secret to check that a keyring daemon is actually reachable.
+func (s *KeyringStore) Probe() error {
+	if err := keyring.Set(serviceName, probeKey, "ok"); err != nil {
+		return err
+	}
+	value, err := keyring.Get(serviceName, probeKey)
+	_ = keyring.Delete(serviceName, probeKey)
+	if err != nil {
+		return err
+	}
+	if value != "ok" {
+		return fmt.Errorf("keyring returned unexpected probe value")
+	}
+	return nil
+}
+
+func credentialKey(account, tokenType string) string {
+	return fmt.Sprintf("%s:%s", account, tokenType)
+}
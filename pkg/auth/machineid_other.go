@@ -0,0 +1,53 @@
+//go:build !windows
+
+package auth
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"os"
+	"os/exec"
+	"os/user"
+	"regexp"
+	"runtime"
+	"strings"
+)
+
+var ioPlatformUUIDRegex = regexp.MustCompile(`"IOPlatformUUID" = "([^"]+)"`)
+
+func machineSecret() ([]byte, error) {
+	id := readMachineID()
+	if id == "" {
+		return nil, fmt.Errorf("no machine identifier available")
+	}
+
+	username := ""
+	if u, err := user.Current(); err == nil {
+		username = u.Username
+	}
+
+	sum := sha256.Sum256([]byte(fmt.Sprintf("%s:%s:%s", serviceName, id, username)))
+	return sum[:], nil
+}
+
+func readMachineID() string {
+	if runtime.GOOS == "darwin" {
+		out, err := exec.Command("ioreg", "-rd1", "-c", "IOPlatformExpertDevice").Output()
+		if err == nil {
+			if m := ioPlatformUUIDRegex.FindSubmatch(out); len(m) == 2 {
+				return string(m[1])
+			}
+		}
+	}
+
+	for _, path := range []string{"/etc/machine-id", "/var/lib/dbus/machine-id"} {
+		if data, err := os.ReadFile(path); err == nil {
+			if id := strings.TrimSpace(string(data)); id != "" {
+				return id
+			}
+		}
+	}
+
+	hostname, _ := os.Hostname()
+	return hostname
+}
@@ -0,0 +1,30 @@
+package auth
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"os/user"
+
+	"golang.org/x/sys/windows/registry"
+)
+
+func machineSecret() ([]byte, error) {
+	k, err := registry.OpenKey(registry.LOCAL_MACHINE, `SOFTWARE\Microsoft\Cryptography`, registry.QUERY_VALUE|registry.WOW64_64KEY)
+	if err != nil {
+		return nil, err
+	}
+	defer k.Close()
+
+	guid, _, err := k.GetStringValue("MachineGuid")
+	if err != nil {
+		return nil, err
+	}
+
+	username := ""
+	if u, err := user.Current(); err == nil {
+		username = u.Username
+	}
+
+	sum := sha256.Sum256([]byte(fmt.Sprintf("%s:%s:%s", serviceName, guid, username)))
+	return sum[:], nil
+}
@@ -2,11 +2,13 @@ package auth
 
 import (
 	"NezordLauncher/pkg/constants"
+	"context"
 	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
 	"sync"
+	"time"
 )
 
 type AccountType string
@@ -15,26 +17,35 @@ const (
 	AccountTypeOffline   AccountType = "offline"
 	AccountTypeElyBy     AccountType = "elyby"
 	AccountTypeMicrosoft AccountType = "microsoft"
+	AccountTypeYggdrasil AccountType = "yggdrasil"
 )
 
 type Account struct {
-	UUID           string        `json:"uuid"`
-	Username       string        `json:"username"`
-	Type           AccountType   `json:"type"`
-	AccessToken    string        `json:"-"`
-	ClientToken    string        `json:"-"`
-	UserProperties []interface{} `json:"userProperties,omitempty"`
+	UUID           string           `json:"uuid"`
+	Username       string           `json:"username"`
+	Type           AccountType      `json:"type"`
+	AccessToken    string           `json:"-"`
+	ClientToken    string           `json:"-"`
+	RefreshToken   string           `json:"-"`
+	ExpiresAt      time.Time        `json:"expiresAt,omitempty"`
+	NeedsRelogin   bool             `json:"needsRelogin,omitempty"`
+	Server         *YggdrasilServer `json:"server,omitempty"`
+	LocalSkinID    string           `json:"localSkinId,omitempty"`
+	UserProperties []interface{}    `json:"userProperties,omitempty"`
 }
 
 type AccountData struct {
 	Accounts   []Account `json:"accounts"`
 	ActiveUUID string    `json:"activeUUID"`
+	// ProfileChoices maps a login (type, server and username) to the profile picked for it.
+	ProfileChoices map[string]string `json:"profileChoices,omitempty"`
 }
 
 type AccountManager struct {
 	mu       sync.RWMutex
 	filePath string
 	Data     AccountData
+	pending  map[string]*PendingLogin
 }
 
 func NewAccountManager() *AccountManager {
@@ -84,6 +95,9 @@ func (m *AccountManager) Load() error {
 			}
 			acc.AccessToken = at
 			acc.ClientToken = ct
+			if acc.Type == AccountTypeMicrosoft {
+				acc.RefreshToken, _ = GetSecureToken(key, "RefreshToken")
+			}
 		}
 	}
 
@@ -96,11 +110,12 @@ func (m *AccountManager) saveInternal() error {
 		return fmt.Errorf("failed to create config directory: %w", err)
 	}
 
+	var tokenErr error
 	for _, acc := range m.Data.Accounts {
 		if acc.Type != AccountTypeOffline && acc.AccessToken != "" {
-			key := tokenKey(acc)
-			_ = SetSecureToken(key, "AccessToken", acc.AccessToken)
-			_ = SetSecureToken(key, "ClientToken", acc.ClientToken)
+			if err := storeTokens(CurrentCredentialStore(), acc); err != nil && tokenErr == nil {
+				tokenErr = fmt.Errorf("failed to store credentials for %s: %w", acc.Username, err)
+			}
 		}
 	}
 
@@ -108,7 +123,64 @@ func (m *AccountManager) saveInternal() error {
 	if err != nil {
 		return fmt.Errorf("failed to marshal accounts: %w", err)
 	}
-	return os.WriteFile(m.filePath, data, 0644)
+	if err := os.WriteFile(m.filePath, data, 0644); err != nil {
+		return err
+	}
+	return tokenErr
+}
+
+// SwitchCredentialStore moves every stored token into the given backend and makes it active.
+func (m *AccountManager) SwitchCredentialStore(store CredentialStore, info CredentialBackendInfo) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	previous := CurrentCredentialStore()
+	previousInfo := GetCredentialBackendInfo()
+
+	for _, acc := range m.Data.Accounts {
+		if acc.Type != AccountTypeOffline && acc.AccessToken != "" {
+			if err := storeTokens(store, acc); err != nil {
+				return fmt.Errorf("failed to migrate credentials for %s: %w", acc.Username, err)
+			}
+		}
+	}
+
+	UseCredentialStore(store, info)
+	if err := m.saveInternal(); err != nil {
+		UseCredentialStore(previous, previousInfo)
+		return err
+	}
+
+	if previous.Name() != store.Name() {
+		for _, acc := range m.Data.Accounts {
+			if acc.Type != AccountTypeOffline {
+				deleteTokens(previous, tokenKey(acc))
+			}
+		}
+	}
+	return nil
+}
+
+func storeTokens(store CredentialStore, acc Account) error {
+	key := tokenKey(acc)
+	if err := store.Set(key, "AccessToken", acc.AccessToken); err != nil {
+		return err
+	}
+	if err := store.Set(key, "ClientToken", acc.ClientToken); err != nil {
+		return err
+	}
+	if acc.RefreshToken != "" {
+		if err := store.Set(key, "RefreshToken", acc.RefreshToken); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func deleteTokens(store CredentialStore, key string) {
+	_ = store.Delete(key, "AccessToken")
+	_ = store.Delete(key, "ClientToken")
+	_ = store.Delete(key, "RefreshToken")
 }
 
 func (m *AccountManager) AddOfflineAccount(username string) (*Account, error) {
@@ -191,6 +263,23 @@ func (m *AccountManager) SetActiveAccount(uuid string) error {
 	return m.saveInternal()
 }
 
+// SetLocalSkin assigns a skin library entry to an offline account; an empty id clears it.
+func (m *AccountManager) SetLocalSkin(uuid, skinID string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for i, acc := range m.Data.Accounts {
+		if acc.UUID == uuid {
+			if acc.Type != AccountTypeOffline {
+				return fmt.Errorf("local skins are only available for offline accounts")
+			}
+			m.Data.Accounts[i].LocalSkinID = skinID
+			return m.saveInternal()
+		}
+	}
+	return fmt.Errorf("account with uuid %s not found", uuid)
+}
+
 func (m *AccountManager) GetAccounts() []Account {
 	m.mu.RLock()
 	defer m.mu.RUnlock()
@@ -201,11 +290,28 @@ func (m *AccountManager) GetAccounts() []Account {
 }
 
 func (m *AccountManager) AddElyByAccount(username, password string) (*Account, error) {
-	resp, err := AuthenticateElyBy(username, password)
+	result, err := m.BeginElyByLogin(username, password, true)
 	if err != nil {
 		return nil, err
 	}
+	if result.Pending != nil {
+		return nil, ErrProfileSelectionRequired
+	}
+	return result.Account, nil
+}
+
+func (m *AccountManager) AddYggdrasilAccount(serverURL, username, password string) (*Account, error) {
+	result, err := m.BeginYggdrasilLogin(serverURL, username, password, true)
+	if err != nil {
+		return nil, err
+	}
+	if result.Pending != nil {
+		return nil, ErrProfileSelectionRequired
+	}
+	return result.Account, nil
+}
 
+func (m *AccountManager) storeYggdrasilAccount(accType AccountType, server *YggdrasilServer, resp *AuthResponse) (*Account, error) {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
@@ -217,7 +323,9 @@ func (m *AccountManager) AddElyByAccount(username, password string) (*Account, e
 			m.Data.Accounts[i].Username = displayName
 			m.Data.Accounts[i].AccessToken = resp.AccessToken
 			m.Data.Accounts[i].ClientToken = resp.ClientToken
-			m.Data.Accounts[i].Type = AccountTypeElyBy
+			m.Data.Accounts[i].Type = accType
+			m.Data.Accounts[i].Server = server
+			m.Data.Accounts[i].NeedsRelogin = false
 
 			m.Data.ActiveUUID = uuid
 			m.saveInternal()
@@ -230,9 +338,58 @@ func (m *AccountManager) AddElyByAccount(username, password string) (*Account, e
 	newAcc := Account{
 		UUID:        uuid,
 		Username:    displayName,
-		Type:        AccountTypeElyBy,
+		Type:        accType,
 		AccessToken: resp.AccessToken,
 		ClientToken: resp.ClientToken,
+		Server:      server,
+	}
+
+	m.Data.Accounts = append(m.Data.Accounts, newAcc)
+	m.Data.ActiveUUID = uuid
+
+	if err := m.saveInternal(); err != nil {
+		return nil, err
+	}
+
+	return &newAcc, nil
+}
+
+func (m *AccountManager) AddMicrosoftAccount(ctx context.Context, cb MicrosoftLoginCallbacks) (*Account, error) {
+	session, err := AuthenticateMicrosoft(ctx, cb)
+	if err != nil {
+		return nil, err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	uuid := session.Profile.ID
+
+	for i, acc := range m.Data.Accounts {
+		if acc.UUID == uuid {
+			m.Data.Accounts[i].Username = session.Profile.Name
+			m.Data.Accounts[i].Type = AccountTypeMicrosoft
+			m.Data.Accounts[i].AccessToken = session.AccessToken
+			m.Data.Accounts[i].ClientToken = ""
+			m.Data.Accounts[i].RefreshToken = session.RefreshToken
+			m.Data.Accounts[i].ExpiresAt = session.ExpiresAt
+			m.Data.Accounts[i].NeedsRelogin = false
+
+			m.Data.ActiveUUID = uuid
+			m.saveInternal()
+
+			accCopy := m.Data.Accounts[i]
+			return &accCopy, nil
+		}
+	}
+
+	newAcc := Account{
+		UUID:         uuid,
+		Username:     session.Profile.Name,
+		Type:         AccountTypeMicrosoft,
+		AccessToken:  session.AccessToken,
+		RefreshToken: session.RefreshToken,
+		ExpiresAt:    session.ExpiresAt,
 	}
 
 	m.Data.Accounts = append(m.Data.Accounts, newAcc)
@@ -246,6 +403,53 @@ func (m *AccountManager) AddElyByAccount(username, password string) (*Account, e
 }
 
 func (m *AccountManager) RemoveAccount(uuid string) error {
+	m.mu.RLock()
+	var target *Account
+	for _, acc := range m.Data.Accounts {
+		if acc.UUID == uuid {
+			accCopy := acc
+			target = &accCopy
+			break
+		}
+	}
+	m.mu.RUnlock()
+
+	if target != nil && target.AccessToken != "" {
+		if client := yggdrasilClientFor(*target); client != nil {
+			_ = client.Invalidate(target.AccessToken, target.ClientToken)
+		}
+	}
+
+	return m.removeAccount(uuid)
+}
+
+// SignOutAccount invalidates every session of the account on its auth server, then removes it.
+func (m *AccountManager) SignOutAccount(uuid, password string) error {
+	m.mu.RLock()
+	var target *Account
+	for _, acc := range m.Data.Accounts {
+		if acc.UUID == uuid {
+			accCopy := acc
+			target = &accCopy
+			break
+		}
+	}
+	m.mu.RUnlock()
+
+	if target == nil {
+		return fmt.Errorf("account not found")
+	}
+
+	if client := yggdrasilClientFor(*target); client != nil {
+		if err := client.Signout(target.Username, password); err != nil {
+			return fmt.Errorf("signout failed: %w", err)
+		}
+	}
+
+	return m.removeAccount(uuid)
+}
+
+func (m *AccountManager) removeAccount(uuid string) error {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
@@ -273,8 +477,7 @@ func (m *AccountManager) RemoveAccount(uuid string) error {
 	}
 
 	if targetKey != "" {
-		_ = DeleteSecureToken(targetKey, "AccessToken")
-		_ = DeleteSecureToken(targetKey, "ClientToken")
+		deleteTokens(CurrentCredentialStore(), targetKey)
 	}
 	if targetUsername != "" && targetUsername != targetKey {
 		_ = DeleteSecureToken(targetUsername, "AccessToken")
@@ -284,6 +487,18 @@ func (m *AccountManager) RemoveAccount(uuid string) error {
 	return m.saveInternal()
 }
 
+func yggdrasilClientFor(acc Account) *YggdrasilClient {
+	switch acc.Type {
+	case AccountTypeElyBy:
+		return NewElyByClient()
+	case AccountTypeYggdrasil:
+		if acc.Server != nil {
+			return NewYggdrasilClient(acc.Server.AuthServerURL())
+		}
+	}
+	return nil
+}
+
 func tokenKey(acc Account) string {
 	if acc.UUID != "" {
 		return acc.UUID
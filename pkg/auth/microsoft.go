@@ -0,0 +1,513 @@
+package auth
+
+import (
+	"NezordLauncher/pkg/network"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+)
+
+const (
+	MicrosoftDeviceCodeURL   = "https://login.microsoftonline.com/consumers/oauth2/v2.0/devicecode"
+	MicrosoftTokenURL        = "https://login.microsoftonline.com/consumers/oauth2/v2.0/token"
+	XboxLiveAuthURL          = "https://user.auth.xboxlive.com/user/authenticate"
+	XSTSAuthURL              = "https://xsts.auth.xboxlive.com/xsts/authorize"
+	MinecraftLoginURL        = "https://api.minecraftservices.com/authentication/login_with_xbox"
+	MinecraftEntitlementsURL = "https://api.minecraftservices.com/entitlements/mcstore"
+	MinecraftProfileURL      = "https://api.minecraftservices.com/minecraft/profile"
+
+	microsoftScope = "XboxLive.signin offline_access"
+)
+
+// MicrosoftClientID is the Azure application ID used for the device-code flow.
+// Release builds set it with -ldflags "-X NezordLauncher/pkg/auth.MicrosoftClientID=...".
+var MicrosoftClientID = ""
+
+var (
+	ErrMicrosoftLoginExpired  = errors.New("device code expired before login was completed")
+	ErrMicrosoftLoginDeclined = errors.New("login was declined by the user")
+	ErrNoXboxAccount          = errors.New("this Microsoft account has no Xbox profile")
+	ErrXboxChildAccount       = errors.New("this Microsoft account is a child account and must be added to a family")
+	ErrNoMinecraftOwnership   = errors.New("this Microsoft account does not own Minecraft")
+	ErrNoMinecraftProfile     = errors.New("this Microsoft account has no Minecraft profile yet")
+)
+
+type DeviceCodeResponse struct {
+	UserCode        string `json:"user_code"`
+	DeviceCode      string `json:"device_code"`
+	VerificationURI string `json:"verification_uri"`
+	ExpiresIn       int    `json:"expires_in"`
+	Interval        int    `json:"interval"`
+	Message         string `json:"message"`
+}
+
+type MicrosoftToken struct {
+	AccessToken  string `json:"access_token"`
+	RefreshToken string `json:"refresh_token"`
+	ExpiresIn    int    `json:"expires_in"`
+	Error        string `json:"error"`
+	Description  string `json:"error_description"`
+}
+
+type xboxAuthResponse struct {
+	Token         string `json:"Token"`
+	DisplayClaims struct {
+		Xui []struct {
+			Uhs string `json:"uhs"`
+		} `json:"xui"`
+	} `json:"DisplayClaims"`
+}
+
+type xboxErrorResponse struct {
+	XErr    int64  `json:"XErr"`
+	Message string `json:"Message"`
+}
+
+type minecraftLoginResponse struct {
+	AccessToken string `json:"access_token"`
+	ExpiresIn   int    `json:"expires_in"`
+}
+
+type minecraftEntitlements struct {
+	Items []struct {
+		Name string `json:"name"`
+	} `json:"items"`
+}
+
+type MinecraftProfile struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+// MicrosoftSession is the result of a completed Microsoft → Minecraft login chain.
+type MicrosoftSession struct {
+	Profile      MinecraftProfile
+	AccessToken  string
+	RefreshToken string
+	ExpiresAt    time.Time
+}
+
+type MicrosoftLoginCallbacks struct {
+	OnDeviceCode func(DeviceCodeResponse)
+	OnStatus     func(message string)
+}
+
+func (cb MicrosoftLoginCallbacks) status(message string) {
+	if cb.OnStatus != nil {
+		cb.OnStatus(message)
+	}
+}
+
+func msEndpoint(envKey, fallback string) string {
+	if override := os.Getenv(envKey); override != "" {
+		return override
+	}
+	return fallback
+}
+
+func microsoftClientID() string {
+	if override := os.Getenv("NEZORD_MS_CLIENT_ID"); override != "" {
+		return override
+	}
+	return MicrosoftClientID
+}
+
+func AuthenticateMicrosoft(ctx context.Context, cb MicrosoftLoginCallbacks) (*MicrosoftSession, error) {
+	clientID := microsoftClientID()
+	if clientID == "" {
+		return nil, fmt.Errorf("microsoft client id is not configured")
+	}
+
+	client := network.NewHttpClient()
+
+	cb.status("Requesting device code...")
+	code, err := requestDeviceCode(ctx, client, clientID)
+	if err != nil {
+		return nil, err
+	}
+	if cb.OnDeviceCode != nil {
+		cb.OnDeviceCode(*code)
+	}
+
+	cb.status("Waiting for Microsoft login...")
+	token, err := pollDeviceToken(ctx, client, clientID, code)
+	if err != nil {
+		return nil, err
+	}
+
+	return completeMicrosoftLogin(ctx, client, token, cb)
+}
+
+func RefreshMicrosoft(ctx context.Context, refreshToken string) (*MicrosoftSession, error) {
+	clientID := microsoftClientID()
+	if clientID == "" {
+		return nil, fmt.Errorf("microsoft client id is not configured")
+	}
+
+	client := network.NewHttpClient()
+	form := url.Values{
+		"client_id":     {clientID},
+		"grant_type":    {"refresh_token"},
+		"refresh_token": {refreshToken},
+		"scope":         {microsoftScope},
+	}
+
+	data, status, err := msPostForm(ctx, client, msEndpoint("NEZORD_MS_TOKEN_URL", MicrosoftTokenURL), form)
+	if err != nil {
+		return nil, fmt.Errorf("microsoft token refresh failed: %w", err)
+	}
+
+	var token MicrosoftToken
+	parseErr := json.Unmarshal(data, &token)
+	if token.Error == "invalid_grant" || (status >= 400 && status < 500) {
+		return nil, &MicrosoftTokenRejectedError{Status: status, Reason: describeTokenError(token, status)}
+	}
+	if status != http.StatusOK {
+		return nil, fmt.Errorf("microsoft token refresh failed: status %d", status)
+	}
+	if parseErr != nil {
+		return nil, fmt.Errorf("failed to parse microsoft token response: %w", parseErr)
+	}
+	if token.AccessToken == "" {
+		return nil, fmt.Errorf("microsoft token refresh failed: %s", describeTokenError(token, status))
+	}
+
+	return completeMicrosoftLogin(ctx, client, &token, MicrosoftLoginCallbacks{})
+}
+
+// MicrosoftTokenRejectedError means the token endpoint refused the refresh token, so only a new
+// login can recover the account. Other refresh errors may be transient.
+type MicrosoftTokenRejectedError struct {
+	Status int
+	Reason string
+}
+
+func (e *MicrosoftTokenRejectedError) Error() string {
+	return fmt.Sprintf("microsoft token refresh rejected: %s", e.Reason)
+}
+
+func completeMicrosoftLogin(ctx context.Context, client *network.HttpClient, token *MicrosoftToken, cb MicrosoftLoginCallbacks) (*MicrosoftSession, error) {
+	cb.status("Authenticating with Xbox Live...")
+	xbl, err := authenticateXboxLive(ctx, client, token.AccessToken)
+	if err != nil {
+		return nil, err
+	}
+
+	cb.status("Requesting XSTS token...")
+	xsts, err := authorizeXSTS(ctx, client, xbl.Token)
+	if err != nil {
+		return nil, err
+	}
+	if len(xsts.DisplayClaims.Xui) == 0 {
+		return nil, fmt.Errorf("xsts response is missing the user hash")
+	}
+
+	cb.status("Logging in to Minecraft services...")
+	mc, err := loginMinecraft(ctx, client, xsts.DisplayClaims.Xui[0].Uhs, xsts.Token)
+	if err != nil {
+		return nil, err
+	}
+
+	cb.status("Checking game ownership...")
+	if err := checkEntitlements(ctx, client, mc.AccessToken); err != nil {
+		return nil, err
+	}
+
+	cb.status("Fetching Minecraft profile...")
+	profile, err := fetchMinecraftProfile(ctx, client, mc.AccessToken)
+	if err != nil {
+		return nil, err
+	}
+
+	return &MicrosoftSession{
+		Profile:      *profile,
+		AccessToken:  mc.AccessToken,
+		RefreshToken: token.RefreshToken,
+		ExpiresAt:    time.Now().Add(time.Duration(mc.ExpiresIn) * time.Second),
+	}, nil
+}
+
+func requestDeviceCode(ctx context.Context, client *network.HttpClient, clientID string) (*DeviceCodeResponse, error) {
+	form := url.Values{
+		"client_id": {clientID},
+		"scope":     {microsoftScope},
+	}
+
+	data, status, err := msPostForm(ctx, client, msEndpoint("NEZORD_MS_DEVICE_CODE_URL", MicrosoftDeviceCodeURL), form)
+	if err != nil {
+		return nil, fmt.Errorf("failed to request device code: %w", err)
+	}
+	if status != http.StatusOK {
+		return nil, fmt.Errorf("device code request failed with status %d: %s", status, string(data))
+	}
+
+	var code DeviceCodeResponse
+	if err := json.Unmarshal(data, &code); err != nil {
+		return nil, fmt.Errorf("failed to parse device code response: %w", err)
+	}
+	if code.DeviceCode == "" {
+		return nil, fmt.Errorf("device code response is missing the device code")
+	}
+	return &code, nil
+}
+
+func pollDeviceToken(ctx context.Context, client *network.HttpClient, clientID string, code *DeviceCodeResponse) (*MicrosoftToken, error) {
+	interval := time.Duration(code.Interval) * time.Second
+	if interval <= 0 {
+		interval = 5 * time.Second
+	}
+	expiresIn := time.Duration(code.ExpiresIn) * time.Second
+	if expiresIn <= 0 {
+		expiresIn = 15 * time.Minute
+	}
+	deadline := time.Now().Add(expiresIn)
+
+	form := url.Values{
+		"client_id":   {clientID},
+		"grant_type":  {"urn:ietf:params:oauth:grant-type:device_code"},
+		"device_code": {code.DeviceCode},
+	}
+	tokenURL := msEndpoint("NEZORD_MS_TOKEN_URL", MicrosoftTokenURL)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(interval):
+		}
+
+		if time.Now().After(deadline) {
+			return nil, ErrMicrosoftLoginExpired
+		}
+
+		data, status, err := msPostForm(ctx, client, tokenURL, form)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
+			return nil, fmt.Errorf("failed to poll microsoft token: %w", err)
+		}
+
+		var token MicrosoftToken
+		if err := json.Unmarshal(data, &token); err != nil {
+			return nil, fmt.Errorf("failed to parse microsoft token response: %w", err)
+		}
+
+		if status == http.StatusOK && token.AccessToken != "" {
+			return &token, nil
+		}
+
+		switch token.Error {
+		case "authorization_pending":
+			continue
+		case "slow_down":
+			interval += 5 * time.Second
+			continue
+		case "expired_token":
+			return nil, ErrMicrosoftLoginExpired
+		case "authorization_declined", "access_denied":
+			return nil, ErrMicrosoftLoginDeclined
+		default:
+			return nil, fmt.Errorf("microsoft login failed: %s", describeTokenError(token, status))
+		}
+	}
+}
+
+func authenticateXboxLive(ctx context.Context, client *network.HttpClient, msAccessToken string) (*xboxAuthResponse, error) {
+	payload := map[string]interface{}{
+		"Properties": map[string]interface{}{
+			"AuthMethod": "RPS",
+			"SiteName":   "user.auth.xboxlive.com",
+			"RpsTicket":  "d=" + msAccessToken,
+		},
+		"RelyingParty": "http://auth.xboxlive.com",
+		"TokenType":    "JWT",
+	}
+
+	data, status, err := msPostJSON(ctx, client, msEndpoint("NEZORD_XBL_AUTH_URL", XboxLiveAuthURL), payload, "")
+	if err != nil {
+		return nil, fmt.Errorf("xbox live authentication failed: %w", err)
+	}
+	if status != http.StatusOK {
+		return nil, fmt.Errorf("xbox live authentication failed with status %d", status)
+	}
+
+	var resp xboxAuthResponse
+	if err := json.Unmarshal(data, &resp); err != nil {
+		return nil, fmt.Errorf("failed to parse xbox live response: %w", err)
+	}
+	return &resp, nil
+}
+
+func authorizeXSTS(ctx context.Context, client *network.HttpClient, xblToken string) (*xboxAuthResponse, error) {
+	payload := map[string]interface{}{
+		"Properties": map[string]interface{}{
+			"SandboxId":  "RETAIL",
+			"UserTokens": []string{xblToken},
+		},
+		"RelyingParty": "rp://api.minecraftservices.com/",
+		"TokenType":    "JWT",
+	}
+
+	data, status, err := msPostJSON(ctx, client, msEndpoint("NEZORD_XSTS_AUTH_URL", XSTSAuthURL), payload, "")
+	if err != nil {
+		return nil, fmt.Errorf("xsts authorization failed: %w", err)
+	}
+	if status == http.StatusUnauthorized {
+		var xerr xboxErrorResponse
+		_ = json.Unmarshal(data, &xerr)
+		switch xerr.XErr {
+		case 2148916233:
+			return nil, ErrNoXboxAccount
+		case 2148916235:
+			return nil, fmt.Errorf("xbox live is not available in this account's country")
+		case 2148916236, 2148916237:
+			return nil, fmt.Errorf("this account requires adult verification on the xbox page")
+		case 2148916238:
+			return nil, ErrXboxChildAccount
+		}
+		return nil, fmt.Errorf("xsts authorization denied (XErr %d)", xerr.XErr)
+	}
+	if status != http.StatusOK {
+		return nil, fmt.Errorf("xsts authorization failed with status %d", status)
+	}
+
+	var resp xboxAuthResponse
+	if err := json.Unmarshal(data, &resp); err != nil {
+		return nil, fmt.Errorf("failed to parse xsts response: %w", err)
+	}
+	return &resp, nil
+}
+
+func loginMinecraft(ctx context.Context, client *network.HttpClient, userHash, xstsToken string) (*minecraftLoginResponse, error) {
+	payload := map[string]string{
+		"identityToken": fmt.Sprintf("XBL3.0 x=%s;%s", userHash, xstsToken),
+	}
+
+	data, status, err := msPostJSON(ctx, client, msEndpoint("NEZORD_MC_LOGIN_URL", MinecraftLoginURL), payload, "")
+	if err != nil {
+		return nil, fmt.Errorf("minecraft login failed: %w", err)
+	}
+	if status != http.StatusOK {
+		return nil, fmt.Errorf("minecraft login failed with status %d", status)
+	}
+
+	var resp minecraftLoginResponse
+	if err := json.Unmarshal(data, &resp); err != nil {
+		return nil, fmt.Errorf("failed to parse minecraft login response: %w", err)
+	}
+	if resp.AccessToken == "" {
+		return nil, fmt.Errorf("minecraft login response is missing the access token")
+	}
+	return &resp, nil
+}
+
+func checkEntitlements(ctx context.Context, client *network.HttpClient, mcAccessToken string) error {
+	data, status, err := msGet(ctx, client, msEndpoint("NEZORD_MC_ENTITLEMENTS_URL", MinecraftEntitlementsURL), mcAccessToken)
+	if err != nil {
+		return fmt.Errorf("failed to fetch entitlements: %w", err)
+	}
+	if status != http.StatusOK {
+		return fmt.Errorf("entitlements request failed with status %d", status)
+	}
+
+	var ent minecraftEntitlements
+	if err := json.Unmarshal(data, &ent); err != nil {
+		return fmt.Errorf("failed to parse entitlements: %w", err)
+	}
+	if len(ent.Items) == 0 {
+		return ErrNoMinecraftOwnership
+	}
+	return nil
+}
+
+func fetchMinecraftProfile(ctx context.Context, client *network.HttpClient, mcAccessToken string) (*MinecraftProfile, error) {
+	data, status, err := msGet(ctx, client, msEndpoint("NEZORD_MC_PROFILE_URL", MinecraftProfileURL), mcAccessToken)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch minecraft profile: %w", err)
+	}
+	if status == http.StatusNotFound {
+		return nil, ErrNoMinecraftProfile
+	}
+	if status != http.StatusOK {
+		return nil, fmt.Errorf("profile request failed with status %d", status)
+	}
+
+	var profile MinecraftProfile
+	if err := json.Unmarshal(data, &profile); err != nil {
+		return nil, fmt.Errorf("failed to parse minecraft profile: %w", err)
+	}
+	if profile.ID == "" {
+		return nil, ErrNoMinecraftProfile
+	}
+	return &profile, nil
+}
+
+func describeTokenError(token MicrosoftToken, status int) string {
+	if token.Description != "" {
+		return token.Description
+	}
+	if token.Error != "" {
+		return token.Error
+	}
+	return fmt.Sprintf("status %d", status)
+}
+
+func msPostForm(ctx context.Context, client *network.HttpClient, endpoint string, form url.Values) ([]byte, int, error) {
+	req, err := http.NewRequestWithContext(ctx, "POST", endpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, 0, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	return msDo(client, req)
+}
+
+func msPostJSON(ctx context.Context, client *network.HttpClient, endpoint string, payload interface{}, bearer string) ([]byte, int, error) {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return nil, 0, err
+	}
+	req, err := http.NewRequestWithContext(ctx, "POST", endpoint, bytes.NewReader(body))
+	if err != nil {
+		return nil, 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if bearer != "" {
+		req.Header.Set("Authorization", "Bearer "+bearer)
+	}
+	return msDo(client, req)
+}
+
+func msGet(ctx context.Context, client *network.HttpClient, endpoint, bearer string) ([]byte, int, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", endpoint, nil)
+	if err != nil {
+		return nil, 0, err
+	}
+	if bearer != "" {
+		req.Header.Set("Authorization", "Bearer "+bearer)
+	}
+	return msDo(client, req)
+}
+
+func msDo(client *network.HttpClient, req *http.Request) ([]byte, int, error) {
+	req.Header.Set("Accept", "application/json")
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, resp.StatusCode, err
+	}
+	return data, resp.StatusCode, nil
+}
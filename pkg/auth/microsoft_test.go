@@ -0,0 +1,126 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+)
+
+func setMicrosoftEndpoints(t *testing.T, base string) {
+	envs := map[string]string{
+		"NEZORD_MS_CLIENT_ID":        "test-client",
+		"NEZORD_MS_DEVICE_CODE_URL":  base + "/devicecode",
+		"NEZORD_MS_TOKEN_URL":        base + "/token",
+		"NEZORD_XBL_AUTH_URL":        base + "/xbl",
+		"NEZORD_XSTS_AUTH_URL":       base + "/xsts",
+		"NEZORD_MC_LOGIN_URL":        base + "/mc/login",
+		"NEZORD_MC_ENTITLEMENTS_URL": base + "/mc/entitlements",
+		"NEZORD_MC_PROFILE_URL":      base + "/mc/profile",
+	}
+	for k, v := range envs {
+		original := os.Getenv(k)
+		os.Setenv(k, v)
+		t.Cleanup(func() { os.Setenv(k, original) })
+	}
+}
+
+func newMicrosoftStub(t *testing.T, owned bool) *httptest.Server {
+	polls := 0
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/devicecode":
+			json.NewEncoder(w).Encode(DeviceCodeResponse{
+				UserCode:        "ABCD-EFGH",
+				DeviceCode:      "device-123",
+				VerificationURI: "https://microsoft.com/link",
+				ExpiresIn:       60,
+				Interval:        1,
+			})
+		case "/token":
+			r.ParseForm()
+			if r.Form.Get("device_code") != "device-123" {
+				t.Errorf("unexpected device code %q", r.Form.Get("device_code"))
+			}
+			polls++
+			if polls == 1 {
+				w.WriteHeader(http.StatusBadRequest)
+				json.NewEncoder(w).Encode(map[string]string{"error": "authorization_pending"})
+				return
+			}
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"access_token":  "ms-access",
+				"refresh_token": "ms-refresh",
+				"expires_in":    3600,
+			})
+		case "/xbl":
+			var body map[string]interface{}
+			json.NewDecoder(r.Body).Decode(&body)
+			props := body["Properties"].(map[string]interface{})
+			if props["RpsTicket"] != "d=ms-access" {
+				t.Errorf("unexpected RpsTicket %v", props["RpsTicket"])
+			}
+			w.Write([]byte(`{"Token":"xbl-token","DisplayClaims":{"xui":[{"uhs":"hash"}]}}`))
+		case "/xsts":
+			w.Write([]byte(`{"Token":"xsts-token","DisplayClaims":{"xui":[{"uhs":"hash"}]}}`))
+		case "/mc/login":
+			var body map[string]string
+			json.NewDecoder(r.Body).Decode(&body)
+			if body["identityToken"] != "XBL3.0 x=hash;xsts-token" {
+				t.Errorf("unexpected identity token %q", body["identityToken"])
+			}
+			w.Write([]byte(`{"access_token":"mc-access","expires_in":86400}`))
+		case "/mc/entitlements":
+			if r.Header.Get("Authorization") != "Bearer mc-access" {
+				t.Errorf("missing bearer token on entitlements")
+			}
+			if owned {
+				w.Write([]byte(`{"items":[{"name":"game_minecraft"}]}`))
+			} else {
+				w.Write([]byte(`{"items":[]}`))
+			}
+		case "/mc/profile":
+			w.Write([]byte(`{"id":"069a79f444e94726a5befca90e38aaf5","name":"Notch"}`))
+		default:
+			t.Errorf("unexpected path %s", r.URL.Path)
+			http.NotFound(w, r)
+		}
+	}))
+}
+
+func TestAuthenticateMicrosoft_Stub(t *testing.T) {
+	server := newMicrosoftStub(t, true)
+	defer server.Close()
+	setMicrosoftEndpoints(t, server.URL)
+
+	var userCode string
+	session, err := AuthenticateMicrosoft(context.Background(), MicrosoftLoginCallbacks{
+		OnDeviceCode: func(code DeviceCodeResponse) { userCode = code.UserCode },
+	})
+	if err != nil {
+		t.Fatalf("AuthenticateMicrosoft failed: %v", err)
+	}
+
+	if userCode != "ABCD-EFGH" {
+		t.Errorf("device code callback not invoked, got %q", userCode)
+	}
+	if session.AccessToken != "mc-access" || session.RefreshToken != "ms-refresh" {
+		t.Errorf("unexpected tokens: %+v", session)
+	}
+	if session.Profile.Name != "Notch" {
+		t.Errorf("expected profile Notch, got %s", session.Profile.Name)
+	}
+}
+
+func TestAuthenticateMicrosoft_NoOwnership(t *testing.T) {
+	server := newMicrosoftStub(t, false)
+	defer server.Close()
+	setMicrosoftEndpoints(t, server.URL)
+
+	_, err := AuthenticateMicrosoft(context.Background(), MicrosoftLoginCallbacks{})
+	if err != ErrNoMinecraftOwnership {
+		t.Fatalf("expected ErrNoMinecraftOwnership, got %v", err)
+	}
+}
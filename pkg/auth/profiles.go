@@ -0,0 +1,214 @@
+package auth
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+const pendingLoginTTL = 10 * time.Minute
+
+var (
+	ErrProfileSelectionRequired = errors.New("account owns several profiles, select one to continue")
+	ErrNoProfiles               = errors.New("account has no game profiles")
+)
+
+// PendingLogin holds an authenticated Yggdrasil session that is not bound to a profile yet.
+type PendingLogin struct {
+	ID       string      `json:"id"`
+	Type     AccountType `json:"type"`
+	Username string      `json:"username"`
+	Profiles []Profile   `json:"profiles"`
+	// RememberedProfileID is the profile chosen on the last login with these credentials, if any.
+	RememberedProfileID string `json:"rememberedProfileId,omitempty"`
+
+	server      *YggdrasilServer
+	accessToken string
+	clientToken string
+	created     time.Time
+}
+
+type LoginResult struct {
+	Account *Account      `json:"account,omitempty"`
+	Pending *PendingLogin `json:"pending,omitempty"`
+}
+
+// BeginElyByLogin authenticates and binds the profile when the account owns only one. With
+// several profiles it returns a pending login, unless keepRemembered is set and a profile was
+// chosen for these credentials before.
+func (m *AccountManager) BeginElyByLogin(username, password string, keepRemembered bool) (*LoginResult, error) {
+	return m.beginLogin(AccountTypeElyBy, nil, NewElyByClient(), username, password, keepRemembered)
+}
+
+func (m *AccountManager) BeginYggdrasilLogin(serverURL, username, password string, keepRemembered bool) (*LoginResult, error) {
+	server, err := ResolveYggdrasilServer(serverURL)
+	if err != nil {
+		return nil, err
+	}
+	return m.beginLogin(AccountTypeYggdrasil, server, NewYggdrasilClient(server.AuthServerURL()), username, password, keepRemembered)
+}
+
+func (m *AccountManager) beginLogin(accType AccountType, server *YggdrasilServer, client *YggdrasilClient, username, password string, keepRemembered bool) (*LoginResult, error) {
+	resp, err := client.Authenticate(username, password, "")
+	if err != nil {
+		return nil, err
+	}
+
+	if len(resp.AvailableProfiles) <= 1 {
+		if resp.SelectedProfile.ID != "" {
+			acc, err := m.storeYggdrasilAccount(accType, server, resp)
+			if err != nil {
+				return nil, err
+			}
+			return &LoginResult{Account: acc}, nil
+		}
+		if len(resp.AvailableProfiles) == 0 {
+			return nil, ErrNoProfiles
+		}
+		acc, err := m.bindProfile(accType, server, client, resp.AccessToken, resp.ClientToken, resp.AvailableProfiles[0])
+		if err != nil {
+			return nil, err
+		}
+		return &LoginResult{Account: acc}, nil
+	}
+
+	remembered := m.rememberedProfile(accType, server, username, resp.AvailableProfiles)
+	if keepRemembered && remembered != nil {
+		acc, err := m.bindProfile(accType, server, client, resp.AccessToken, resp.ClientToken, *remembered)
+		if err != nil {
+			return nil, err
+		}
+		return &LoginResult{Account: acc}, nil
+	}
+
+	pending := &PendingLogin{
+		ID:          newPendingID(),
+		Type:        accType,
+		Username:    username,
+		Profiles:    resp.AvailableProfiles,
+		server:      server,
+		accessToken: resp.AccessToken,
+		clientToken: resp.ClientToken,
+		created:     time.Now(),
+	}
+	if remembered != nil {
+		pending.RememberedProfileID = remembered.ID
+	}
+
+	m.mu.Lock()
+	if m.pending == nil {
+		m.pending = make(map[string]*PendingLogin)
+	}
+	for id, p := range m.pending {
+		if time.Since(p.created) > pendingLoginTTL {
+			delete(m.pending, id)
+		}
+	}
+	m.pending[pending.ID] = pending
+	m.mu.Unlock()
+
+	return &LoginResult{Pending: pending}, nil
+}
+
+// SelectProfile finishes a pending login by binding the chosen profile through /refresh.
+func (m *AccountManager) SelectProfile(pendingID, profileID string) (*Account, error) {
+	m.mu.Lock()
+	pending, ok := m.pending[pendingID]
+	if ok {
+		delete(m.pending, pendingID)
+	}
+	m.mu.Unlock()
+
+	if !ok || time.Since(pending.created) > pendingLoginTTL {
+		return nil, fmt.Errorf("login session expired, please log in again")
+	}
+
+	var selected *Profile
+	for _, p := range pending.Profiles {
+		if p.ID == profileID {
+			pCopy := p
+			selected = &pCopy
+			break
+		}
+	}
+	if selected == nil {
+		return nil, fmt.Errorf("profile %s is not available for this account", profileID)
+	}
+
+	var client *YggdrasilClient
+	if pending.server != nil {
+		client = NewYggdrasilClient(pending.server.AuthServerURL())
+	} else {
+		client = NewElyByClient()
+	}
+
+	acc, err := m.bindProfile(pending.Type, pending.server, client, pending.accessToken, pending.clientToken, *selected)
+	if err != nil {
+		return nil, err
+	}
+	if err := m.rememberProfileChoice(pending.Type, pending.server, pending.Username, selected.ID); err != nil {
+		return nil, err
+	}
+	return acc, nil
+}
+
+func (m *AccountManager) bindProfile(accType AccountType, server *YggdrasilServer, client *YggdrasilClient, accessToken, clientToken string, profile Profile) (*Account, error) {
+	resp, err := client.Refresh(accessToken, clientToken, &profile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to select profile %s: %w", profile.Name, err)
+	}
+	if resp.SelectedProfile.ID == "" {
+		resp.SelectedProfile = profile
+	}
+	if resp.ClientToken == "" {
+		resp.ClientToken = clientToken
+	}
+	return m.storeYggdrasilAccount(accType, server, resp)
+}
+
+func (m *AccountManager) rememberedProfile(accType AccountType, server *YggdrasilServer, username string, profiles []Profile) *Profile {
+	m.mu.RLock()
+	id := m.Data.ProfileChoices[profileChoiceKey(accType, server, username)]
+	m.mu.RUnlock()
+
+	if id == "" {
+		return nil
+	}
+	for _, p := range profiles {
+		if p.ID == id {
+			pCopy := p
+			return &pCopy
+		}
+	}
+	return nil
+}
+
+func (m *AccountManager) rememberProfileChoice(accType AccountType, server *YggdrasilServer, username, profileID string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.Data.ProfileChoices == nil {
+		m.Data.ProfileChoices = make(map[string]string)
+	}
+	m.Data.ProfileChoices[profileChoiceKey(accType, server, username)] = profileID
+	return m.saveInternal()
+}
+
+// profileChoiceKey identifies a login by the credentials typed in, since several stored accounts
+// can share them.
+func profileChoiceKey(accType AccountType, server *YggdrasilServer, username string) string {
+	root := ""
+	if server != nil {
+		root = server.APIRoot
+	}
+	return string(accType) + "|" + root + "|" + strings.ToLower(strings.TrimSpace(username))
+}
+
+func newPendingID() string {
+	buf := make([]byte, 16)
+	_, _ = rand.Read(buf)
+	return hex.EncodeToString(buf)
+}
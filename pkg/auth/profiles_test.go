@@ -0,0 +1,102 @@
+package auth
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestProfileSelection(t *testing.T) {
+	profiles := []Profile{
+		{ID: "11111111111111111111111111111111", Name: "MainProfile"},
+		{ID: "22222222222222222222222222222222", Name: "AltProfile"},
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/auth/authenticate":
+			// The server picks a default profile, which must not hide the other one.
+			json.NewEncoder(w).Encode(AuthResponse{
+				AccessToken:       "unbound-token",
+				ClientToken:       "client",
+				SelectedProfile:   profiles[0],
+				AvailableProfiles: profiles,
+			})
+		case "/auth/refresh":
+			var payload TokenPayload
+			json.NewDecoder(r.Body).Decode(&payload)
+			if payload.SelectedProfile == nil {
+				t.Error("refresh called without selectedProfile")
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			json.NewEncoder(w).Encode(AuthResponse{
+				AccessToken:     "bound-token",
+				ClientToken:     payload.ClientToken,
+				SelectedProfile: *payload.SelectedProfile,
+			})
+		default:
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+	}))
+	defer server.Close()
+	useTestCredentialStore(t)
+
+	originalURL := os.Getenv("NEZORD_ELYBY_AUTH_URL")
+	os.Setenv("NEZORD_ELYBY_AUTH_URL", server.URL+"/auth/authenticate")
+	defer os.Setenv("NEZORD_ELYBY_AUTH_URL", originalURL)
+
+	manager := &AccountManager{
+		filePath: filepath.Join(t.TempDir(), "accounts.json"),
+		Data:     AccountData{Accounts: []Account{}},
+	}
+
+	if _, err := manager.AddElyByAccount("user", "pass"); !errors.Is(err, ErrProfileSelectionRequired) {
+		t.Fatalf("expected ErrProfileSelectionRequired, got %v", err)
+	}
+
+	result, err := manager.BeginElyByLogin("user", "pass", false)
+	if err != nil {
+		t.Fatalf("BeginElyByLogin failed: %v", err)
+	}
+	if result.Pending == nil || len(result.Pending.Profiles) != 2 {
+		t.Fatalf("expected pending login with 2 profiles, got %+v", result)
+	}
+	if result.Pending.RememberedProfileID != "" {
+		t.Errorf("first login should have no remembered profile, got %s", result.Pending.RememberedProfileID)
+	}
+
+	acc, err := manager.SelectProfile(result.Pending.ID, profiles[1].ID)
+	if err != nil {
+		t.Fatalf("SelectProfile failed: %v", err)
+	}
+	if acc.UUID != profiles[1].ID || acc.AccessToken != "bound-token" {
+		t.Errorf("unexpected bound account: %+v", acc)
+	}
+
+	// Without keepRemembered the picker comes back, preselecting the last choice, so the user
+	// can switch profiles.
+	again, err := manager.BeginElyByLogin("User", "pass", false)
+	if err != nil {
+		t.Fatalf("second login failed: %v", err)
+	}
+	if again.Pending == nil || again.Pending.RememberedProfileID != profiles[1].ID {
+		t.Fatalf("expected picker with remembered profile, got %+v", again)
+	}
+
+	kept, err := manager.BeginElyByLogin("user", "pass", true)
+	if err != nil {
+		t.Fatalf("third login failed: %v", err)
+	}
+	if kept.Account == nil || kept.Account.UUID != profiles[1].ID {
+		t.Errorf("expected remembered profile to be rebound, got %+v", kept)
+	}
+
+	if got, err := manager.BeginElyByLogin("other", "pass", true); err != nil || got.Pending == nil {
+		t.Errorf("choice must not carry over to another username, got %+v, %v", got, err)
+	}
+}
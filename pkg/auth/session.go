@@ -0,0 +1,140 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+const microsoftRefreshMargin = 5 * time.Minute
+
+// EnsureValidSession checks the stored tokens of an online account before launch and renews
+// them when needed. Network failures leave the account untouched so offline play still works;
+// only an explicit rejection by the auth server yields ErrReloginRequired.
+func (m *AccountManager) EnsureValidSession(ctx context.Context, uuid string) (*Account, error) {
+	acc, err := m.GetAccount(uuid)
+	if err != nil {
+		return nil, err
+	}
+
+	switch acc.Type {
+	case AccountTypeElyBy, AccountTypeYggdrasil:
+		client := yggdrasilClientFor(*acc)
+		if client == nil {
+			return nil, m.markRelogin(acc.UUID, fmt.Errorf("account has no bound yggdrasil server"))
+		}
+		return m.ensureYggdrasilSession(acc, client)
+	case AccountTypeMicrosoft:
+		return m.ensureMicrosoftSession(ctx, acc)
+	default:
+		return acc, nil
+	}
+}
+
+func (m *AccountManager) ensureYggdrasilSession(acc *Account, client *YggdrasilClient) (*Account, error) {
+	if acc.AccessToken == "" {
+		return nil, m.markRelogin(acc.UUID, fmt.Errorf("no access token stored"))
+	}
+
+	err := client.Validate(acc.AccessToken, acc.ClientToken)
+	if err == nil {
+		return acc, nil
+	}
+	if !isInvalidTokenError(err) {
+		return acc, nil
+	}
+
+	resp, err := client.Refresh(acc.AccessToken, acc.ClientToken, nil)
+	if err != nil {
+		if isInvalidTokenError(err) {
+			return nil, m.markRelogin(acc.UUID, err)
+		}
+		return acc, nil
+	}
+
+	return m.updateTokens(acc.UUID, func(a *Account) {
+		a.AccessToken = resp.AccessToken
+		if resp.ClientToken != "" {
+			a.ClientToken = resp.ClientToken
+		}
+		if resp.SelectedProfile.Name != "" {
+			a.Username = resp.SelectedProfile.Name
+		}
+	})
+}
+
+func (m *AccountManager) ensureMicrosoftSession(ctx context.Context, acc *Account) (*Account, error) {
+	if acc.AccessToken != "" && time.Until(acc.ExpiresAt) > microsoftRefreshMargin {
+		return acc, nil
+	}
+	if acc.RefreshToken == "" {
+		return nil, m.markRelogin(acc.UUID, fmt.Errorf("no refresh token stored"))
+	}
+
+	session, err := RefreshMicrosoft(ctx, acc.RefreshToken)
+	if err != nil {
+		if errors.Is(err, context.Canceled) {
+			return nil, err
+		}
+		var rejected *MicrosoftTokenRejectedError
+		if errors.As(err, &rejected) {
+			return nil, m.markRelogin(acc.UUID, err)
+		}
+		return acc, nil
+	}
+
+	return m.updateTokens(acc.UUID, func(a *Account) {
+		a.AccessToken = session.AccessToken
+		if session.RefreshToken != "" {
+			a.RefreshToken = session.RefreshToken
+		}
+		a.ExpiresAt = session.ExpiresAt
+		a.Username = session.Profile.Name
+	})
+}
+
+func (m *AccountManager) GetAccount(uuid string) (*Account, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	for _, acc := range m.Data.Accounts {
+		if acc.UUID == uuid {
+			accCopy := acc
+			return &accCopy, nil
+		}
+	}
+	return nil, fmt.Errorf("account with uuid %s not found", uuid)
+}
+
+func (m *AccountManager) updateTokens(uuid string, update func(*Account)) (*Account, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for i := range m.Data.Accounts {
+		if m.Data.Accounts[i].UUID == uuid {
+			update(&m.Data.Accounts[i])
+			m.Data.Accounts[i].NeedsRelogin = false
+			if err := m.saveInternal(); err != nil {
+				return nil, err
+			}
+			accCopy := m.Data.Accounts[i]
+			return &accCopy, nil
+		}
+	}
+	return nil, fmt.Errorf("account with uuid %s not found", uuid)
+}
+
+func (m *AccountManager) markRelogin(uuid string, cause error) error {
+	m.mu.Lock()
+	for i := range m.Data.Accounts {
+		if m.Data.Accounts[i].UUID == uuid {
+			m.Data.Accounts[i].NeedsRelogin = true
+			m.saveInternal()
+			break
+		}
+	}
+	m.mu.Unlock()
+
+	return fmt.Errorf("%w: %v", ErrReloginRequired, cause)
+}
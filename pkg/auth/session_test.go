@@ -0,0 +1,178 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func newSessionTestManager(t *testing.T, acc Account) *AccountManager {
+	manager := &AccountManager{
+		filePath: filepath.Join(t.TempDir(), "accounts.json"),
+		Data: AccountData{
+			Accounts:   []Account{acc},
+			ActiveUUID: acc.UUID,
+		},
+	}
+	return manager
+}
+
+func newYggdrasilStub(t *testing.T, refreshStatus int) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var payload TokenPayload
+		json.NewDecoder(r.Body).Decode(&payload)
+
+		switch r.URL.Path {
+		case "/auth/validate":
+			if payload.AccessToken == "fresh-token" {
+				w.WriteHeader(http.StatusNoContent)
+				return
+			}
+			w.WriteHeader(http.StatusForbidden)
+			w.Write([]byte(`{"error":"ForbiddenOperationException","errorMessage":"Token expired."}`))
+		case "/auth/refresh":
+			if refreshStatus != http.StatusOK {
+				w.WriteHeader(refreshStatus)
+				w.Write([]byte(`{"error":"ForbiddenOperationException","errorMessage":"Invalid token."}`))
+				return
+			}
+			json.NewEncoder(w).Encode(AuthResponse{
+				AccessToken:     "fresh-token",
+				ClientToken:     payload.ClientToken,
+				SelectedProfile: Profile{ID: "3f1d6a2b8c9e4f0a1b2c3d4e5f6a7b8c", Name: "Renamed"},
+			})
+		default:
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+	}))
+}
+
+func TestEnsureValidSession_RefreshesExpiredToken(t *testing.T) {
+	server := newYggdrasilStub(t, http.StatusOK)
+	defer server.Close()
+	useTestCredentialStore(t)
+
+	originalURL := os.Getenv("NEZORD_ELYBY_AUTH_URL")
+	os.Setenv("NEZORD_ELYBY_AUTH_URL", server.URL+"/auth/authenticate")
+	defer os.Setenv("NEZORD_ELYBY_AUTH_URL", originalURL)
+
+	manager := newSessionTestManager(t, Account{
+		UUID:        "3f1d6a2b8c9e4f0a1b2c3d4e5f6a7b8c",
+		Username:    "Original",
+		Type:        AccountTypeElyBy,
+		AccessToken: "stale-token",
+		ClientToken: "client",
+	})
+
+	acc, err := manager.EnsureValidSession(context.Background(), "3f1d6a2b8c9e4f0a1b2c3d4e5f6a7b8c")
+	if err != nil {
+		t.Fatalf("EnsureValidSession failed: %v", err)
+	}
+	if acc.AccessToken != "fresh-token" {
+		t.Errorf("expected refreshed token, got %s", acc.AccessToken)
+	}
+	if acc.Username != "Renamed" {
+		t.Errorf("expected username to follow refreshed profile, got %s", acc.Username)
+	}
+}
+
+func TestEnsureValidSession_ReloginRequired(t *testing.T) {
+	server := newYggdrasilStub(t, http.StatusForbidden)
+	defer server.Close()
+	useTestCredentialStore(t)
+
+	originalURL := os.Getenv("NEZORD_ELYBY_AUTH_URL")
+	os.Setenv("NEZORD_ELYBY_AUTH_URL", server.URL+"/auth/authenticate")
+	defer os.Setenv("NEZORD_ELYBY_AUTH_URL", originalURL)
+
+	manager := newSessionTestManager(t, Account{
+		UUID:        "3f1d6a2b8c9e4f0a1b2c3d4e5f6a7b8c",
+		Username:    "Original",
+		Type:        AccountTypeElyBy,
+		AccessToken: "revoked-token",
+		ClientToken: "client",
+	})
+
+	_, err := manager.EnsureValidSession(context.Background(), "3f1d6a2b8c9e4f0a1b2c3d4e5f6a7b8c")
+	if !errors.Is(err, ErrReloginRequired) {
+		t.Fatalf("expected ErrReloginRequired, got %v", err)
+	}
+	if !manager.GetAccounts()[0].NeedsRelogin {
+		t.Error("account should be flagged for re-login")
+	}
+}
+
+func expiredMicrosoftAccount() Account {
+	return Account{
+		UUID:         "3f1d6a2b8c9e4f0a1b2c3d4e5f6a7b8c",
+		Username:     "Original",
+		Type:         AccountTypeMicrosoft,
+		AccessToken:  "expired-token",
+		RefreshToken: "refresh-token",
+		ExpiresAt:    time.Now().Add(-time.Hour),
+	}
+}
+
+func TestEnsureValidSession_MicrosoftOfflineKeepsAccount(t *testing.T) {
+	useTestCredentialStore(t)
+
+	// A closed server stands in for an unreachable token endpoint.
+	server := httptest.NewServer(http.NotFoundHandler())
+	setMicrosoftEndpoints(t, server.URL)
+	server.Close()
+
+	manager := newSessionTestManager(t, expiredMicrosoftAccount())
+	acc, err := manager.EnsureValidSession(context.Background(), "3f1d6a2b8c9e4f0a1b2c3d4e5f6a7b8c")
+	if err != nil {
+		t.Fatalf("network failure should not fail the session check: %v", err)
+	}
+	if acc.AccessToken != "expired-token" {
+		t.Errorf("account should be left untouched, got token %s", acc.AccessToken)
+	}
+	if manager.GetAccounts()[0].NeedsRelogin {
+		t.Error("network failure must not flag the account for re-login")
+	}
+}
+
+func TestEnsureValidSession_MicrosoftServerErrorKeepsAccount(t *testing.T) {
+	useTestCredentialStore(t)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		w.Write([]byte("<html>maintenance</html>"))
+	}))
+	defer server.Close()
+	setMicrosoftEndpoints(t, server.URL)
+
+	manager := newSessionTestManager(t, expiredMicrosoftAccount())
+	if _, err := manager.EnsureValidSession(context.Background(), "3f1d6a2b8c9e4f0a1b2c3d4e5f6a7b8c"); err != nil {
+		t.Fatalf("server error should not fail the session check: %v", err)
+	}
+	if manager.GetAccounts()[0].NeedsRelogin {
+		t.Error("server error must not flag the account for re-login")
+	}
+}
+
+func TestEnsureValidSession_MicrosoftRejectedRefreshToken(t *testing.T) {
+	useTestCredentialStore(t)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(`{"error":"invalid_grant","error_description":"The refresh token has expired."}`))
+	}))
+	defer server.Close()
+	setMicrosoftEndpoints(t, server.URL)
+
+	manager := newSessionTestManager(t, expiredMicrosoftAccount())
+	_, err := manager.EnsureValidSession(context.Background(), "3f1d6a2b8c9e4f0a1b2c3d4e5f6a7b8c")
+	if !errors.Is(err, ErrReloginRequired) {
+		t.Fatalf("expected ErrReloginRequired, got %v", err)
+	}
+	if !manager.GetAccounts()[0].NeedsRelogin {
+		t.Error("account should be flagged for re-login")
+	}
+}
@@ -3,12 +3,17 @@ package auth
 import (
 	"NezordLauncher/pkg/network"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"net/http"
 	"os"
+	"strings"
 )
 
 const ElyByAuthURL = "https://authserver.ely.by/auth/authenticate"
 
+var ErrReloginRequired = errors.New("account session expired, re-login required")
+
 type AuthPayload struct {
 	Agent       Agent  `json:"agent"`
 	Username    string `json:"username"`
@@ -39,11 +44,66 @@ type User struct {
 	ID string `json:"id"`
 }
 
-func AuthenticateElyBy(username, password string) (*AuthResponse, error) {
+type TokenPayload struct {
+	AccessToken     string   `json:"accessToken"`
+	ClientToken     string   `json:"clientToken,omitempty"`
+	RequestUser     bool     `json:"requestUser,omitempty"`
+	SelectedProfile *Profile `json:"selectedProfile,omitempty"`
+}
+
+type SignoutPayload struct {
+	Username string `json:"username"`
+	Password string `json:"password"`
+}
+
+type YggdrasilError struct {
+	StatusCode   int    `json:"-"`
+	ErrorType    string `json:"error"`
+	ErrorMessage string `json:"errorMessage"`
+	Cause        string `json:"cause,omitempty"`
+}
+
+func (e *YggdrasilError) Error() string {
+	if e.ErrorMessage != "" {
+		return fmt.Sprintf("%s: %s", e.ErrorType, e.ErrorMessage)
+	}
+	if e.ErrorType != "" {
+		return e.ErrorType
+	}
+	return fmt.Sprintf("request failed with status %d", e.StatusCode)
+}
+
+// IsInvalidToken reports whether the server rejected the token itself rather than failing.
+func (e *YggdrasilError) IsInvalidToken() bool {
+	return e.StatusCode == http.StatusForbidden || e.StatusCode == http.StatusUnauthorized
+}
+
+// YggdrasilClient talks to an authserver root such as https://authserver.ely.by/auth.
+type YggdrasilClient struct {
+	AuthServerURL string
+	http          *network.HttpClient
+}
+
+func NewYggdrasilClient(authServerURL string) *YggdrasilClient {
+	return &YggdrasilClient{
+		AuthServerURL: strings.TrimSuffix(authServerURL, "/"),
+		http:          network.NewHttpClient(),
+	}
+}
+
+func NewElyByClient() *YggdrasilClient {
 	authURL := os.Getenv("NEZORD_ELYBY_AUTH_URL")
 	if authURL == "" {
 		authURL = ElyByAuthURL
 	}
+	return NewYggdrasilClient(strings.TrimSuffix(authURL, "/authenticate"))
+}
+
+func AuthenticateElyBy(username, password string) (*AuthResponse, error) {
+	return NewElyByClient().Authenticate(username, password, "")
+}
+
+func (c *YggdrasilClient) Authenticate(username, password, clientToken string) (*AuthResponse, error) {
 	payload := AuthPayload{
 		Agent: Agent{
 			Name:    "Minecraft",
@@ -51,24 +111,71 @@ func AuthenticateElyBy(username, password string) (*AuthResponse, error) {
 		},
 		Username:    username,
 		Password:    password,
+		ClientToken: clientToken,
 		RequestUser: true,
 	}
 
+	var resp AuthResponse
+	if err := c.post("/authenticate", payload, &resp); err != nil {
+		return nil, fmt.Errorf("authentication failed: %w", err)
+	}
+	return &resp, nil
+}
+
+// Validate returns nil when the access token is still usable.
+func (c *YggdrasilClient) Validate(accessToken, clientToken string) error {
+	return c.post("/validate", TokenPayload{AccessToken: accessToken, ClientToken: clientToken}, nil)
+}
+
+func (c *YggdrasilClient) Refresh(accessToken, clientToken string, selected *Profile) (*AuthResponse, error) {
+	payload := TokenPayload{
+		AccessToken:     accessToken,
+		ClientToken:     clientToken,
+		RequestUser:     true,
+		SelectedProfile: selected,
+	}
+
+	var resp AuthResponse
+	if err := c.post("/refresh", payload, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+func (c *YggdrasilClient) Invalidate(accessToken, clientToken string) error {
+	return c.post("/invalidate", TokenPayload{AccessToken: accessToken, ClientToken: clientToken}, nil)
+}
+
+func (c *YggdrasilClient) Signout(username, password string) error {
+	return c.post("/signout", SignoutPayload{Username: username, Password: password}, nil)
+}
+
+func (c *YggdrasilClient) post(path string, payload interface{}, out interface{}) error {
 	body, err := json.Marshal(payload)
 	if err != nil {
-		return nil, fmt.Errorf("failed to marshal auth payload: %w", err)
+		return fmt.Errorf("failed to marshal %s payload: %w", path, err)
 	}
 
-	client := network.NewHttpClient()
-	responseBytes, err := client.PostJSON(authURL, body)
+	data, statusCode, err := c.http.PostJSONWithStatus(c.AuthServerURL+path, body)
 	if err != nil {
-		return nil, fmt.Errorf("authentication failed: %w", err)
+		return err
 	}
 
-	var resp AuthResponse
-	if err := json.Unmarshal(responseBytes, &resp); err != nil {
-		return nil, fmt.Errorf("failed to parse auth response: %w", err)
+	if statusCode != http.StatusOK && statusCode != http.StatusNoContent {
+		yErr := &YggdrasilError{StatusCode: statusCode}
+		_ = json.Unmarshal(data, yErr)
+		return yErr
 	}
 
-	return &resp, nil
+	if out != nil {
+		if err := json.Unmarshal(data, out); err != nil {
+			return fmt.Errorf("failed to parse %s response: %w", path, err)
+		}
+	}
+	return nil
+}
+
+func isInvalidTokenError(err error) bool {
+	var yErr *YggdrasilError
+	return errors.As(err, &yErr) && yErr.IsInvalidToken()
 }
@@ -11,6 +11,7 @@ const (
 	Version              = "0.4.0"
 	VersionManifestV2URL = "https://piston-meta.mojang.com/mc/game/version_manifest_v2.json"
 	ResourcesURL         = "https://resources.download.minecraft.net/"
+	LibrariesURL         = "https://libraries.minecraft.net/"
 )
 
 func GetAppDataDir() string {
@@ -80,6 +81,10 @@ func GetVersionsDir() string {
 	return filepath.Join(GetDataDir(), "versions")
 }
 
+func GetSkinsDir() string {
+	return filepath.Join(GetDataDir(), "skins")
+}
+
 func GetLogsDir() string {
 	return filepath.Join(GetDataDir(), "logs")
 }
@@ -9,7 +9,9 @@ import (
 	"os"
 )
 
-func downloadWithResume(ctx context.Context, client *network.HttpClient, url, partPath string) (int64, error) {
+// downloadWithResume appends url to partPath, resuming a partial file when the server supports
+// ranges. onBytes is called as data arrives.
+func downloadWithResume(ctx context.Context, client *network.HttpClient, url, partPath string, onBytes func(int64)) (int64, error) {
 	offset := int64(0)
 	if info, err := os.Stat(partPath); err == nil {
 		offset = info.Size()
@@ -40,7 +42,7 @@ func downloadWithResume(ctx context.Context, client *network.HttpClient, url, pa
 
 		if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusPartialContent {
 			resp.Body.Close()
-			return 0, fmt.Errorf("unexpected status %d", resp.StatusCode)
+			return 0, &network.StatusError{StatusCode: resp.StatusCode}
 		}
 
 		flags := os.O_CREATE | os.O_WRONLY
@@ -57,7 +59,7 @@ func downloadWithResume(ctx context.Context, client *network.HttpClient, url, pa
 			return 0, err
 		}
 
-		n, err := io.Copy(f, resp.Body)
+		n, err := io.Copy(&progressWriter{w: f, onWrite: onBytes}, resp.Body)
 		closeErr := f.Close()
 		resp.Body.Close()
 		if err != nil {
@@ -72,3 +74,16 @@ func downloadWithResume(ctx context.Context, client *network.HttpClient, url, pa
 
 	return 0, fmt.Errorf("range not satisfiable")
 }
+
+type progressWriter struct {
+	w       io.Writer
+	onWrite func(int64)
+}
+
+func (pw *progressWriter) Write(p []byte) (int, error) {
+	n, err := pw.w.Write(p)
+	if n > 0 && pw.onWrite != nil {
+		pw.onWrite(int64(n))
+	}
+	return n, err
+}
@@ -18,6 +18,11 @@ import (
 type ArtifactFetcher struct {
 	pool   *WorkerPool
 	Filter func(path string) bool
+
+	// Recorded while fetching, for the install receipt.
+	chain          []string
+	planned        []Task
+	assetIndexPath string
 }
 
 func NewArtifactFetcher(pool *WorkerPool) *ArtifactFetcher {
@@ -53,11 +58,17 @@ func (f *ArtifactFetcher) DownloadVersion(ctx context.Context, versionID string)
 	return nil
 }
 
+func (f *ArtifactFetcher) submit(t Task) {
+	f.planned = append(f.planned, t)
+	f.pool.Submit(t)
+}
+
 func (f *ArtifactFetcher) getVersionDetails(versionID string, visited map[string]struct{}) (*models.VersionDetail, error) {
 	if _, ok := visited[versionID]; ok {
 		return nil, fmt.Errorf("version inheritance loop detected")
 	}
 	visited[versionID] = struct{}{}
+	f.chain = append(f.chain, versionID)
 
 	detail, err := f.loadCachedVersion(versionID)
 	if err != nil {
@@ -175,8 +186,17 @@ func (f *ArtifactFetcher) fetchAndCacheVersion(versionID string) (*models.Versio
 }
 
 func (f *ArtifactFetcher) downloadClient(ctx context.Context, v *models.VersionDetail) error {
+	jarID := v.ID
+	if v.Jar != "" {
+		jarID = v.Jar
+	}
+	// A child version with its own jar (a jar mod) builds it locally from the parent's.
+	if v.InheritsFrom != "" && jarID != v.InheritsFrom {
+		return nil
+	}
+
 	if v.Downloads.Client.URL != "" {
-		path := filepath.Join(constants.GetVersionsDir(), v.ID, fmt.Sprintf("%s.jar", v.ID))
+		path := filepath.Join(constants.GetVersionsDir(), jarID, fmt.Sprintf("%s.jar", jarID))
 
 		select {
 		case <-ctx.Done():
@@ -189,7 +209,7 @@ func (f *ArtifactFetcher) downloadClient(ctx context.Context, v *models.VersionD
 			}
 
 			f.pool.Progress.AddTotal(1, int64(v.Downloads.Client.Size))
-			f.pool.Submit(Task{
+			f.submit(Task{
 				URL:  v.Downloads.Client.URL,
 				Path: path,
 				SHA1: v.Downloads.Client.SHA1,
@@ -222,12 +242,13 @@ func (f *ArtifactFetcher) downloadLibraries(ctx context.Context, v *models.Versi
 					Size: int64(lib.Downloads.Artifact.Size),
 				})
 			}
-		} else if lib.Name != "" {
+		} else if lib.Name != "" && lib.Downloads.Artifact.Path == "" {
+			// Artifacts with a path but no URL are generated locally by loader installers.
 			relPath := lib.GetMavenPath()
 			if relPath != "" {
 				baseURL := lib.URL
 				if baseURL == "" {
-					baseURL = "https://libraries.minecraft.net/"
+					baseURL = constants.LibrariesURL
 				}
 
 				if !strings.HasSuffix(baseURL, "/") {
@@ -275,7 +296,7 @@ func (f *ArtifactFetcher) downloadLibraries(ctx context.Context, v *models.Versi
 			if ctx.Err() != nil {
 				return ctx.Err()
 			}
-			f.pool.Submit(t)
+			f.submit(t)
 		}
 	}
 
@@ -297,6 +318,7 @@ func (f *ArtifactFetcher) downloadAssets(ctx context.Context, v *models.VersionD
 		}
 	}
 	idxPath := filepath.Join(constants.GetAssetsDir(), "indexes", fmt.Sprintf("%s.json", indexID))
+	f.assetIndexPath = idxPath
 
 	client := network.NewHttpClient()
 	idxData, err := client.Get(idxURL)
@@ -322,7 +344,7 @@ func (f *ArtifactFetcher) downloadAssets(ctx context.Context, v *models.VersionD
 		return err
 	}
 
-	baseAssetURL := "https://resources.download.minecraft.net/"
+	baseAssetURL := constants.ResourcesURL
 	objectsDir := filepath.Join(constants.GetAssetsDir(), "objects")
 
 	var tasks []Task
@@ -359,7 +381,7 @@ func (f *ArtifactFetcher) downloadAssets(ctx context.Context, v *models.VersionD
 			if ctx.Err() != nil {
 				return ctx.Err()
 			}
-			f.pool.Submit(t)
+			f.submit(t)
 		}
 	}
 
@@ -0,0 +1,138 @@
+package downloader
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+)
+
+type indexEntry struct {
+	Size    int64  `json:"size"`
+	ModTime int64  `json:"mtime"`
+	Inode   uint64 `json:"inode,omitempty"`
+	SHA1    string `json:"sha1"`
+}
+
+func statEntry(info os.FileInfo) indexEntry {
+	return indexEntry{Size: info.Size(), ModTime: info.ModTime().UnixNano(), Inode: fileInode(info)}
+}
+
+func (e indexEntry) sameFile(other indexEntry) bool {
+	return e.Size == other.Size && e.ModTime == other.ModTime && e.Inode == other.Inode
+}
+
+// HashIndex remembers the SHA1 of files verified earlier, keyed by path and stat data, so files
+// that have not changed since are not hashed again.
+type HashIndex struct {
+	mu      sync.Mutex
+	path    string
+	entries map[string]indexEntry
+	dirty   bool
+}
+
+// OpenHashIndex loads the index stored at path. A missing or unreadable index starts empty.
+func OpenHashIndex(path string) *HashIndex {
+	idx := &HashIndex{path: path, entries: make(map[string]indexEntry)}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return idx
+	}
+	if err := json.Unmarshal(data, &idx.entries); err != nil || idx.entries == nil {
+		idx.entries = make(map[string]indexEntry)
+	}
+	return idx
+}
+
+// Verify reports whether the file at path has the expected SHA1, hashing it only when its stat
+// data differs from the last verification.
+func (x *HashIndex) Verify(path, expected string) (bool, error) {
+	if expected == "" {
+		return false, nil
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		x.forget(path)
+		return false, err
+	}
+	current := statEntry(info)
+
+	x.mu.Lock()
+	entry, ok := x.entries[path]
+	x.mu.Unlock()
+	if ok && entry.sameFile(current) && strings.EqualFold(entry.SHA1, expected) {
+		return true, nil
+	}
+
+	return x.DeepVerify(path, expected)
+}
+
+// DeepVerify hashes the file regardless of the index and records the result.
+func (x *HashIndex) DeepVerify(path, expected string) (bool, error) {
+	// Stat before hashing so a write during the hash leaves a stale, not a wrong, entry.
+	info, err := os.Stat(path)
+	if err != nil {
+		x.forget(path)
+		return false, err
+	}
+	valid, err := VerifyFileSHA1(path, expected)
+	if err != nil || !valid {
+		x.forget(path)
+		return valid, err
+	}
+	x.record(path, info, expected)
+	return true, nil
+}
+
+// Record stores the hash of a file the caller has just verified, e.g. a committed download.
+func (x *HashIndex) Record(path, sha1 string) {
+	if sha1 == "" {
+		return
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return
+	}
+	x.record(path, info, sha1)
+}
+
+func (x *HashIndex) record(path string, info os.FileInfo, sha1 string) {
+	entry := statEntry(info)
+	entry.SHA1 = strings.ToLower(sha1)
+
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	if old, ok := x.entries[path]; ok && old == entry {
+		return
+	}
+	x.entries[path] = entry
+	x.dirty = true
+}
+
+func (x *HashIndex) forget(path string) {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	if _, ok := x.entries[path]; ok {
+		delete(x.entries, path)
+		x.dirty = true
+	}
+}
+
+// Save writes the index if it changed since it was loaded or last saved.
+func (x *HashIndex) Save() error {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	if !x.dirty {
+		return nil
+	}
+	data, err := json.Marshal(x.entries)
+	if err != nil {
+		return fmt.Errorf("failed to encode hash index: %w", err)
+	}
+	if err := AtomicWriteFile(x.path, data); err != nil {
+		return fmt.Errorf("failed to write hash index: %w", err)
+	}
+	x.dirty = false
+	return nil
+}
@@ -0,0 +1,79 @@
+package downloader
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestHashIndexSkipsUnchangedFiles(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "asset")
+	content := []byte("asset-data")
+	sum := sha1.Sum(content)
+	hash := hex.EncodeToString(sum[:])
+	if err := os.WriteFile(file, content, 0644); err != nil {
+		t.Fatal(err)
+	}
+	mtime := time.Now().Add(-time.Hour).Truncate(time.Second)
+	if err := os.Chtimes(file, mtime, mtime); err != nil {
+		t.Fatal(err)
+	}
+
+	indexPath := filepath.Join(dir, "index.json")
+	idx := OpenHashIndex(indexPath)
+	if ok, err := idx.Verify(file, hash); !ok || err != nil {
+		t.Fatalf("expected file to verify: %v", err)
+	}
+	if err := idx.Save(); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	// Same size and mtime: the reloaded index trusts its entry without reading the file.
+	if err := os.WriteFile(file, []byte("ASSET-DATA"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chtimes(file, mtime, mtime); err != nil {
+		t.Fatal(err)
+	}
+	idx = OpenHashIndex(indexPath)
+	if ok, _ := idx.Verify(file, hash); !ok {
+		t.Fatalf("expected unchanged stat data to skip hashing")
+	}
+	if ok, _ := idx.DeepVerify(file, hash); ok {
+		t.Fatalf("expected deep verify to hash the file")
+	}
+
+	// The failed deep verify dropped the entry, so the next check hashes again.
+	if ok, _ := idx.Verify(file, hash); ok {
+		t.Fatalf("expected changed file to fail verification")
+	}
+}
+
+func TestHashIndexRehashesOnStatChange(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "lib.jar")
+	content := []byte("library")
+	sum := sha1.Sum(content)
+	hash := hex.EncodeToString(sum[:])
+	if err := os.WriteFile(file, content, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	idx := OpenHashIndex(filepath.Join(dir, "index.json"))
+	idx.Record(file, hash)
+
+	if err := os.WriteFile(file, []byte("corrupt"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	later := time.Now().Add(time.Hour)
+	if err := os.Chtimes(file, later, later); err != nil {
+		t.Fatal(err)
+	}
+	if ok, _ := idx.Verify(file, hash); ok {
+		t.Fatalf("expected modified file to be hashed again")
+	}
+}
@@ -0,0 +1,446 @@
+package downloader
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+type JobState string
+
+const (
+	JobQueued    JobState = "queued"
+	JobRunning   JobState = "running"
+	JobPaused    JobState = "paused"
+	JobFailed    JobState = "failed"
+	JobDone      JobState = "done"
+	JobCancelled JobState = "cancelled"
+)
+
+var (
+	ErrJobNotFound = errors.New("download job not found")
+	// ErrIncomplete wraps the failure of a job whose tasks ran but some files failed.
+	ErrIncomplete = errors.New("download incomplete")
+)
+
+// JobFunc submits a job's tasks to the pool. The manager waits for the pool afterwards.
+type JobFunc func(ctx context.Context, pool *WorkerPool) error
+
+// JobInfo is a snapshot of a job for the frontend.
+type JobInfo struct {
+	ID           string   `json:"id"`
+	Key          string   `json:"key"`
+	Label        string   `json:"label"`
+	InstanceID   string   `json:"instanceId,omitempty"`
+	State        JobState `json:"state"`
+	Error        string   `json:"error,omitempty"`
+	Current      int      `json:"current"`
+	Total        int      `json:"total"`
+	CurrentBytes int64    `json:"currentBytes"`
+	TotalBytes   int64    `json:"totalBytes"`
+	Speed        float64  `json:"speed"`
+	Eta          float64  `json:"eta"`
+}
+
+type Job struct {
+	ID         string
+	Key        string
+	Label      string
+	InstanceID string
+
+	manager   *Manager
+	run       JobFunc
+	state     JobState
+	err       error
+	completed *CompletedSet
+	progress  *DownloadProgress
+	// pool is set while the job's workers are running or draining.
+	pool   *WorkerPool
+	cancel context.CancelFunc
+	// done is closed when the job settles as done, failed or cancelled; a retry replaces it.
+	done chan struct{}
+}
+
+// Wait blocks until the job is done, failed or cancelled. A paused job keeps Wait blocked until
+// it is resumed and finishes, or is cancelled.
+func (j *Job) Wait(ctx context.Context) error {
+	m := j.manager
+	for {
+		m.mu.Lock()
+		done := j.done
+		m.mu.Unlock()
+
+		select {
+		case <-done:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+
+		m.mu.Lock()
+		state, err := j.state, j.err
+		replaced := j.done != done
+		m.mu.Unlock()
+		if replaced {
+			continue
+		}
+		if state == JobCancelled {
+			return context.Canceled
+		}
+		return err
+	}
+}
+
+// Manager runs download jobs with a limited number of them active at once.
+type Manager struct {
+	mu      sync.Mutex
+	jobs    map[string]*Job
+	order   []*Job
+	seq     int
+	running int
+
+	maxConcurrent int
+	workers       int
+
+	// Index is shared by every job's pool and saved when a job stops.
+	Index *HashIndex
+
+	// OnUpdate is called after a job changes state.
+	OnUpdate func(JobInfo)
+	// OnProgress is called every ProgressInterval while a job runs.
+	OnProgress       func(JobInfo)
+	ProgressInterval time.Duration
+}
+
+// NewManager creates a manager running up to maxConcurrent jobs with workers downloads each.
+func NewManager(maxConcurrent, workers int) *Manager {
+	if maxConcurrent < 1 {
+		maxConcurrent = 1
+	}
+	if workers < 1 {
+		workers = 1
+	}
+	return &Manager{
+		jobs:             make(map[string]*Job),
+		maxConcurrent:    maxConcurrent,
+		workers:          workers,
+		ProgressInterval: 100 * time.Millisecond,
+	}
+}
+
+// SetMaxConcurrent changes the job limit. Running jobs above a lowered limit are left to finish.
+func (m *Manager) SetMaxConcurrent(n int) {
+	if n < 1 {
+		n = 1
+	}
+	m.mu.Lock()
+	m.maxConcurrent = n
+	started := m.schedule()
+	m.mu.Unlock()
+	m.notify(started...)
+}
+
+// Submit queues a job. If a job with the same key is still queued, running or paused, that job
+// is returned instead of starting a second download of the same files; a failed one is retried.
+func (m *Manager) Submit(key, label, instanceID string, fn JobFunc) *Job {
+	m.mu.Lock()
+	for _, j := range m.order {
+		if j.Key != key {
+			continue
+		}
+		if j.active() {
+			m.mu.Unlock()
+			return j
+		}
+		if j.state == JobFailed {
+			j.run = fn
+			m.mu.Unlock()
+			if err := m.Retry(j.ID); err == nil {
+				return j
+			}
+			m.mu.Lock()
+			break
+		}
+	}
+
+	m.seq++
+	job := &Job{
+		ID:         fmt.Sprintf("job-%d", m.seq),
+		Key:        key,
+		Label:      label,
+		InstanceID: instanceID,
+		manager:    m,
+		run:        fn,
+		state:      JobQueued,
+		completed:  NewCompletedSet(),
+		done:       make(chan struct{}),
+	}
+	m.jobs[job.ID] = job
+	m.order = append(m.order, job)
+	started := m.schedule()
+	m.mu.Unlock()
+
+	m.notify(job)
+	m.notify(started...)
+	return job
+}
+
+func (m *Manager) Get(id string) (*Job, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	j, ok := m.jobs[id]
+	return j, ok
+}
+
+// Jobs returns snapshots of all jobs in submission order.
+func (m *Manager) Jobs() []JobInfo {
+	m.mu.Lock()
+	jobs := append([]*Job(nil), m.order...)
+	m.mu.Unlock()
+
+	infos := make([]JobInfo, 0, len(jobs))
+	for _, j := range jobs {
+		infos = append(infos, m.info(j))
+	}
+	return infos
+}
+
+// Cancel stops a job for good. Files it already finished stay on disk.
+func (m *Manager) Cancel(id string) error {
+	m.mu.Lock()
+	job, ok := m.jobs[id]
+	if !ok {
+		m.mu.Unlock()
+		return ErrJobNotFound
+	}
+	if !job.active() {
+		m.mu.Unlock()
+		return nil
+	}
+
+	job.state = JobCancelled
+	if job.pool != nil {
+		// execute settles the job once its pool has drained.
+		job.cancel()
+	} else {
+		close(job.done)
+	}
+	m.mu.Unlock()
+	m.notify(job)
+	return nil
+}
+
+// CancelAll cancels every queued, running and paused job.
+func (m *Manager) CancelAll() {
+	m.mu.Lock()
+	var ids []string
+	for _, j := range m.order {
+		if j.active() {
+			ids = append(ids, j.ID)
+		}
+	}
+	m.mu.Unlock()
+
+	for _, id := range ids {
+		_ = m.Cancel(id)
+	}
+}
+
+// Pause stops a queued or running job without settling it; Resume picks it up again.
+func (m *Manager) Pause(id string) error {
+	m.mu.Lock()
+	job, ok := m.jobs[id]
+	if !ok {
+		m.mu.Unlock()
+		return ErrJobNotFound
+	}
+
+	switch job.state {
+	case JobRunning:
+		job.state = JobPaused
+		job.cancel()
+		m.mu.Unlock()
+		m.notify(job)
+		return nil
+	case JobQueued:
+		job.state = JobPaused
+		m.mu.Unlock()
+		m.notify(job)
+		return nil
+	default:
+		m.mu.Unlock()
+		return fmt.Errorf("cannot pause a %s job", job.state)
+	}
+}
+
+func (m *Manager) Resume(id string) error {
+	return m.requeue(id, JobPaused)
+}
+
+// Retry queues a failed job again. Files it completed before failing are not checked again.
+func (m *Manager) Retry(id string) error {
+	return m.requeue(id, JobFailed)
+}
+
+func (m *Manager) requeue(id string, from JobState) error {
+	m.mu.Lock()
+	job, ok := m.jobs[id]
+	if !ok {
+		m.mu.Unlock()
+		return ErrJobNotFound
+	}
+	if job.state != from {
+		m.mu.Unlock()
+		return fmt.Errorf("job is %s, not %s", job.state, from)
+	}
+	if job.pool != nil {
+		// Still draining after a pause.
+		m.mu.Unlock()
+		return fmt.Errorf("job is still stopping")
+	}
+
+	if from == JobFailed {
+		job.done = make(chan struct{})
+	}
+	job.state = JobQueued
+	job.err = nil
+	started := m.schedule()
+	m.mu.Unlock()
+
+	m.notify(job)
+	m.notify(started...)
+	return nil
+}
+
+// ClearFinished forgets jobs that are done or cancelled.
+func (m *Manager) ClearFinished() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	kept := m.order[:0]
+	for _, j := range m.order {
+		if j.state == JobDone || j.state == JobCancelled {
+			delete(m.jobs, j.ID)
+			continue
+		}
+		kept = append(kept, j)
+	}
+	m.order = kept
+}
+
+// schedule starts queued jobs up to the limit and returns them. Callers hold m.mu.
+func (m *Manager) schedule() []*Job {
+	var started []*Job
+	for _, j := range m.order {
+		if m.running >= m.maxConcurrent {
+			break
+		}
+		if j.state != JobQueued {
+			continue
+		}
+
+		ctx, cancel := context.WithCancel(context.Background())
+		pool := NewWorkerPool(m.workers, 100)
+		pool.Completed = j.completed
+		pool.Index = m.Index
+		j.state = JobRunning
+		j.cancel = cancel
+		j.pool = pool
+		j.progress = pool.Progress
+		m.running++
+		started = append(started, j)
+		go m.execute(ctx, j, pool)
+	}
+	return started
+}
+
+func (m *Manager) execute(ctx context.Context, job *Job, pool *WorkerPool) {
+	pool.Start(ctx)
+
+	stopProgress := make(chan struct{})
+	progressDone := make(chan struct{})
+	go func() {
+		defer close(progressDone)
+		if m.OnProgress == nil {
+			return
+		}
+		ticker := time.NewTicker(m.ProgressInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				m.OnProgress(m.info(job))
+			case <-stopProgress:
+				return
+			}
+		}
+	}()
+
+	err := job.run(ctx, pool)
+	pool.Wait()
+	close(stopProgress)
+	<-progressDone
+	if err == nil && ctx.Err() == nil {
+		if n := len(pool.Errors()); n > 0 {
+			err = fmt.Errorf("%w: %d task errors", ErrIncomplete, n)
+		}
+	}
+
+	if pool.Index != nil {
+		if err := pool.Index.Save(); err != nil {
+			fmt.Printf("[Downloader Error] %v\n", err)
+		}
+	}
+
+	m.mu.Lock()
+	m.running--
+	job.cancel()
+	job.pool = nil
+	switch job.state {
+	case JobPaused:
+		// Stays paused until resumed; Wait keeps blocking.
+	case JobCancelled:
+		close(job.done)
+	default:
+		if err != nil {
+			job.state = JobFailed
+			job.err = err
+		} else {
+			job.state = JobDone
+		}
+		close(job.done)
+	}
+	started := m.schedule()
+	m.mu.Unlock()
+
+	m.notify(job)
+	m.notify(started...)
+}
+
+func (j *Job) active() bool {
+	return j.state == JobQueued || j.state == JobRunning || j.state == JobPaused
+}
+
+func (m *Manager) info(j *Job) JobInfo {
+	m.mu.Lock()
+	info := JobInfo{ID: j.ID, Key: j.Key, Label: j.Label, InstanceID: j.InstanceID, State: j.state}
+	if j.err != nil {
+		info.Error = j.err.Error()
+	}
+	progress := j.progress
+	m.mu.Unlock()
+
+	if progress != nil {
+		info.Current, info.Total, info.CurrentBytes, info.TotalBytes, info.Speed, info.Eta = progress.GetMetrics()
+	}
+	return info
+}
+
+func (m *Manager) notify(jobs ...*Job) {
+	if m.OnUpdate == nil {
+		return
+	}
+	for _, j := range jobs {
+		m.OnUpdate(m.info(j))
+	}
+}
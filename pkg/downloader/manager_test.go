@@ -0,0 +1,159 @@
+package downloader
+
+import (
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func waitForState(t *testing.T, m *Manager, id string, want JobState) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		for _, info := range m.Jobs() {
+			if info.ID == id && info.State == want {
+				return
+			}
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	t.Fatalf("job %s never reached %s: %+v", id, want, m.Jobs())
+}
+
+func blockingJob(started chan<- struct{}) JobFunc {
+	return func(ctx context.Context, pool *WorkerPool) error {
+		started <- struct{}{}
+		<-ctx.Done()
+		return ctx.Err()
+	}
+}
+
+func TestManagerLimitsConcurrentJobs(t *testing.T) {
+	m := NewManager(1, 1)
+	started := make(chan struct{}, 2)
+
+	first := m.Submit("a", "a", "", blockingJob(started))
+	second := m.Submit("b", "b", "", blockingJob(started))
+	<-started
+	waitForState(t, m, first.ID, JobRunning)
+	waitForState(t, m, second.ID, JobQueued)
+
+	if dup := m.Submit("a", "a", "", blockingJob(started)); dup != first {
+		t.Fatalf("expected duplicate key to return the active job")
+	}
+
+	if err := m.Cancel(first.ID); err != nil {
+		t.Fatalf("Cancel failed: %v", err)
+	}
+	if err := first.Wait(context.Background()); !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected cancelled job, got %v", err)
+	}
+	<-started
+	waitForState(t, m, second.ID, JobRunning)
+	m.CancelAll()
+	second.Wait(context.Background())
+}
+
+func TestManagerPauseResume(t *testing.T) {
+	m := NewManager(2, 1)
+	started := make(chan struct{}, 2)
+	var runs int32
+	job := m.Submit("a", "a", "", func(ctx context.Context, pool *WorkerPool) error {
+		if atomic.AddInt32(&runs, 1) == 2 {
+			return nil
+		}
+		return blockingJob(started)(ctx, pool)
+	})
+	<-started
+
+	if err := m.Pause(job.ID); err != nil {
+		t.Fatalf("Pause failed: %v", err)
+	}
+	waitForState(t, m, job.ID, JobPaused)
+
+	// Resume is rejected until the paused run has drained.
+	deadline := time.Now().Add(5 * time.Second)
+	for m.Resume(job.ID) != nil {
+		if time.Now().After(deadline) {
+			t.Fatalf("Resume never succeeded")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	if err := job.Wait(context.Background()); err != nil {
+		t.Fatalf("resumed job failed: %v", err)
+	}
+	if atomic.LoadInt32(&runs) != 2 {
+		t.Fatalf("expected 2 runs, got %d", runs)
+	}
+}
+
+func TestManagerRetrySkipsCompletedFiles(t *testing.T) {
+	content := []byte("library")
+	sum := sha1.Sum(content)
+	hash := hex.EncodeToString(sum[:])
+
+	var goodHits, flakyHits int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/good":
+			atomic.AddInt32(&goodHits, 1)
+		case "/flaky":
+			if atomic.AddInt32(&flakyHits, 1) == 1 {
+				http.NotFound(w, r)
+				return
+			}
+		}
+		w.Write(content)
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	good := filepath.Join(dir, "good.jar")
+	flaky := filepath.Join(dir, "flaky.jar")
+
+	m := NewManager(1, 2)
+	fn := func(ctx context.Context, pool *WorkerPool) error {
+		pool.Progress.AddTotal(2, 2*int64(len(content)))
+		pool.Submit(Task{URL: server.URL + "/good", Path: good, SHA1: hash, Size: int64(len(content))})
+		pool.Submit(Task{URL: server.URL + "/flaky", Path: flaky, SHA1: hash, Size: int64(len(content))})
+		return nil
+	}
+
+	job := m.Submit("version:1", "1", "", fn)
+	if err := job.Wait(context.Background()); !errors.Is(err, ErrIncomplete) {
+		t.Fatalf("expected incomplete job, got %v", err)
+	}
+	waitForState(t, m, job.ID, JobFailed)
+
+	// A completed file is trusted on retry even if it changed on disk since.
+	if err := os.WriteFile(good, []byte("corrupt"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if again := m.Submit("version:1", "1", "", fn); again != job {
+		t.Fatalf("expected resubmitting a failed key to retry the same job")
+	}
+	if err := job.Wait(context.Background()); err != nil {
+		t.Fatalf("retry failed: %v", err)
+	}
+
+	if goodHits != 1 {
+		t.Errorf("completed file was fetched %d times", goodHits)
+	}
+	if ok, _ := VerifyFileSHA1(flaky, hash); !ok {
+		t.Errorf("failed file was not downloaded on retry")
+	}
+	infos := m.Jobs()
+	if len(infos) != 1 || infos[0].State != JobDone || infos[0].Current != 2 {
+		t.Errorf("unexpected jobs after retry: %+v", infos)
+	}
+}
@@ -18,6 +18,43 @@ type WorkerPool struct {
 	errorMutex sync.Mutex
 	Progress   *DownloadProgress
 	errDone    chan struct{}
+	done       <-chan struct{}
+	// Completed, when set, records finished paths and skips them without re-verifying, so a
+	// retried job only processes what it had not finished.
+	Completed *CompletedSet
+	// Index, when set, skips hashing files unchanged since they were last verified.
+	Index *HashIndex
+	// DeepVerify hashes every existing file even if the index has it.
+	DeepVerify bool
+}
+
+// CompletedSet is the set of paths a job has already fetched or verified.
+type CompletedSet struct {
+	mu    sync.Mutex
+	paths map[string]struct{}
+}
+
+func NewCompletedSet() *CompletedSet {
+	return &CompletedSet{paths: make(map[string]struct{})}
+}
+
+func (s *CompletedSet) Has(path string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, ok := s.paths[path]
+	return ok
+}
+
+func (s *CompletedSet) Add(path string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.paths[path] = struct{}{}
+}
+
+func (s *CompletedSet) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.paths)
 }
 
 func NewWorkerPool(workers int, bufferSize int) *WorkerPool {
@@ -31,6 +68,7 @@ func NewWorkerPool(workers int, bufferSize int) *WorkerPool {
 }
 
 func (p *WorkerPool) Start(ctx context.Context) {
+	p.done = ctx.Done()
 	for i := 0; i < p.workers; i++ {
 		p.wg.Add(1)
 		go p.worker(ctx)
@@ -62,8 +100,13 @@ func (p *WorkerPool) Wait() {
 	<-p.errDone
 }
 
+// Submit queues a task. Once the pool's context is cancelled the task is dropped instead of
+// blocking on workers that have stopped.
 func (p *WorkerPool) Submit(t Task) {
-	p.tasks <- t
+	select {
+	case p.tasks <- t:
+	case <-p.done:
+	}
 }
 
 func (p *WorkerPool) Errors() []error {
@@ -97,13 +140,25 @@ func (p *WorkerPool) worker(ctx context.Context) {
 	}
 }
 
-func (p *WorkerPool) process(ctx context.Context, t Task, client *network.HttpClient) error {
+func (p *WorkerPool) process(ctx context.Context, t Task, client *network.HttpClient) (err error) {
 	if t.URL == "" || t.Path == "" {
 		return fmt.Errorf("invalid task")
 	}
 
+	if p.Completed != nil {
+		if p.Completed.Has(t.Path) {
+			p.Progress.Increment(t.Size, 0)
+			return nil
+		}
+		defer func() {
+			if err == nil {
+				p.Completed.Add(t.Path)
+			}
+		}()
+	}
+
 	if t.SHA1 != "" {
-		if valid, _ := VerifyFileSHA1(t.Path, t.SHA1); valid {
+		if valid, _ := p.verify(t.Path, t.SHA1); valid {
 			p.Progress.Increment(t.Size, 0)
 			return nil
 		}
@@ -123,15 +178,50 @@ func (p *WorkerPool) process(ctx context.Context, t Task, client *network.HttpCl
 
 	partPath := t.Path + ".part"
 
-	n, err := downloadWithResume(ctx, client, t.URL, partPath)
-	if err != nil {
-		return err
-	}
+	// Mirrors are tried in order; one that errors or serves a wrong hash falls through to the next.
+	for i, url := range network.Candidates(t.URL) {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		// A partial file from another host must not be resumed against this one.
+		if i > 0 {
+			if err := os.Remove(partPath); err != nil && !os.IsNotExist(err) {
+				return err
+			}
+		}
+		var streamed int64
+		_, err = downloadWithResume(ctx, client, url, partPath, func(n int64) {
+			streamed += n
+			p.Progress.AddStreamed(n)
+		})
+		if err == nil {
+			err = CommitFile(partPath, t.Path, t.SHA1)
+		}
+		if err != nil {
+			p.Progress.Rewind(streamed)
+			if url != t.URL && network.IsMirrorFault(err) {
+				network.ReportMirrorFailure(url)
+			}
+			continue
+		}
 
-	if err := CommitFile(partPath, t.Path, t.SHA1); err != nil {
-		return err
+		if p.Index != nil {
+			p.Index.Record(t.Path, t.SHA1)
+		}
+		// Streamed bytes are already counted; this adds any resumed prefix and the file itself.
+		p.Progress.Increment(t.Size-streamed, 0)
+		return nil
 	}
+	return err
+}
 
-	p.Progress.Increment(t.Size, n)
-	return nil
+func (p *WorkerPool) verify(path, sha1 string) (bool, error) {
+	switch {
+	case p.Index == nil:
+		return VerifyFileSHA1(path, sha1)
+	case p.DeepVerify:
+		return p.Index.DeepVerify(path, sha1)
+	default:
+		return p.Index.Verify(path, sha1)
+	}
 }
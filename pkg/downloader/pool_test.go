@@ -1,6 +1,7 @@
 package downloader
 
 import (
+	"NezordLauncher/pkg/network"
 	"bytes"
 	"context"
 	"crypto/sha1"
@@ -77,6 +78,11 @@ func TestWorkerPoolResume(t *testing.T) {
 	if _, err := os.Stat(part); !os.IsNotExist(err) {
 		t.Fatalf("part file should not exist after commit")
 	}
+
+	// The resumed prefix counts toward progress even though only the rest was streamed.
+	if _, _, current, _, _, _ := pool.Progress.GetMetrics(); current != int64(len(content)) {
+		t.Fatalf("expected %d processed bytes, got %d", len(content), current)
+	}
 }
 
 func TestWorkerPoolErrorHandling(t *testing.T) {
@@ -98,3 +104,119 @@ func TestWorkerPoolErrorHandling(t *testing.T) {
 		t.Fatalf("expected 1 error, got %d", len(pool.Errors()))
 	}
 }
+
+func TestWorkerPoolFallsBackOnMirrorHashMismatch(t *testing.T) {
+	content := []byte("genuine")
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write(content)
+	}))
+	defer upstream.Close()
+	mirror := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("tampered"))
+	}))
+	defer mirror.Close()
+
+	t.Cleanup(func() { network.SetMirrors(nil) })
+	network.SetMirrors([]network.MirrorRule{{Upstream: upstream.URL, Mirrors: []string{mirror.URL}}})
+
+	hasher := sha1.New()
+	hasher.Write(content)
+	hash := hex.EncodeToString(hasher.Sum(nil))
+	dest := filepath.Join(t.TempDir(), "file.jar")
+
+	pool := NewWorkerPool(1, 1)
+	pool.Progress.AddTotal(1, int64(len(content)))
+	pool.Start(context.Background())
+	pool.Submit(Task{URL: upstream.URL + "/file.jar", Path: dest, SHA1: hash, Size: int64(len(content))})
+	pool.Wait()
+
+	if errs := pool.Errors(); len(errs) != 0 {
+		t.Fatalf("expected fallback to upstream, got %v", errs)
+	}
+	if ok, _ := VerifyFileSHA1(dest, hash); !ok {
+		t.Fatalf("downloaded file has the mirror's content")
+	}
+	if got := network.Candidates(upstream.URL + "/other.jar"); got[0] != upstream.URL+"/other.jar" {
+		t.Errorf("mirror serving bad hashes should be tried last, got %v", got)
+	}
+}
+
+func TestWorkerPoolDropsPartialFileFromFailedMirror(t *testing.T) {
+	content := []byte("genuine content")
+	var ranged bool
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ranged = ranged || r.Header.Get("Range") != ""
+		http.ServeContent(w, r, "file.jar", time.Time{}, bytes.NewReader(content))
+	}))
+	defer upstream.Close()
+	// The mirror promises more than it sends, leaving a .part behind.
+	mirror := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Length", "100")
+		w.Write([]byte("bogus"))
+	}))
+	defer mirror.Close()
+
+	t.Cleanup(func() { network.SetMirrors(nil) })
+	network.SetMirrors([]network.MirrorRule{{Upstream: upstream.URL, Mirrors: []string{mirror.URL}}})
+
+	hasher := sha1.New()
+	hasher.Write(content)
+	hash := hex.EncodeToString(hasher.Sum(nil))
+	dest := filepath.Join(t.TempDir(), "file.jar")
+
+	pool := NewWorkerPool(1, 1)
+	pool.Progress.AddTotal(1, int64(len(content)))
+	pool.Start(context.Background())
+	pool.Submit(Task{URL: upstream.URL + "/file.jar", Path: dest, SHA1: hash, Size: int64(len(content))})
+	pool.Wait()
+
+	if errs := pool.Errors(); len(errs) != 0 {
+		t.Fatalf("expected fallback to upstream, got %v", errs)
+	}
+	if ranged {
+		t.Error("the mirror's partial file was resumed against the upstream")
+	}
+	if ok, _ := VerifyFileSHA1(dest, hash); !ok {
+		t.Error("downloaded file does not match")
+	}
+}
+
+func TestWorkerPoolReportsStreamingProgress(t *testing.T) {
+	content := bytes.Repeat([]byte("b"), 4096)
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write(content[:1024])
+		w.(http.Flusher).Flush()
+		<-release
+		w.Write(content[1024:])
+	}))
+	defer server.Close()
+
+	hasher := sha1.New()
+	hasher.Write(content)
+	hash := hex.EncodeToString(hasher.Sum(nil))
+
+	pool := NewWorkerPool(1, 1)
+	pool.Progress.AddTotal(1, int64(len(content)))
+	pool.Start(context.Background())
+	pool.Submit(Task{URL: server.URL, Path: filepath.Join(t.TempDir(), "big.jar"), SHA1: hash, Size: int64(len(content))})
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		completed, _, current, _, _, _ := pool.Progress.GetMetrics()
+		if completed == 0 && current == 1024 {
+			break
+		}
+		if time.Now().After(deadline) {
+			close(release)
+			t.Fatalf("expected 1024 streamed bytes before completion, got %d (completed %d)", current, completed)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	close(release)
+	pool.Wait()
+
+	if completed, _, current, _, _, _ := pool.Progress.GetMetrics(); completed != 1 || current != int64(len(content)) {
+		t.Fatalf("expected 1 file and %d bytes, got %d and %d", len(content), completed, current)
+	}
+}
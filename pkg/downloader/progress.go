@@ -31,6 +31,22 @@ func (p *DownloadProgress) Increment(size int64, network int64) {
 	p.NetworkBytes += network
 }
 
+// AddStreamed counts bytes of a file that is still downloading.
+func (p *DownloadProgress) AddStreamed(n int64) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.CurrentBytes += n
+	p.NetworkBytes += n
+}
+
+// Rewind takes a failed download's streamed bytes back out of the processed total; they still
+// count as network traffic.
+func (p *DownloadProgress) Rewind(n int64) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.CurrentBytes -= n
+}
+
 func (p *DownloadProgress) AddTotal(count int, size int64) {
 	p.mu.Lock()
 	defer p.mu.Unlock()
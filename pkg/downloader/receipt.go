@@ -0,0 +1,161 @@
+package downloader
+
+import (
+	"NezordLauncher/pkg/constants"
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+const receiptFormat = 1
+
+// Receipt records a completed install of a version so later launches can confirm it by
+// comparing hashes of the version metadata and stat data of the artifacts.
+type Receipt struct {
+	Format    int    `json:"format"`
+	VersionID string `json:"versionId"`
+	// Versions maps each version JSON in the inheritance chain to its SHA1.
+	Versions       map[string]string `json:"versions"`
+	AssetIndex     string            `json:"assetIndex,omitempty"`
+	AssetIndexSHA1 string            `json:"assetIndexSha1,omitempty"`
+	Files          []ReceiptFile     `json:"files"`
+	InstalledAt    time.Time         `json:"installedAt"`
+}
+
+// ReceiptFile is an artifact as it was on disk after the install. Paths are relative to the
+// data directory.
+type ReceiptFile struct {
+	Path    string `json:"path"`
+	Size    int64  `json:"size"`
+	ModTime int64  `json:"mtime"`
+}
+
+func ReceiptPath(versionID string) string {
+	return filepath.Join(constants.GetVersionsDir(), versionID, "install-receipt.json")
+}
+
+func versionJSONPath(versionID string) string {
+	return filepath.Join(constants.GetVersionsDir(), versionID, versionID+".json")
+}
+
+// WriteReceipt stores a receipt for the version this fetcher downloaded. Call it only after the
+// pool finished without errors; filtered fetches cover part of the version and write nothing.
+func (f *ArtifactFetcher) WriteReceipt(versionID string) error {
+	if f.Filter != nil || len(f.chain) == 0 {
+		return nil
+	}
+
+	r := Receipt{
+		Format:      receiptFormat,
+		VersionID:   versionID,
+		Versions:    make(map[string]string, len(f.chain)),
+		InstalledAt: time.Now().UTC(),
+	}
+	for _, id := range f.chain {
+		sum, err := fileSHA1(versionJSONPath(id))
+		if err != nil {
+			return fmt.Errorf("failed to hash version %s: %w", id, err)
+		}
+		r.Versions[id] = sum
+	}
+
+	dataDir := constants.GetDataDir()
+	if f.assetIndexPath != "" {
+		sum, err := fileSHA1(f.assetIndexPath)
+		if err != nil {
+			return fmt.Errorf("failed to hash asset index: %w", err)
+		}
+		r.AssetIndex = relativePath(dataDir, f.assetIndexPath)
+		r.AssetIndexSHA1 = sum
+	}
+
+	seen := make(map[string]struct{}, len(f.planned))
+	for _, t := range f.planned {
+		if _, ok := seen[t.Path]; ok {
+			continue
+		}
+		seen[t.Path] = struct{}{}
+
+		info, err := os.Stat(t.Path)
+		if err != nil {
+			return fmt.Errorf("artifact missing after install: %w", err)
+		}
+		r.Files = append(r.Files, ReceiptFile{
+			Path:    relativePath(dataDir, t.Path),
+			Size:    info.Size(),
+			ModTime: info.ModTime().UnixNano(),
+		})
+	}
+
+	data, err := json.Marshal(r)
+	if err != nil {
+		return fmt.Errorf("failed to encode install receipt: %w", err)
+	}
+	if err := AtomicWriteFile(ReceiptPath(versionID), data); err != nil {
+		return fmt.Errorf("failed to write install receipt: %w", err)
+	}
+	return nil
+}
+
+// CheckReceipt returns nil when the version's receipt is still accurate: the version JSONs and
+// asset index hash the same and every artifact has the recorded size and mtime. Artifacts are
+// not hashed.
+func CheckReceipt(versionID string) error {
+	data, err := os.ReadFile(ReceiptPath(versionID))
+	if err != nil {
+		return err
+	}
+	var r Receipt
+	if err := json.Unmarshal(data, &r); err != nil {
+		return fmt.Errorf("invalid install receipt: %w", err)
+	}
+	if r.Format != receiptFormat || r.VersionID != versionID || len(r.Versions) == 0 {
+		return fmt.Errorf("install receipt does not describe %s", versionID)
+	}
+
+	for id, want := range r.Versions {
+		sum, err := fileSHA1(versionJSONPath(id))
+		if err != nil || sum != want {
+			return fmt.Errorf("version %s changed since install", id)
+		}
+	}
+
+	dataDir := constants.GetDataDir()
+	if r.AssetIndex != "" {
+		sum, err := fileSHA1(filepath.Join(dataDir, r.AssetIndex))
+		if err != nil || sum != r.AssetIndexSHA1 {
+			return fmt.Errorf("asset index changed since install")
+		}
+	}
+
+	for _, file := range r.Files {
+		info, err := os.Stat(filepath.Join(dataDir, file.Path))
+		if err != nil {
+			return fmt.Errorf("%s is missing", file.Path)
+		}
+		if info.Size() != file.Size || info.ModTime().UnixNano() != file.ModTime {
+			return fmt.Errorf("%s changed since install", file.Path)
+		}
+	}
+	return nil
+}
+
+func fileSHA1(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	sum := sha1.Sum(data)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+func relativePath(base, path string) string {
+	if rel, err := filepath.Rel(base, path); err == nil {
+		return filepath.ToSlash(rel)
+	}
+	return filepath.ToSlash(path)
+}
@@ -0,0 +1,70 @@
+package downloader
+
+import (
+	"NezordLauncher/pkg/constants"
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestInstallReceipt(t *testing.T) {
+	t.Setenv("NEZORD_DATA_DIR", t.TempDir())
+
+	client := []byte("client-jar")
+	sum := sha1.Sum(client)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write(client)
+	}))
+	defer server.Close()
+
+	versionJSON := fmt.Sprintf(`{"id":"1.0","mainClass":"Main","downloads":{"client":{"url":%q,"sha1":%q,"size":%d}}}`,
+		server.URL+"/client.jar", hex.EncodeToString(sum[:]), len(client))
+	if err := AtomicWriteFile(versionJSONPath("1.0"), []byte(versionJSON)); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := CheckReceipt("1.0"); !os.IsNotExist(err) {
+		t.Fatalf("expected missing receipt, got %v", err)
+	}
+
+	pool := NewWorkerPool(1, 4)
+	pool.Start(context.Background())
+	fetcher := NewArtifactFetcher(pool)
+	if err := fetcher.DownloadVersion(context.Background(), "1.0"); err != nil {
+		t.Fatalf("DownloadVersion failed: %v", err)
+	}
+	pool.Wait()
+	if err := fetcher.WriteReceipt("1.0"); err != nil {
+		t.Fatalf("WriteReceipt failed: %v", err)
+	}
+
+	if err := CheckReceipt("1.0"); err != nil {
+		t.Fatalf("expected fresh receipt, got %v", err)
+	}
+
+	jar := filepath.Join(constants.GetVersionsDir(), "1.0", "1.0.jar")
+	later := time.Now().Add(time.Hour)
+	if err := os.Chtimes(jar, later, later); err != nil {
+		t.Fatal(err)
+	}
+	if err := CheckReceipt("1.0"); err == nil {
+		t.Fatalf("expected touched artifact to invalidate the receipt")
+	}
+
+	if err := fetcher.WriteReceipt("1.0"); err != nil {
+		t.Fatalf("WriteReceipt failed: %v", err)
+	}
+	if err := AtomicWriteFile(versionJSONPath("1.0"), []byte(versionJSON+" ")); err != nil {
+		t.Fatal(err)
+	}
+	if err := CheckReceipt("1.0"); err == nil {
+		t.Fatalf("expected changed version JSON to invalidate the receipt")
+	}
+}
@@ -4,27 +4,98 @@ import (
 	"NezordLauncher/pkg/network"
 	"encoding/json"
 	"fmt"
+	"strings"
 )
 
 const MetaURL = "https://meta.fabricmc.net"
 
+// Meta describes a Fabric-compatible metadata service. Fabric and its forks share the same
+// loader and installer layout and differ only in endpoints and naming.
+type Meta struct {
+	Name     string
+	URL      string
+	API      string
+	MavenURL string
+	// TaggedPrereleases marks services whose loader listing has no stable flag; their
+	// pre-releases carry a version tag such as -beta.1 instead.
+	TaggedPrereleases bool
+}
+
+var Fabric = Meta{
+	Name:     "fabric",
+	URL:      MetaURL,
+	API:      "v2",
+	MavenURL: "https://maven.fabricmc.net/",
+}
+
+// LegacyFabric ports the Fabric loader to 1.3 through 1.13.2 with its own intermediary mappings.
+var LegacyFabric = Meta{
+	Name:     "legacyfabric",
+	URL:      "https://meta.legacyfabric.net",
+	API:      "v2",
+	MavenURL: "https://maven.legacyfabric.net/",
+}
+
+// Babric is the Fabric loader for Beta 1.7.3.
+var Babric = Meta{
+	Name:     "babric",
+	URL:      "https://meta.babric.glass-launcher.net",
+	API:      "v2",
+	MavenURL: "https://maven.glass-launcher.net/babric/",
+}
+
 func GetLoaderVersions(gameVersion string) ([]LoaderVersion, error) {
+	return Fabric.LoaderVersions(gameVersion)
+}
+
+func (m Meta) LoaderVersions(gameVersion string) ([]LoaderVersion, error) {
 	client := network.NewHttpClient()
-	url := fmt.Sprintf("%s/v2/versions/loader/%s", MetaURL, gameVersion)
+	url := fmt.Sprintf("%s/%s/versions/loader/%s", m.URL, m.API, gameVersion)
 
-	data, err := client.Get(url)
+	data, err := client.GetCached(url, network.MetaTTL)
 	if err != nil {
-		return nil, fmt.Errorf("failed to fetch fabric versions: %w", err)
+		return nil, fmt.Errorf("failed to fetch %s versions: %w", m.Name, err)
 	}
 
 	var versions []LoaderVersion
 	if err := json.Unmarshal(data, &versions); err != nil {
-		return nil, fmt.Errorf("failed to parse fabric versions: %w", err)
+		network.InvalidateCached(url)
+		return nil, fmt.Errorf("failed to parse %s versions: %w", m.Name, err)
 	}
 
 	if len(versions) == 0 {
-		return nil, fmt.Errorf("no fabric loader versions found for %s", gameVersion)
+		return nil, fmt.Errorf("no %s loader versions found for %s", m.Name, gameVersion)
 	}
 
 	return versions, nil
 }
+
+// GameVersions lists the game versions the loader supports, newest first.
+func (m Meta) GameVersions() ([]GameVersion, error) {
+	client := network.NewHttpClient()
+	url := fmt.Sprintf("%s/%s/versions/game", m.URL, m.API)
+
+	data, err := client.GetCached(url, network.MetaTTL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch %s game versions: %w", m.Name, err)
+	}
+
+	var versions []GameVersion
+	if err := json.Unmarshal(data, &versions); err != nil {
+		network.InvalidateCached(url)
+		return nil, fmt.Errorf("failed to parse %s game versions: %w", m.Name, err)
+	}
+	return versions, nil
+}
+
+// IsStable reports whether a loader build is a stable release.
+func (m Meta) IsStable(l Loader) bool {
+	if m.TaggedPrereleases {
+		return !strings.Contains(l.Version, "-")
+	}
+	return l.Stable
+}
+
+func (m Meta) VersionID(gameVersion, loaderVersion string) string {
+	return fmt.Sprintf("%s-loader-%s-%s", m.Name, loaderVersion, gameVersion)
+}
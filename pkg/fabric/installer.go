@@ -2,86 +2,230 @@ package fabric
 
 import (
 	"NezordLauncher/pkg/constants"
+	"NezordLauncher/pkg/downloader"
 	"NezordLauncher/pkg/models"
+	"NezordLauncher/pkg/network"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"net/http"
 	"os"
 	"path/filepath"
+	"regexp"
+	"strings"
 )
 
 func InstallFabric(gameVersion string, loaderVersion string) (string, error) {
-	versions, err := GetLoaderVersions(gameVersion)
-	if err != nil {
-		return "", err
-	}
+	return Fabric.Install(gameVersion, loaderVersion)
+}
 
-	var target *LoaderVersion
+// Install writes the loader's official launcher profile as a version JSON, with every library
+// pinned to a SHA1 so the downloader can verify it.
+func (m Meta) Install(gameVersion string, loaderVersion string) (string, error) {
 	if loaderVersion == "latest" || loaderVersion == "" {
-		if len(versions) > 0 {
-			target = &versions[0]
+		versions, err := m.LoaderVersions(gameVersion)
+		if err != nil {
+			return "", err
 		}
-	} else {
-		for _, v := range versions {
-			if v.Loader.Version == loaderVersion {
-				vCopy := v
-				target = &vCopy
-				break
-			}
-		}
-	}
-
-	if target == nil {
-		return "", fmt.Errorf("fabric loader %s not found for game %s", loaderVersion, gameVersion)
+		loaderVersion = versions[0].Loader.Version
 	}
 
-	versionID := fmt.Sprintf("fabric-loader-%s-%s", target.Loader.Version, gameVersion)
+	versionID := m.VersionID(gameVersion, loaderVersion)
+	versionDir := filepath.Join(constants.GetVersionsDir(), versionID)
+	jsonPath := filepath.Join(versionDir, fmt.Sprintf("%s.json", versionID))
 
-	var libraries []models.Library
+	// The profile of a pinned loader version never changes, so an installed copy is enough.
+	// Files written before libraries were pinned are reinstalled to pick up their hashes.
+	if isPinnedVersionFile(jsonPath, versionID) {
+		return versionID, nil
+	}
 
-	libraries = append(libraries, models.Library{
-		Name: target.Loader.Maven,
-		URL:  "https://maven.fabricmc.net/",
-	})
+	client := network.NewHttpClient()
+	url := fmt.Sprintf("%s/%s/versions/loader/%s/%s/profile/json", m.URL, m.API, gameVersion, loaderVersion)
+	data, err := client.Get(url)
+	if err != nil {
+		return "", fmt.Errorf("%s loader %s not found for game %s: %w", m.Name, loaderVersion, gameVersion, err)
+	}
 
-	libraries = append(libraries, models.Library{
-		Name: target.Intermediary.Maven,
-		URL:  "https://maven.fabricmc.net/",
-	})
+	var profile map[string]json.RawMessage
+	if err := json.Unmarshal(data, &profile); err != nil {
+		return "", fmt.Errorf("failed to parse %s profile: %w", m.Name, err)
+	}
 
-	for _, libs := range target.LauncherMeta.Libraries {
-		for _, lib := range libs {
-			libraries = append(libraries, models.Library{
-				Name: lib.Name,
-				URL:  lib.URL,
-			})
+	var libraries []ProfileLibrary
+	if raw, ok := profile["libraries"]; ok {
+		if err := json.Unmarshal(raw, &libraries); err != nil {
+			return "", fmt.Errorf("failed to parse %s profile libraries: %w", m.Name, err)
 		}
 	}
 
-	versionJSON := models.VersionDetail{
-		ID:           versionID,
-		InheritsFrom: gameVersion,
-		Jar:          gameVersion,
-		Type:         "release",
-		MainClass:    target.LauncherMeta.MainClass,
-		Libraries:    libraries,
+	pinned := make([]models.Library, 0, len(libraries))
+	for _, lib := range libraries {
+		resolved, err := m.pinLibrary(client, lib)
+		if err != nil {
+			return "", err
+		}
+		pinned = append(pinned, resolved)
 	}
 
-	versionsDir := constants.GetVersionsDir()
-	versionDir := filepath.Join(versionsDir, versionID)
-	jsonPath := filepath.Join(versionDir, fmt.Sprintf("%s.json", versionID))
+	if profile["id"], err = json.Marshal(versionID); err != nil {
+		return "", err
+	}
+	if profile["inheritsFrom"], err = json.Marshal(gameVersion); err != nil {
+		return "", err
+	}
+	if profile["libraries"], err = json.Marshal(pinned); err != nil {
+		return "", err
+	}
 
 	if err := os.MkdirAll(versionDir, 0755); err != nil {
 		return "", fmt.Errorf("failed to create version directory: %w", err)
 	}
 
-	data, err := json.MarshalIndent(versionJSON, "", "  ")
+	out, err := json.MarshalIndent(profile, "", "  ")
 	if err != nil {
 		return "", fmt.Errorf("failed to marshal version json: %w", err)
 	}
 
-	if err := os.WriteFile(jsonPath, data, 0644); err != nil {
+	if err := os.WriteFile(jsonPath, out, 0644); err != nil {
 		return "", fmt.Errorf("failed to write version json: %w", err)
 	}
 
 	return versionID, nil
 }
+
+// isPinnedVersionFile reports whether jsonPath is an installed version JSON whose libraries
+// all carry a SHA1.
+func isPinnedVersionFile(jsonPath, versionID string) bool {
+	if !models.IsValidVersionFile(jsonPath, versionID) {
+		return false
+	}
+	data, err := os.ReadFile(jsonPath)
+	if err != nil {
+		return false
+	}
+	var detail models.VersionDetail
+	if err := json.Unmarshal(data, &detail); err != nil {
+		return false
+	}
+	for _, lib := range detail.Libraries {
+		if !sha1Pattern.MatchString(lib.Downloads.Artifact.SHA1) {
+			return false
+		}
+	}
+	return true
+}
+
+// pinLibrary turns a maven-style profile library into a downloadable artifact with a SHA1.
+// Libraries without a declared hash get one from the maven .sha1 sidecar, or from the .sha256
+// sidecar by downloading and verifying the artifact once. A library with no hash at all fails.
+func (m Meta) pinLibrary(client *network.HttpClient, lib ProfileLibrary) (models.Library, error) {
+	path := models.MavenPath(lib.Name)
+	if path == "" {
+		return models.Library{}, fmt.Errorf("invalid %s library name %q", m.Name, lib.Name)
+	}
+
+	baseURL := lib.URL
+	if baseURL == "" {
+		baseURL = m.MavenURL
+	}
+	artifactURL := strings.TrimSuffix(baseURL, "/") + "/" + path
+
+	sha1Sum := strings.ToLower(lib.SHA1)
+	if sha1Sum != "" && !sha1Pattern.MatchString(sha1Sum) {
+		return models.Library{}, fmt.Errorf("invalid sha1 %q for %s library %s", lib.SHA1, m.Name, lib.Name)
+	}
+	if sha1Sum == "" {
+		sum, err := fetchSidecar(client, artifactURL+".sha1", sha1Pattern)
+		if err != nil {
+			return models.Library{}, fmt.Errorf("failed to fetch sha1 of %s: %w", lib.Name, err)
+		}
+		sha1Sum = sum
+	}
+	if sha1Sum == "" {
+		sha256Sum := strings.ToLower(lib.SHA256)
+		if sha256Sum != "" && !sha256Pattern.MatchString(sha256Sum) {
+			return models.Library{}, fmt.Errorf("invalid sha256 %q for %s library %s", lib.SHA256, m.Name, lib.Name)
+		}
+		if sha256Sum == "" {
+			sum, err := fetchSidecar(client, artifactURL+".sha256", sha256Pattern)
+			if err != nil {
+				return models.Library{}, fmt.Errorf("failed to fetch sha256 of %s: %w", lib.Name, err)
+			}
+			sha256Sum = sum
+		}
+		if sha256Sum == "" {
+			return models.Library{}, fmt.Errorf("no hash published for %s library %s", m.Name, lib.Name)
+		}
+		sum, err := verifyBySHA256(client, artifactURL, filepath.Join(constants.GetLibrariesDir(), path), sha256Sum)
+		if err != nil {
+			return models.Library{}, fmt.Errorf("failed to verify %s: %w", lib.Name, err)
+		}
+		sha1Sum = sum
+	}
+
+	return models.Library{
+		Name: lib.Name,
+		URL:  baseURL,
+		Downloads: models.LibraryDownloadMap{
+			Artifact: models.DownloadInfo{
+				Path: path,
+				URL:  artifactURL,
+				SHA1: sha1Sum,
+				Size: lib.Size,
+			},
+		},
+	}, nil
+}
+
+var (
+	sha1Pattern   = regexp.MustCompile(`^[0-9a-f]{40}$`)
+	sha256Pattern = regexp.MustCompile(`^[0-9a-f]{64}$`)
+)
+
+// fetchSidecar returns the hash published next to a maven artifact, or "" when the repository
+// has none. Anything else that is not a well-formed hash, such as an error page, is an error.
+func fetchSidecar(client *network.HttpClient, url string, pattern *regexp.Regexp) (string, error) {
+	data, err := client.Get(url)
+	var statusErr *network.StatusError
+	if errors.As(err, &statusErr) && statusErr.StatusCode == http.StatusNotFound {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	fields := strings.Fields(string(data))
+	if len(fields) == 0 || !pattern.MatchString(strings.ToLower(fields[0])) {
+		return "", fmt.Errorf("malformed hash file %s", url)
+	}
+	return strings.ToLower(fields[0]), nil
+}
+
+// verifyBySHA256 makes sure the artifact on disk matches sha256Sum, downloading it if needed,
+// and returns its SHA1.
+func verifyBySHA256(client *network.HttpClient, url, path, sha256Sum string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil || !matchesSHA256(data, sha256Sum) {
+		data, err = client.Get(url)
+		if err != nil {
+			return "", err
+		}
+		if !matchesSHA256(data, sha256Sum) {
+			return "", fmt.Errorf("sha256 mismatch")
+		}
+		if err := downloader.AtomicWriteFile(path, data); err != nil {
+			return "", err
+		}
+	}
+
+	sum := sha1.Sum(data)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+func matchesSHA256(data []byte, expected string) bool {
+	sum := sha256.Sum256(data)
+	return strings.EqualFold(hex.EncodeToString(sum[:]), expected)
+}
@@ -3,9 +3,16 @@ package fabric
 import (
 	"NezordLauncher/pkg/constants"
 	"NezordLauncher/pkg/models"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 )
 
@@ -61,3 +68,232 @@ func TestInstallFabric(t *testing.T) {
 		t.Error("Fabric Maven URL not found in libraries")
 	}
 }
+
+func TestInstallFromProfileJSON(t *testing.T) {
+	t.Setenv("NEZORD_DATA_DIR", t.TempDir())
+
+	loaderJar := []byte("loader jar")
+	asmJar := []byte("asm jar")
+	asmSHA256 := sha256.Sum256(asmJar)
+	loaderSHA1 := sha1.Sum(loaderJar)
+	intermediarySHA1 := strings.Repeat("ab", 20)
+
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v2/versions/loader/1.20.1/0.15.0/profile/json":
+			fmt.Fprintf(w, `{
+				"id": "fabric-loader-0.15.0-1.20.1",
+				"inheritsFrom": "1.20.1",
+				"mainClass": "net.fabricmc.loader.impl.launch.knot.KnotClient",
+				"arguments": {"game": [], "jvm": ["-DFabricMcEmu= net.minecraft.client.main.Main "]},
+				"libraries": [
+					{"name": "net.fabricmc:fabric-loader:0.15.0", "url": "%[1]s/maven/"},
+					{"name": "org.ow2.asm:asm:9.6", "url": "%[1]s/maven/"},
+					{"name": "net.fabricmc:intermediary:1.20.1", "url": "%[1]s/maven/", "sha1": "%[2]s"}
+				]
+			}`, server.URL, strings.ToUpper(intermediarySHA1))
+		case "/maven/net/fabricmc/fabric-loader/0.15.0/fabric-loader-0.15.0.jar.sha1":
+			w.Write([]byte(hex.EncodeToString(loaderSHA1[:]) + "  fabric-loader-0.15.0.jar\n"))
+		case "/maven/org/ow2/asm/asm/9.6/asm-9.6.jar.sha256":
+			w.Write([]byte(hex.EncodeToString(asmSHA256[:])))
+		case "/maven/org/ow2/asm/asm/9.6/asm-9.6.jar":
+			w.Write(asmJar)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	meta := Meta{Name: "fabric", URL: server.URL, API: "v2", MavenURL: server.URL + "/maven/"}
+	id, err := meta.Install("1.20.1", "0.15.0")
+	if err != nil {
+		t.Fatalf("Install failed: %v", err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(constants.GetVersionsDir(), id, id+".json"))
+	if err != nil {
+		t.Fatalf("version json not written: %v", err)
+	}
+	var version models.VersionDetail
+	if err := json.Unmarshal(data, &version); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(version.Arguments.JVM) != 1 {
+		t.Errorf("profile arguments were not preserved: %+v", version.Arguments)
+	}
+
+	asmSHA1 := sha1.Sum(asmJar)
+	want := map[string]string{
+		"net.fabricmc:fabric-loader:0.15.0": hex.EncodeToString(loaderSHA1[:]),
+		"org.ow2.asm:asm:9.6":               hex.EncodeToString(asmSHA1[:]),
+		"net.fabricmc:intermediary:1.20.1":  intermediarySHA1,
+	}
+	for _, lib := range version.Libraries {
+		if lib.Downloads.Artifact.SHA1 != want[lib.Name] {
+			t.Errorf("%s: sha1 %q, want %q", lib.Name, lib.Downloads.Artifact.SHA1, want[lib.Name])
+		}
+		if lib.Downloads.Artifact.URL != server.URL+"/maven/"+lib.Downloads.Artifact.Path {
+			t.Errorf("%s: unexpected url %s", lib.Name, lib.Downloads.Artifact.URL)
+		}
+	}
+}
+
+func TestInstallRejectsSHA256Mismatch(t *testing.T) {
+	t.Setenv("NEZORD_DATA_DIR", t.TempDir())
+
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v3/versions/loader/1.20.1/0.23.0/profile/json":
+			fmt.Fprintf(w, `{"libraries": [{"name": "org.quiltmc:quilt-loader:0.23.0", "url": "%s/maven/"}]}`, server.URL)
+		case "/maven/org/quiltmc/quilt-loader/0.23.0/quilt-loader-0.23.0.jar.sha256":
+			w.Write([]byte(strings.Repeat("0", 64)))
+		case "/maven/org/quiltmc/quilt-loader/0.23.0/quilt-loader-0.23.0.jar":
+			w.Write([]byte("corrupt"))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	meta := Meta{Name: "quilt", URL: server.URL, API: "v3"}
+	if _, err := meta.Install("1.20.1", "0.23.0"); err == nil || !strings.Contains(err.Error(), "sha256 mismatch") {
+		t.Fatalf("expected sha256 mismatch, got %v", err)
+	}
+}
+
+func TestInstallRejectsBadSidecars(t *testing.T) {
+	tests := []struct {
+		name    string
+		sidecar http.HandlerFunc
+		want    string
+	}{
+		{"error page", func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte("<html><body>Service unavailable</body></html>"))
+		}, "malformed hash file"},
+		{"short hash", func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte("abc"))
+		}, "malformed hash file"},
+		{"request failure", func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusForbidden)
+		}, "status: 403"},
+		{"no hash published", http.NotFound, "no hash published"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("NEZORD_DATA_DIR", t.TempDir())
+
+			var server *httptest.Server
+			server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				switch {
+				case r.URL.Path == "/v2/versions/loader/1.20.1/0.15.0/profile/json":
+					fmt.Fprintf(w, `{"libraries": [{"name": "net.fabricmc:fabric-loader:0.15.0", "url": "%s/maven/"}]}`, server.URL)
+				case strings.HasSuffix(r.URL.Path, ".sha1") || strings.HasSuffix(r.URL.Path, ".sha256"):
+					tt.sidecar(w, r)
+				default:
+					http.NotFound(w, r)
+				}
+			}))
+			defer server.Close()
+
+			meta := Meta{Name: "fabric", URL: server.URL, API: "v2"}
+			if _, err := meta.Install("1.20.1", "0.15.0"); err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("expected error containing %q, got %v", tt.want, err)
+			}
+			if _, err := os.Stat(filepath.Join(constants.GetVersionsDir(), "fabric-loader-0.15.0-1.20.1")); !os.IsNotExist(err) {
+				t.Error("no version JSON should be written when hashes cannot be pinned")
+			}
+		})
+	}
+}
+
+func TestInstallWorksOffline(t *testing.T) {
+	t.Setenv("NEZORD_DATA_DIR", t.TempDir())
+
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v2/versions/loader/1.20.1":
+			w.Write([]byte(`[{"loader":{"version":"0.15.0"}}]`))
+		case "/v2/versions/loader/1.20.1/0.15.0/profile/json":
+			fmt.Fprintf(w, `{"mainClass": "net.fabricmc.loader.impl.launch.knot.KnotClient",
+				"libraries": [{"name": "net.fabricmc:fabric-loader:0.15.0", "url": "%s/maven/", "sha1": "%s"}]}`, server.URL, strings.Repeat("0", 40))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+
+	meta := Meta{Name: "fabric", URL: server.URL, API: "v2"}
+	id, err := meta.Install("1.20.1", "latest")
+	if err != nil {
+		t.Fatalf("Install failed: %v", err)
+	}
+	server.Close()
+
+	for _, loader := range []string{"0.15.0", "latest"} {
+		got, err := meta.Install("1.20.1", loader)
+		if err != nil || got != id {
+			t.Errorf("offline install of %s: got %q (%v), want %s", loader, got, err, id)
+		}
+	}
+}
+
+func TestInstallRepinsLegacyVersionFile(t *testing.T) {
+	t.Setenv("NEZORD_DATA_DIR", t.TempDir())
+
+	loaderJar := []byte("loader jar")
+	loaderSHA1 := sha1.Sum(loaderJar)
+
+	var profileRequests int
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v2/versions/loader/1.20.1/0.15.0/profile/json":
+			profileRequests++
+			fmt.Fprintf(w, `{"mainClass": "net.fabricmc.loader.impl.launch.knot.KnotClient",
+				"libraries": [{"name": "net.fabricmc:fabric-loader:0.15.0", "url": "%s/maven/"}]}`, server.URL)
+		case "/maven/net/fabricmc/fabric-loader/0.15.0/fabric-loader-0.15.0.jar.sha1":
+			w.Write([]byte(hex.EncodeToString(loaderSHA1[:])))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	// The hand-built installer wrote maven-style libraries without downloads or hashes.
+	id := "fabric-loader-0.15.0-1.20.1"
+	jsonPath := filepath.Join(constants.GetVersionsDir(), id, id+".json")
+	legacy := fmt.Sprintf(`{"id": %q, "inheritsFrom": "1.20.1", "mainClass": "net.fabricmc.loader.impl.launch.knot.KnotClient",
+		"libraries": [{"name": "net.fabricmc:fabric-loader:0.15.0", "url": "https://maven.fabricmc.net/"}]}`, id)
+	if err := os.MkdirAll(filepath.Dir(jsonPath), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(jsonPath, []byte(legacy), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	meta := Meta{Name: "fabric", URL: server.URL, API: "v2"}
+	for i := 0; i < 2; i++ {
+		if _, err := meta.Install("1.20.1", "0.15.0"); err != nil {
+			t.Fatalf("Install failed: %v", err)
+		}
+	}
+	if profileRequests != 1 {
+		t.Errorf("expected the legacy file to be reinstalled once, got %d profile requests", profileRequests)
+	}
+
+	data, err := os.ReadFile(jsonPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var version models.VersionDetail
+	if err := json.Unmarshal(data, &version); err != nil {
+		t.Fatal(err)
+	}
+	if len(version.Libraries) != 1 || version.Libraries[0].Downloads.Artifact.SHA1 != hex.EncodeToString(loaderSHA1[:]) {
+		t.Errorf("libraries were not pinned: %+v", version.Libraries)
+	}
+}
@@ -34,3 +34,17 @@ type Library struct {
 	Name string `json:"name"`
 	URL  string `json:"url"`
 }
+
+type GameVersion struct {
+	Version string `json:"version"`
+	Stable  bool   `json:"stable"`
+}
+
+// ProfileLibrary is a library entry of a loader's launcher profile JSON.
+type ProfileLibrary struct {
+	Name   string `json:"name"`
+	URL    string `json:"url"`
+	SHA1   string `json:"sha1,omitempty"`
+	SHA256 string `json:"sha256,omitempty"`
+	Size   int    `json:"size,omitempty"`
+}
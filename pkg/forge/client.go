@@ -0,0 +1,140 @@
+package forge
+
+import (
+	"NezordLauncher/pkg/network"
+	"encoding/xml"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const MavenURL = "https://maven.minecraftforge.net/"
+
+// mavenURL returns the Forge maven root, overridable via NEZORD_FORGE_MAVEN_URL.
+func mavenURL() string {
+	if override := os.Getenv("NEZORD_FORGE_MAVEN_URL"); override != "" {
+		return strings.TrimSuffix(override, "/") + "/"
+	}
+	return MavenURL
+}
+
+// rewriteMavenURL points library URLs at the configured maven when it has been overridden.
+func rewriteMavenURL(url, defaultRoot, root string) string {
+	if root != defaultRoot && strings.HasPrefix(url, defaultRoot) {
+		return root + strings.TrimPrefix(url, defaultRoot)
+	}
+	return url
+}
+
+// GetForgeVersions lists the Forge versions for a game version, newest first, without the game prefix.
+func GetForgeVersions(gameVersion string) ([]string, error) {
+	all, err := fetchMavenVersions(mavenURL() + "net/minecraftforge/forge/maven-metadata.xml")
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch forge versions: %w", err)
+	}
+
+	prefix := gameVersion + "-"
+	var versions []string
+	for _, v := range all {
+		if strings.HasPrefix(v, prefix) {
+			versions = append(versions, strings.TrimPrefix(v, prefix))
+		}
+	}
+
+	if len(versions) == 0 {
+		return nil, fmt.Errorf("no forge versions found for %s", gameVersion)
+	}
+
+	SortVersionsDesc(versions)
+	return versions, nil
+}
+
+// GetGameVersions lists the game versions Forge has builds for, newest first.
+func GetGameVersions() ([]string, error) {
+	all, err := fetchMavenVersions(mavenURL() + "net/minecraftforge/forge/maven-metadata.xml")
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch forge versions: %w", err)
+	}
+
+	seen := make(map[string]bool)
+	var versions []string
+	for _, v := range all {
+		game, _, ok := strings.Cut(v, "-")
+		if ok && !seen[game] {
+			seen[game] = true
+			versions = append(versions, game)
+		}
+	}
+
+	SortVersionsDesc(versions)
+	return versions, nil
+}
+
+func fetchMavenVersions(url string) ([]string, error) {
+	client := network.NewHttpClient()
+	data, err := client.GetCached(url, network.MetaTTL)
+	if err != nil {
+		return nil, err
+	}
+
+	var meta mavenMetadata
+	if err := xml.Unmarshal(data, &meta); err != nil {
+		network.InvalidateCached(url)
+		return nil, fmt.Errorf("failed to parse maven metadata: %w", err)
+	}
+	return meta.Versioning.Versions, nil
+}
+
+// SortVersionsDesc orders dotted loader versions from newest to oldest.
+func SortVersionsDesc(versions []string) {
+	sort.SliceStable(versions, func(i, j int) bool {
+		return CompareVersions(versions[i], versions[j]) > 0
+	})
+}
+
+// CompareVersions compares dotted versions numerically where possible, e.g. 47.10.0 > 47.9.1.
+func CompareVersions(a, b string) int {
+	split := func(s string) []string {
+		return strings.FieldsFunc(s, func(r rune) bool { return r == '.' || r == '-' || r == '+' })
+	}
+	pa, pb := split(a), split(b)
+
+	for i := 0; i < len(pa) && i < len(pb); i++ {
+		na, errA := strconv.Atoi(pa[i])
+		nb, errB := strconv.Atoi(pb[i])
+		switch {
+		case errA == nil && errB == nil:
+			if na != nb {
+				if na > nb {
+					return 1
+				}
+				return -1
+			}
+		case errA == nil:
+			return 1
+		case errB == nil:
+			return -1
+		default:
+			if c := strings.Compare(pa[i], pb[i]); c != 0 {
+				return c
+			}
+		}
+	}
+
+	// A trailing tag such as "beta" marks a pre-release of the shorter version.
+	switch {
+	case len(pa) > len(pb):
+		if _, err := strconv.Atoi(pa[len(pb)]); err != nil {
+			return -1
+		}
+		return 1
+	case len(pa) < len(pb):
+		if _, err := strconv.Atoi(pb[len(pa)]); err != nil {
+			return 1
+		}
+		return -1
+	}
+	return 0
+}
@@ -0,0 +1,284 @@
+package forge
+
+import (
+	"NezordLauncher/pkg/constants"
+	"NezordLauncher/pkg/downloader"
+	"NezordLauncher/pkg/models"
+	"NezordLauncher/pkg/network"
+	"NezordLauncher/pkg/system"
+	"archive/zip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+var ErrLegacyInstaller = errors.New("installer uses the pre-1.13 format")
+
+type InstallOptions struct {
+	// GameVersion, when set, must match the installer's Minecraft version.
+	GameVersion string
+	// JavaPath runs the installer processors.
+	JavaPath string
+	// Mirrors maps default maven roots to the roots libraries are actually fetched from.
+	Mirrors  map[string]string
+	OnStatus func(string)
+}
+
+func (o InstallOptions) status(message string) {
+	if o.OnStatus != nil {
+		o.OnStatus(message)
+	}
+}
+
+func (o InstallOptions) rewrite(url string) string {
+	for defaultRoot, root := range o.Mirrors {
+		url = rewriteMavenURL(url, defaultRoot, root)
+	}
+	return url
+}
+
+// InstallForge installs a Forge version and returns its version ID. opts.JavaPath runs the
+// installer processors; GameVersion and Mirrors are filled in.
+func InstallForge(gameVersion, forgeVersion string, opts InstallOptions) (string, error) {
+	if forgeVersion == "latest" || forgeVersion == "" {
+		versions, err := GetForgeVersions(gameVersion)
+		if err != nil {
+			return "", err
+		}
+		forgeVersion = versions[0]
+	}
+	forgeVersion = strings.TrimPrefix(forgeVersion, gameVersion+"-")
+
+	versionID := fmt.Sprintf("%s-forge-%s", gameVersion, forgeVersion)
+	if models.IsValidVersionFile(filepath.Join(constants.GetVersionsDir(), versionID, versionID+".json"), versionID) {
+		return versionID, nil
+	}
+
+	if isJarModVersion(gameVersion) {
+		return installJarMod(gameVersion, forgeVersion, versionID)
+	}
+
+	full := gameVersion + "-" + forgeVersion
+	relPath := fmt.Sprintf("net/minecraftforge/forge/%s/forge-%s-installer.jar", full, full)
+	installerPath := filepath.Join(constants.GetLibrariesDir(), filepath.FromSlash(relPath))
+	if err := EnsureInstaller(mavenURL()+relPath, installerPath); err != nil {
+		return "", fmt.Errorf("failed to download forge installer: %w", err)
+	}
+
+	opts.GameVersion = gameVersion
+	opts.Mirrors = map[string]string{MavenURL: mavenURL()}
+	id, err := InstallFromJar(installerPath, opts)
+	if errors.Is(err, ErrLegacyInstaller) {
+		return installLegacy(installerPath, gameVersion, versionID)
+	}
+	return id, err
+}
+
+// EnsureInstaller downloads an installer jar unless it is already cached.
+func EnsureInstaller(url, path string) error {
+	if _, err := os.Stat(path); err == nil {
+		return nil
+	}
+	data, err := network.NewHttpClient().Get(url)
+	if err != nil {
+		return err
+	}
+	return downloader.AtomicWriteFile(path, data)
+}
+
+// InstallFromJar installs the version described by a 1.13+ installer jar: it fetches the declared
+// libraries, runs the client processors and writes the version JSON once everything succeeded.
+func InstallFromJar(installerPath string, opts InstallOptions) (string, error) {
+	zr, err := zip.OpenReader(installerPath)
+	if err != nil {
+		return "", fmt.Errorf("failed to open installer: %w", err)
+	}
+	defer zr.Close()
+
+	profileData, err := readZipEntry(&zr.Reader, "install_profile.json")
+	if err != nil {
+		return "", err
+	}
+	var profile InstallProfile
+	if err := json.Unmarshal(profileData, &profile); err != nil {
+		return "", fmt.Errorf("failed to parse install profile: %w", err)
+	}
+	if len(profile.VersionInfo) > 0 || len(profile.Install) > 0 {
+		return "", ErrLegacyInstaller
+	}
+
+	versionPath := strings.TrimPrefix(profile.JSON, "/")
+	if versionPath == "" {
+		versionPath = "version.json"
+	}
+	versionData, err := readZipEntry(&zr.Reader, versionPath)
+	if err != nil {
+		return "", err
+	}
+	var version models.VersionDetail
+	if err := json.Unmarshal(versionData, &version); err != nil {
+		return "", fmt.Errorf("failed to parse version json: %w", err)
+	}
+	if version.ID == "" {
+		return "", fmt.Errorf("installer version json has no id")
+	}
+	if version.InheritsFrom == "" {
+		return "", fmt.Errorf("installer version json does not inherit from a vanilla version")
+	}
+
+	gameVersion := profile.Minecraft
+	if gameVersion == "" {
+		gameVersion = version.InheritsFrom
+	}
+	if opts.GameVersion != "" && gameVersion != opts.GameVersion {
+		return "", fmt.Errorf("installer targets minecraft %s, not %s", gameVersion, opts.GameVersion)
+	}
+
+	opts.status("Installing loader libraries...")
+	if err := installLibraries(&zr.Reader, profile.Libraries, true, opts); err != nil {
+		return "", err
+	}
+	if err := installLibraries(&zr.Reader, version.Libraries, false, opts); err != nil {
+		return "", err
+	}
+
+	tempDir, err := os.MkdirTemp("", "nezord-installer-*")
+	if err != nil {
+		return "", err
+	}
+	defer os.RemoveAll(tempDir)
+
+	data, err := buildProcessorData(&zr.Reader, &profile, gameVersion, installerPath, tempDir)
+	if err != nil {
+		return "", err
+	}
+	if err := runProcessors(profile.Processors, data, opts); err != nil {
+		return "", err
+	}
+
+	libDir := constants.GetLibrariesDir()
+	osName := system.GetSystemInfo().OS
+	for _, lib := range version.Libraries {
+		path := libraryPath(lib)
+		if path == "" || !lib.IsAllowed(osName) {
+			continue
+		}
+		if _, err := os.Stat(filepath.Join(libDir, filepath.FromSlash(path))); err != nil {
+			return "", fmt.Errorf("library %s was not produced by the installer", lib.Name)
+		}
+	}
+
+	jsonPath := filepath.Join(constants.GetVersionsDir(), version.ID, version.ID+".json")
+	if err := downloader.AtomicWriteFile(jsonPath, versionData); err != nil {
+		return "", fmt.Errorf("failed to write version json: %w", err)
+	}
+
+	return version.ID, nil
+}
+
+func readZipEntry(zr *zip.Reader, name string) ([]byte, error) {
+	f, err := zr.Open(name)
+	if err != nil {
+		return nil, fmt.Errorf("installer is missing %s: %w", name, err)
+	}
+	defer f.Close()
+	return io.ReadAll(f)
+}
+
+func extractZipEntry(zr *zip.Reader, name, dest string) error {
+	data, err := readZipEntry(zr, name)
+	if err != nil {
+		return err
+	}
+	return downloader.AtomicWriteFile(dest, data)
+}
+
+func libraryPath(lib models.Library) string {
+	if lib.Downloads.Artifact.Path != "" {
+		return lib.Downloads.Artifact.Path
+	}
+	return lib.GetMavenPath()
+}
+
+// installLibraries makes every library available, preferring copies bundled in the installer's
+// maven/ folder over downloads. Libraries without a source are left for the processors unless
+// required is set.
+func installLibraries(zr *zip.Reader, libs []models.Library, required bool, opts InstallOptions) error {
+	libDir := constants.GetLibrariesDir()
+	osName := system.GetSystemInfo().OS
+
+	var tasks []downloader.Task
+	for _, lib := range libs {
+		if !lib.IsAllowed(osName) {
+			continue
+		}
+		path := libraryPath(lib)
+		if path == "" {
+			continue
+		}
+		dest := filepath.Join(libDir, filepath.FromSlash(path))
+		sha1 := lib.Downloads.Artifact.SHA1
+
+		if _, err := os.Stat(dest); err == nil && (sha1 == "" || downloader.CheckFileSHA1(dest, sha1)) {
+			continue
+		}
+
+		if zipHasEntry(zr, "maven/"+path) {
+			if err := extractZipEntry(zr, "maven/"+path, dest); err != nil {
+				return err
+			}
+			if sha1 != "" && !downloader.CheckFileSHA1(dest, sha1) {
+				return fmt.Errorf("bundled library %s failed checksum verification", lib.Name)
+			}
+			continue
+		}
+
+		url := lib.Downloads.Artifact.URL
+		if url == "" && lib.URL != "" {
+			url = strings.TrimSuffix(lib.URL, "/") + "/" + path
+		}
+		if url == "" {
+			if required {
+				return fmt.Errorf("library %s is not available", lib.Name)
+			}
+			continue
+		}
+
+		tasks = append(tasks, downloader.Task{
+			URL:  opts.rewrite(url),
+			Path: dest,
+			SHA1: sha1,
+			Size: int64(lib.Downloads.Artifact.Size),
+		})
+	}
+
+	if len(tasks) == 0 {
+		return nil
+	}
+
+	pool := downloader.NewWorkerPool(8, len(tasks))
+	pool.Start(context.Background())
+	for _, task := range tasks {
+		pool.Submit(task)
+	}
+	pool.Wait()
+
+	if errs := pool.Errors(); len(errs) > 0 {
+		return fmt.Errorf("failed to download %d libraries: %w", len(errs), errs[0])
+	}
+	return nil
+}
+
+func zipHasEntry(zr *zip.Reader, name string) bool {
+	f, err := zr.Open(name)
+	if err != nil {
+		return false
+	}
+	f.Close()
+	return true
+}
@@ -0,0 +1,291 @@
+package forge
+
+import (
+	"NezordLauncher/pkg/constants"
+	"archive/zip"
+	"bytes"
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const (
+	testGameVersion  = "1.20.1"
+	testForgeVersion = "47.2.0"
+	testVersionID    = "1.20.1-forge-47.2.0"
+)
+
+var patchedContent = []byte("patched client jar")
+
+func sha1Hex(data []byte) string {
+	sum := sha1.Sum(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func buildZip(t *testing.T, files map[string][]byte) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for name, data := range files {
+		w, err := zw.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		w.Write(data)
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func buildInstaller(t *testing.T, patchedSHA string) []byte {
+	t.Helper()
+	toolJar := buildZip(t, map[string][]byte{
+		"META-INF/MANIFEST.MF": []byte("Manifest-Version: 1.0\r\nMain-Class: net.minecraftforge.installertools.ConsoleTool\r\n"),
+	})
+
+	profile := map[string]interface{}{
+		"spec":      1,
+		"profile":   "forge",
+		"version":   testVersionID,
+		"minecraft": testGameVersion,
+		"json":      "/version.json",
+		"data": map[string]interface{}{
+			"BINPATCH":    map[string]string{"client": "/data/client.lzma", "server": "/data/server.lzma"},
+			"PATCHED":     map[string]string{"client": "[net.minecraftforge:forge:1.20.1-47.2.0:client]"},
+			"PATCHED_SHA": map[string]string{"client": "'" + patchedSHA + "'"},
+		},
+		"processors": []map[string]interface{}{
+			{
+				"sides": []string{"server"},
+				"jar":   "net.minecraftforge:installertools:1.3.0",
+				"args":  []string{"--task", "SERVER_ONLY"},
+			},
+			{
+				"jar":       "net.minecraftforge:installertools:1.3.0",
+				"classpath": []string{"net.md-5:SpecialSource:1.11.0"},
+				"args":      []string{"--clean", "{MINECRAFT_JAR}", "--apply", "{BINPATCH}", "--output", "{PATCHED}"},
+				"outputs":   map[string]string{"{PATCHED}": "{PATCHED_SHA}"},
+			},
+		},
+		"libraries": []map[string]interface{}{
+			{
+				"name": "net.minecraftforge:installertools:1.3.0",
+				"downloads": map[string]interface{}{"artifact": map[string]interface{}{
+					"path": "net/minecraftforge/installertools/1.3.0/installertools-1.3.0.jar",
+					"url":  MavenURL + "net/minecraftforge/installertools/1.3.0/installertools-1.3.0.jar",
+					"sha1": sha1Hex(toolJar),
+				}},
+			},
+			{
+				"name": "net.md-5:SpecialSource:1.11.0",
+				"downloads": map[string]interface{}{"artifact": map[string]interface{}{
+					"path": "net/md-5/SpecialSource/1.11.0/SpecialSource-1.11.0.jar",
+					"url":  MavenURL + "net/md-5/SpecialSource/1.11.0/SpecialSource-1.11.0.jar",
+				}},
+			},
+		},
+	}
+	version := map[string]interface{}{
+		"id":           testVersionID,
+		"inheritsFrom": testGameVersion,
+		"type":         "release",
+		"mainClass":    "cpw.mods.bootstraplauncher.BootstrapLauncher",
+		"libraries": []map[string]interface{}{
+			{
+				"name": "net.minecraftforge:forge:1.20.1-47.2.0:client",
+				"downloads": map[string]interface{}{"artifact": map[string]interface{}{
+					"path": "net/minecraftforge/forge/1.20.1-47.2.0/forge-1.20.1-47.2.0-client.jar",
+					"url":  "",
+				}},
+			},
+		},
+	}
+
+	profileData, _ := json.Marshal(profile)
+	versionData, _ := json.Marshal(version)
+	return buildZip(t, map[string][]byte{
+		"install_profile.json": profileData,
+		"version.json":         versionData,
+		"data/client.lzma":     []byte("binpatches"),
+		"maven/net/minecraftforge/installertools/1.3.0/installertools-1.3.0.jar": toolJar,
+	})
+}
+
+func newMavenStub(t *testing.T, installer []byte) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/net/minecraftforge/forge/maven-metadata.xml":
+			w.Write([]byte(`<metadata><versioning><versions>
+				<version>1.20.1-47.1.0</version>
+				<version>1.20.1-47.2.0</version>
+				<version>1.19.4-45.1.0</version>
+			</versions></versioning></metadata>`))
+		case strings.HasSuffix(r.URL.Path, "-installer.jar"):
+			w.Write(installer)
+		case r.URL.Path == "/net/md-5/SpecialSource/1.11.0/SpecialSource-1.11.0.jar":
+			w.Write([]byte("special source"))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(server.Close)
+	t.Setenv("NEZORD_FORGE_MAVEN_URL", server.URL)
+	return server
+}
+
+type processorCall struct {
+	classpath []string
+	mainClass string
+	args      []string
+}
+
+func stubProcessor(t *testing.T, output []byte) *[]processorCall {
+	t.Helper()
+	var calls []processorCall
+	original := runProcessor
+	runProcessor = func(javaPath string, classpath []string, mainClass string, args []string) error {
+		calls = append(calls, processorCall{classpath, mainClass, args})
+		for i, arg := range args {
+			if arg == "--apply" {
+				if _, err := os.Stat(args[i+1]); err != nil {
+					t.Errorf("binpatch was not extracted: %v", err)
+				}
+			}
+			if arg == "--output" {
+				os.MkdirAll(filepath.Dir(args[i+1]), 0755)
+				os.WriteFile(args[i+1], output, 0644)
+			}
+		}
+		return nil
+	}
+	t.Cleanup(func() { runProcessor = original })
+	return &calls
+}
+
+func TestGetForgeVersions(t *testing.T) {
+	t.Setenv("NEZORD_DATA_DIR", t.TempDir())
+	newMavenStub(t, nil)
+
+	versions, err := GetForgeVersions(testGameVersion)
+	if err != nil {
+		t.Fatalf("GetForgeVersions failed: %v", err)
+	}
+	if len(versions) != 2 || versions[0] != "47.2.0" || versions[1] != "47.1.0" {
+		t.Errorf("unexpected versions: %v", versions)
+	}
+}
+
+func TestInstallForgeRunsProcessors(t *testing.T) {
+	t.Setenv("NEZORD_DATA_DIR", t.TempDir())
+	newMavenStub(t, buildInstaller(t, sha1Hex(patchedContent)))
+	calls := stubProcessor(t, patchedContent)
+
+	id, err := InstallForge(testGameVersion, "latest", InstallOptions{JavaPath: "java"})
+	if err != nil {
+		t.Fatalf("InstallForge failed: %v", err)
+	}
+	if id != testVersionID {
+		t.Errorf("got version id %s, want %s", id, testVersionID)
+	}
+
+	if len(*calls) != 1 {
+		t.Fatalf("expected only the client processor to run, got %d calls", len(*calls))
+	}
+	call := (*calls)[0]
+	if call.mainClass != "net.minecraftforge.installertools.ConsoleTool" {
+		t.Errorf("unexpected main class %s", call.mainClass)
+	}
+	if len(call.classpath) != 2 || !strings.HasSuffix(call.classpath[1], "SpecialSource-1.11.0.jar") {
+		t.Errorf("unexpected classpath %v", call.classpath)
+	}
+	wantJar := filepath.Join(constants.GetVersionsDir(), testGameVersion, testGameVersion+".jar")
+	if call.args[1] != wantJar {
+		t.Errorf("MINECRAFT_JAR resolved to %s, want %s", call.args[1], wantJar)
+	}
+
+	if _, err := os.Stat(filepath.Join(constants.GetVersionsDir(), id, id+".json")); err != nil {
+		t.Errorf("version json not written: %v", err)
+	}
+
+	if _, err := InstallForge(testGameVersion, testForgeVersion, InstallOptions{JavaPath: "java"}); err != nil {
+		t.Fatalf("reinstall failed: %v", err)
+	}
+	if len(*calls) != 1 {
+		t.Errorf("installed version should not rerun processors")
+	}
+}
+
+func TestInstallFromJarSkipsSatisfiedProcessors(t *testing.T) {
+	t.Setenv("NEZORD_DATA_DIR", t.TempDir())
+	newMavenStub(t, nil)
+	calls := stubProcessor(t, patchedContent)
+
+	installerPath := filepath.Join(t.TempDir(), "installer.jar")
+	os.WriteFile(installerPath, buildInstaller(t, sha1Hex(patchedContent)), 0644)
+
+	patched := filepath.Join(constants.GetLibrariesDir(), "net/minecraftforge/forge/1.20.1-47.2.0/forge-1.20.1-47.2.0-client.jar")
+	os.MkdirAll(filepath.Dir(patched), 0755)
+	os.WriteFile(patched, patchedContent, 0644)
+
+	if _, err := InstallFromJar(installerPath, InstallOptions{Mirrors: map[string]string{MavenURL: mavenURL()}}); err != nil {
+		t.Fatalf("InstallFromJar failed: %v", err)
+	}
+	if len(*calls) != 0 {
+		t.Errorf("processor with valid outputs should be skipped")
+	}
+}
+
+func TestInstallFromJarRejectsBadProcessorOutput(t *testing.T) {
+	t.Setenv("NEZORD_DATA_DIR", t.TempDir())
+	newMavenStub(t, nil)
+	stubProcessor(t, []byte("corrupted"))
+
+	installerPath := filepath.Join(t.TempDir(), "installer.jar")
+	os.WriteFile(installerPath, buildInstaller(t, sha1Hex(patchedContent)), 0644)
+
+	_, err := InstallFromJar(installerPath, InstallOptions{JavaPath: "java", Mirrors: map[string]string{MavenURL: mavenURL()}})
+	if err == nil || !strings.Contains(err.Error(), "unexpected checksum") {
+		t.Fatalf("expected checksum error, got %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(constants.GetVersionsDir(), testVersionID, testVersionID+".json")); !os.IsNotExist(err) {
+		t.Errorf("version json must not be written when a processor fails")
+	}
+}
+
+func TestInstallFromJarLegacyInstaller(t *testing.T) {
+	installerPath := filepath.Join(t.TempDir(), "installer.jar")
+	os.WriteFile(installerPath, buildZip(t, map[string][]byte{
+		"install_profile.json": []byte(`{"install":{"minecraft":"1.12.2"},"versionInfo":{"id":"1.12.2-forge"}}`),
+	}), 0644)
+
+	if _, err := InstallFromJar(installerPath, InstallOptions{}); !errors.Is(err, ErrLegacyInstaller) {
+		t.Errorf("expected ErrLegacyInstaller, got %v", err)
+	}
+}
+
+func TestCompareVersions(t *testing.T) {
+	cases := []struct {
+		a, b string
+		want int
+	}{
+		{"47.10.0", "47.9.1", 1},
+		{"47.2.0", "47.2.0", 0},
+		{"20.4.80-beta", "20.4.80", -1},
+		{"14.23.5.2860", "14.23.5", 1},
+	}
+	for _, c := range cases {
+		if got := CompareVersions(c.a, c.b); got != c.want {
+			t.Errorf("CompareVersions(%s, %s) = %d, want %d", c.a, c.b, got, c.want)
+		}
+	}
+}
@@ -0,0 +1,218 @@
+package forge
+
+import (
+	"NezordLauncher/pkg/constants"
+	"NezordLauncher/pkg/downloader"
+	"NezordLauncher/pkg/models"
+	"NezordLauncher/pkg/network"
+	"archive/zip"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// Maven roots used by pre-1.13 install profiles; their artifacts now live on the current maven.
+var legacyMavenRoots = []string{
+	"http://files.minecraftforge.net/maven/",
+	"https://files.minecraftforge.net/maven/",
+	"http://maven.minecraftforge.net/",
+}
+
+// FMLLibsURL hosts the helper libraries FML 1.5.x used to download on first start.
+const FMLLibsURL = "https://files.prismlauncher.org/fmllibs/"
+
+var fmlLibraries = map[string][]string{
+	"1.5":   {"argo-small-3.2.jar", "guava-14.0-rc3.jar", "asm-all-4.1.jar", "bcprov-jdk15on-148.jar", "deobfuscation_data_1.5.zip"},
+	"1.5.1": {"argo-small-3.2.jar", "guava-14.0-rc3.jar", "asm-all-4.1.jar", "bcprov-jdk15on-148.jar", "deobfuscation_data_1.5.1.zip"},
+	"1.5.2": {"argo-small-3.2.jar", "guava-14.0-rc3.jar", "asm-all-4.1.jar", "bcprov-jdk15on-148.jar", "deobfuscation_data_1.5.2.zip", "scala-library.jar"},
+}
+
+func fmlLibsURL() string {
+	if override := os.Getenv("NEZORD_FML_LIBS_URL"); override != "" {
+		return strings.TrimSuffix(override, "/") + "/"
+	}
+	return FMLLibsURL
+}
+
+type legacyInstallProfile struct {
+	Install struct {
+		Path      string `json:"path"`
+		FilePath  string `json:"filePath"`
+		Minecraft string `json:"minecraft"`
+	} `json:"install"`
+	VersionInfo json.RawMessage `json:"versionInfo"`
+}
+
+// isJarModVersion reports whether Forge for this game version predates the installer and
+// has to be merged into the client jar.
+func isJarModVersion(gameVersion string) bool {
+	parts := strings.Split(gameVersion, ".")
+	if len(parts) < 2 || parts[0] != "1" {
+		return false
+	}
+	minor, err := strconv.Atoi(parts[1])
+	return err == nil && minor < 6
+}
+
+// installLegacy handles 1.6-1.12 installers, whose profile embeds the version JSON and ships
+// the universal jar. The version is stored under versionID so it matches GetLaunchVersionID.
+func installLegacy(installerPath, gameVersion, versionID string) (string, error) {
+	zr, err := zip.OpenReader(installerPath)
+	if err != nil {
+		return "", fmt.Errorf("failed to open installer: %w", err)
+	}
+	defer zr.Close()
+
+	profileData, err := readZipEntry(&zr.Reader, "install_profile.json")
+	if err != nil {
+		return "", err
+	}
+	var profile legacyInstallProfile
+	if err := json.Unmarshal(profileData, &profile); err != nil {
+		return "", fmt.Errorf("failed to parse install profile: %w", err)
+	}
+	if profile.Install.Minecraft != "" && profile.Install.Minecraft != gameVersion {
+		return "", fmt.Errorf("installer targets minecraft %s, not %s", profile.Install.Minecraft, gameVersion)
+	}
+
+	var version models.VersionDetail
+	if err := json.Unmarshal(profile.VersionInfo, &version); err != nil {
+		return "", fmt.Errorf("failed to parse version info: %w", err)
+	}
+
+	if profile.Install.Path != "" && profile.Install.FilePath != "" {
+		dest := filepath.Join(constants.GetLibrariesDir(), filepath.FromSlash(models.MavenPath(profile.Install.Path)))
+		if err := extractZipEntry(&zr.Reader, profile.Install.FilePath, dest); err != nil {
+			return "", err
+		}
+	}
+
+	root := mavenURL()
+	for i := range version.Libraries {
+		for _, legacyRoot := range legacyMavenRoots {
+			version.Libraries[i].URL = rewriteMavenURL(version.Libraries[i].URL, legacyRoot, root)
+		}
+	}
+
+	version.ID = versionID
+	if version.InheritsFrom == "" {
+		version.InheritsFrom = gameVersion
+	}
+	if version.Jar == "" {
+		version.Jar = gameVersion
+	}
+
+	return versionID, writeVersionJSON(&version)
+}
+
+// installJarMod merges the 1.5.x universal zip into a copy of the vanilla client jar.
+func installJarMod(gameVersion, forgeVersion, versionID string) (string, error) {
+	vanillaJar := filepath.Join(constants.GetVersionsDir(), gameVersion, gameVersion+".jar")
+	if _, err := os.Stat(vanillaJar); err != nil {
+		return "", fmt.Errorf("minecraft %s client jar is required: %w", gameVersion, err)
+	}
+
+	full := gameVersion + "-" + forgeVersion
+	relPath := fmt.Sprintf("net/minecraftforge/forge/%s/forge-%s-universal.zip", full, full)
+	universalPath := filepath.Join(constants.GetLibrariesDir(), filepath.FromSlash(relPath))
+	if err := EnsureInstaller(mavenURL()+relPath, universalPath); err != nil {
+		return "", fmt.Errorf("failed to download forge universal: %w", err)
+	}
+
+	mergedJar := filepath.Join(constants.GetVersionsDir(), versionID, versionID+".jar")
+	if err := mergeJars(mergedJar, universalPath, vanillaJar); err != nil {
+		return "", fmt.Errorf("failed to patch client jar: %w", err)
+	}
+
+	return versionID, writeVersionJSON(&models.VersionDetail{
+		ID:           versionID,
+		InheritsFrom: gameVersion,
+		Jar:          versionID,
+		Type:         "release",
+	})
+}
+
+// mergeJars writes the entries of each source into dest, earlier sources winning. Signatures are
+// dropped because patched classes no longer match them.
+func mergeJars(dest string, sources ...string) error {
+	if err := os.MkdirAll(filepath.Dir(dest), 0755); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(dest), "tmp-*.jar")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	zw := zip.NewWriter(tmp)
+	seen := make(map[string]bool)
+	for _, source := range sources {
+		zr, err := zip.OpenReader(source)
+		if err != nil {
+			tmp.Close()
+			return err
+		}
+		for _, f := range zr.File {
+			if seen[f.Name] || strings.HasPrefix(f.Name, "META-INF/") {
+				continue
+			}
+			seen[f.Name] = true
+			if err := zw.Copy(f); err != nil {
+				zr.Close()
+				tmp.Close()
+				return err
+			}
+		}
+		zr.Close()
+	}
+
+	if err := zw.Close(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), dest)
+}
+
+func writeVersionJSON(version *models.VersionDetail) error {
+	data, err := json.MarshalIndent(version, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal version json: %w", err)
+	}
+	path := filepath.Join(constants.GetVersionsDir(), version.ID, version.ID+".json")
+	if err := downloader.AtomicWriteFile(path, data); err != nil {
+		return fmt.Errorf("failed to write version json: %w", err)
+	}
+	return nil
+}
+
+// EnsureFMLLibraries places the helper libraries FML 1.5.x expects in <gameDir>/lib, since the
+// original download location is gone. Other game versions need nothing.
+func EnsureFMLLibraries(gameVersion, gameDir string) error {
+	names, ok := fmlLibraries[gameVersion]
+	if !ok {
+		return nil
+	}
+
+	client := network.NewHttpClient()
+	libDir := filepath.Join(gameDir, "lib")
+	for _, name := range names {
+		path := filepath.Join(libDir, name)
+		if _, err := os.Stat(path); err == nil {
+			continue
+		}
+		data, err := client.Get(fmlLibsURL() + name)
+		if err != nil {
+			return fmt.Errorf("failed to download %s: %w", name, err)
+		}
+		if err := downloader.AtomicWriteFile(path, data); err != nil {
+			return err
+		}
+	}
+	return nil
+}
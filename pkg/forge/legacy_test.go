@@ -0,0 +1,140 @@
+package forge
+
+import (
+	"NezordLauncher/pkg/constants"
+	"NezordLauncher/pkg/models"
+	"archive/zip"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestInstallForgeLegacyInstaller(t *testing.T) {
+	t.Setenv("NEZORD_DATA_DIR", t.TempDir())
+
+	profile := `{
+		"install": {
+			"path": "net.minecraftforge:forge:1.7.10-10.13.4.1614-1.7.10",
+			"filePath": "forge-1.7.10-10.13.4.1614-1.7.10-universal.jar",
+			"minecraft": "1.7.10"
+		},
+		"versionInfo": {
+			"id": "1.7.10-Forge10.13.4.1614-1.7.10",
+			"inheritsFrom": "1.7.10",
+			"mainClass": "net.minecraft.launchwrapper.Launch",
+			"minecraftArguments": "--username ${auth_player_name} --tweakClass cpw.mods.fml.common.launcher.FMLTweaker",
+			"libraries": [
+				{"name": "net.minecraftforge:forge:1.7.10-10.13.4.1614-1.7.10", "url": "http://files.minecraftforge.net/maven/"},
+				{"name": "net.minecraft:launchwrapper:1.12", "serverreq": true}
+			]
+		}
+	}`
+	installer := buildZip(t, map[string][]byte{
+		"install_profile.json":                           []byte(profile),
+		"forge-1.7.10-10.13.4.1614-1.7.10-universal.jar": []byte("universal"),
+	})
+	server := newMavenStub(t, installer)
+
+	id, err := InstallForge("1.7.10", "10.13.4.1614-1.7.10", InstallOptions{})
+	if err != nil {
+		t.Fatalf("InstallForge failed: %v", err)
+	}
+	if id != "1.7.10-forge-10.13.4.1614-1.7.10" {
+		t.Errorf("unexpected version id %s", id)
+	}
+
+	data, err := os.ReadFile(filepath.Join(constants.GetVersionsDir(), id, id+".json"))
+	if err != nil {
+		t.Fatalf("version json not written: %v", err)
+	}
+	var version models.VersionDetail
+	if err := json.Unmarshal(data, &version); err != nil {
+		t.Fatal(err)
+	}
+	if version.ID != id || version.InheritsFrom != "1.7.10" || version.Jar != "1.7.10" {
+		t.Errorf("unexpected version header: id=%s inheritsFrom=%s jar=%s", version.ID, version.InheritsFrom, version.Jar)
+	}
+	if !strings.Contains(version.MinecraftArguments, "FMLTweaker") {
+		t.Errorf("tweak class missing from arguments: %s", version.MinecraftArguments)
+	}
+	if len(version.Libraries) != 2 || version.Libraries[0].URL != server.URL+"/" {
+		t.Errorf("legacy maven url not rewritten: %+v", version.Libraries)
+	}
+
+	universal := filepath.Join(constants.GetLibrariesDir(), "net/minecraftforge/forge/1.7.10-10.13.4.1614-1.7.10/forge-1.7.10-10.13.4.1614-1.7.10.jar")
+	if content, err := os.ReadFile(universal); err != nil || string(content) != "universal" {
+		t.Errorf("universal jar not extracted: %v", err)
+	}
+}
+
+func TestInstallForgeJarMod(t *testing.T) {
+	t.Setenv("NEZORD_DATA_DIR", t.TempDir())
+
+	universal := buildZip(t, map[string][]byte{
+		"net/minecraft/client/Minecraft.class": []byte("patched"),
+		"cpw/mods/fml/common/Loader.class":     []byte("fml"),
+	})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case strings.HasSuffix(r.URL.Path, "-universal.zip"):
+			w.Write(universal)
+		case strings.HasPrefix(r.URL.Path, "/fmllibs/"):
+			w.Write([]byte(strings.TrimPrefix(r.URL.Path, "/fmllibs/")))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+	t.Setenv("NEZORD_FORGE_MAVEN_URL", server.URL)
+	t.Setenv("NEZORD_FML_LIBS_URL", server.URL+"/fmllibs")
+
+	vanillaJar := filepath.Join(constants.GetVersionsDir(), "1.5.2", "1.5.2.jar")
+	os.MkdirAll(filepath.Dir(vanillaJar), 0755)
+	os.WriteFile(vanillaJar, buildZip(t, map[string][]byte{
+		"net/minecraft/client/Minecraft.class": []byte("vanilla"),
+		"terrain.png":                          []byte("png"),
+		"META-INF/MOJANG_C.SF":                 []byte("signature"),
+	}), 0644)
+
+	id, err := InstallForge("1.5.2", "7.8.1.738", InstallOptions{})
+	if err != nil {
+		t.Fatalf("InstallForge failed: %v", err)
+	}
+
+	zr, err := zip.OpenReader(filepath.Join(constants.GetVersionsDir(), id, id+".jar"))
+	if err != nil {
+		t.Fatalf("patched jar not written: %v", err)
+	}
+	defer zr.Close()
+	entries := map[string]string{}
+	for _, f := range zr.File {
+		rc, _ := f.Open()
+		content, _ := io.ReadAll(rc)
+		rc.Close()
+		entries[f.Name] = string(content)
+	}
+	if entries["net/minecraft/client/Minecraft.class"] != "patched" || entries["terrain.png"] != "png" {
+		t.Errorf("unexpected patched jar contents: %v", entries)
+	}
+	if _, ok := entries["META-INF/MOJANG_C.SF"]; ok {
+		t.Error("signatures should be stripped from the patched jar")
+	}
+
+	gameDir := t.TempDir()
+	if err := EnsureFMLLibraries("1.5.2", gameDir); err != nil {
+		t.Fatalf("EnsureFMLLibraries failed: %v", err)
+	}
+	for _, name := range fmlLibraries["1.5.2"] {
+		if _, err := os.Stat(filepath.Join(gameDir, "lib", name)); err != nil {
+			t.Errorf("%s not placed in lib: %v", name, err)
+		}
+	}
+	if err := EnsureFMLLibraries("1.12.2", t.TempDir()); err != nil {
+		t.Errorf("versions without fml libraries should be a no-op: %v", err)
+	}
+}
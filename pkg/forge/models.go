@@ -0,0 +1,43 @@
+package forge
+
+import (
+	"NezordLauncher/pkg/models"
+	"encoding/json"
+)
+
+// InstallProfile is the install_profile.json of a 1.13+ Forge (or NeoForge) installer.
+type InstallProfile struct {
+	Spec       int                  `json:"spec"`
+	Profile    string               `json:"profile"`
+	Version    string               `json:"version"`
+	Minecraft  string               `json:"minecraft"`
+	JSON       string               `json:"json"`
+	Data       map[string]DataEntry `json:"data"`
+	Processors []Processor          `json:"processors"`
+	Libraries  []models.Library     `json:"libraries"`
+
+	// Present only in pre-1.13 installers.
+	Install     json.RawMessage `json:"install,omitempty"`
+	VersionInfo json.RawMessage `json:"versionInfo,omitempty"`
+}
+
+type DataEntry struct {
+	Client string `json:"client"`
+	Server string `json:"server"`
+}
+
+type Processor struct {
+	Sides     []string          `json:"sides,omitempty"`
+	Jar       string            `json:"jar"`
+	Classpath []string          `json:"classpath"`
+	Args      []string          `json:"args"`
+	Outputs   map[string]string `json:"outputs,omitempty"`
+}
+
+type mavenMetadata struct {
+	Versioning struct {
+		Latest   string   `xml:"latest"`
+		Release  string   `xml:"release"`
+		Versions []string `xml:"versions>version"`
+	} `xml:"versioning"`
+}
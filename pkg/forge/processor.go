@@ -0,0 +1,213 @@
+package forge
+
+import (
+	"NezordLauncher/pkg/constants"
+	"NezordLauncher/pkg/downloader"
+	"NezordLauncher/pkg/models"
+	"NezordLauncher/pkg/system"
+	"archive/zip"
+	"bufio"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// runProcessor executes one installer processor; replaced in tests.
+var runProcessor = func(javaPath string, classpath []string, mainClass string, args []string) error {
+	cmdArgs := append([]string{"-cp", strings.Join(classpath, system.GetClasspathSeparator()), mainClass}, args...)
+	output, err := exec.Command(javaPath, cmdArgs...).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("%w: %s", err, outputTail(output, 2048))
+	}
+	return nil
+}
+
+func outputTail(output []byte, max int) string {
+	text := strings.TrimSpace(string(output))
+	if len(text) > max {
+		text = "..." + text[len(text)-max:]
+	}
+	return text
+}
+
+// buildProcessorData resolves the client side of the profile's data block plus the built-in keys.
+func buildProcessorData(zr *zip.Reader, profile *InstallProfile, gameVersion, installerPath, tempDir string) (map[string]string, error) {
+	libDir := constants.GetLibrariesDir()
+	data := map[string]string{
+		"SIDE":              "client",
+		"MINECRAFT_JAR":     filepath.Join(constants.GetVersionsDir(), gameVersion, gameVersion+".jar"),
+		"MINECRAFT_VERSION": gameVersion,
+		"ROOT":              constants.GetDataDir(),
+		"INSTALLER":         installerPath,
+		"LIBRARY_DIR":       libDir,
+	}
+
+	for key, entry := range profile.Data {
+		value := entry.Client
+		switch {
+		case isWrapped(value, '[', ']'):
+			data[key] = artifactPath(libDir, value[1:len(value)-1])
+		case isWrapped(value, '\'', '\''):
+			data[key] = value[1 : len(value)-1]
+		case strings.HasPrefix(value, "/"):
+			dest := filepath.Join(tempDir, filepath.FromSlash(strings.TrimPrefix(value, "/")))
+			if err := extractZipEntry(zr, strings.TrimPrefix(value, "/"), dest); err != nil {
+				return nil, err
+			}
+			data[key] = dest
+		default:
+			data[key] = value
+		}
+	}
+	return data, nil
+}
+
+func runProcessors(processors []Processor, data map[string]string, opts InstallOptions) error {
+	libDir := constants.GetLibrariesDir()
+
+	var client []Processor
+	for _, proc := range processors {
+		if len(proc.Sides) == 0 || containsString(proc.Sides, "client") {
+			client = append(client, proc)
+		}
+	}
+
+	for i, proc := range client {
+		outputs := make(map[string]string, len(proc.Outputs))
+		for key, value := range proc.Outputs {
+			path, err := resolveArg(key, data, libDir)
+			if err != nil {
+				return err
+			}
+			sha1, err := resolveArg(value, data, libDir)
+			if err != nil {
+				return err
+			}
+			outputs[path] = sha1
+		}
+
+		if len(outputs) > 0 && outputsValid(outputs) {
+			continue
+		}
+
+		if opts.JavaPath == "" {
+			return fmt.Errorf("java is required to run installer processors")
+		}
+
+		jarPath := artifactPath(libDir, proc.Jar)
+		mainClass, err := jarMainClass(jarPath)
+		if err != nil {
+			return fmt.Errorf("processor %s: %w", proc.Jar, err)
+		}
+
+		classpath := []string{jarPath}
+		for _, coord := range proc.Classpath {
+			classpath = append(classpath, artifactPath(libDir, coord))
+		}
+
+		args := make([]string, 0, len(proc.Args))
+		for _, arg := range proc.Args {
+			resolved, err := resolveArg(arg, data, libDir)
+			if err != nil {
+				return fmt.Errorf("processor %s: %w", proc.Jar, err)
+			}
+			args = append(args, resolved)
+		}
+
+		opts.status(fmt.Sprintf("Running installer processor %d/%d...", i+1, len(client)))
+		if err := runProcessor(opts.JavaPath, classpath, mainClass, args); err != nil {
+			return fmt.Errorf("processor %s failed: %w", proc.Jar, err)
+		}
+
+		for path, sha1 := range outputs {
+			if !downloader.CheckFileSHA1(path, sha1) {
+				_ = os.Remove(path)
+				return fmt.Errorf("processor %s produced %s with an unexpected checksum", proc.Jar, filepath.Base(path))
+			}
+		}
+	}
+	return nil
+}
+
+func outputsValid(outputs map[string]string) bool {
+	for path, sha1 := range outputs {
+		if !downloader.CheckFileSHA1(path, sha1) {
+			return false
+		}
+	}
+	return true
+}
+
+// resolveArg expands [maven:coordinates], 'literals' and {DATA} tokens in a processor argument.
+func resolveArg(arg string, data map[string]string, libDir string) (string, error) {
+	if isWrapped(arg, '[', ']') {
+		return artifactPath(libDir, arg[1:len(arg)-1]), nil
+	}
+	if isWrapped(arg, '\'', '\'') {
+		return arg[1 : len(arg)-1], nil
+	}
+
+	var sb strings.Builder
+	for {
+		start := strings.IndexByte(arg, '{')
+		if start == -1 {
+			break
+		}
+		end := strings.IndexByte(arg[start:], '}')
+		if end == -1 {
+			break
+		}
+		key := arg[start+1 : start+end]
+		value, ok := data[key]
+		if !ok {
+			return "", fmt.Errorf("unknown installer data key %s", key)
+		}
+		sb.WriteString(arg[:start])
+		sb.WriteString(value)
+		arg = arg[start+end+1:]
+	}
+	sb.WriteString(arg)
+	return sb.String(), nil
+}
+
+func artifactPath(libDir, coordinate string) string {
+	return filepath.Join(libDir, filepath.FromSlash(models.MavenPath(coordinate)))
+}
+
+func isWrapped(s string, open, close byte) bool {
+	return len(s) >= 2 && s[0] == open && s[len(s)-1] == close
+}
+
+func containsString(values []string, target string) bool {
+	for _, v := range values {
+		if v == target {
+			return true
+		}
+	}
+	return false
+}
+
+func jarMainClass(jarPath string) (string, error) {
+	zr, err := zip.OpenReader(jarPath)
+	if err != nil {
+		return "", err
+	}
+	defer zr.Close()
+
+	f, err := zr.Open("META-INF/MANIFEST.MF")
+	if err != nil {
+		return "", fmt.Errorf("jar has no manifest: %w", err)
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if strings.HasPrefix(line, "Main-Class:") {
+			return strings.TrimSpace(strings.TrimPrefix(line, "Main-Class:")), nil
+		}
+	}
+	return "", fmt.Errorf("jar manifest has no Main-Class")
+}
@@ -181,6 +181,23 @@ func (m *Manager) UpdateSettings(id string, settings InstanceSettings) error {
 	return m.SaveInstance(inst)
 }
 
+// UpdateVersion switches the game version and modloader of an instance.
+func (m *Manager) UpdateVersion(id, gameVersion string, loaderType ModloaderType, loaderVersion string) error {
+	m.mu.Lock()
+	inst, ok := m.instances[id]
+	m.mu.Unlock()
+
+	if !ok {
+		return fmt.Errorf("instance not found")
+	}
+
+	inst.GameVersion = gameVersion
+	inst.ModloaderType = loaderType
+	inst.ModloaderVersion = loaderVersion
+
+	return m.SaveInstance(inst)
+}
+
 func slugify(s string) string {
 	var result string
 	for _, r := range s {
@@ -55,3 +55,30 @@ func TestInstanceManager(t *testing.T) {
 		t.Error("Instance still exists in memory after delete")
 	}
 }
+
+func TestUpdateVersion(t *testing.T) {
+	t.Setenv("NEZORD_DATA_DIR", t.TempDir())
+
+	mgr := NewManager()
+	inst, err := mgr.CreateInstance("Upgrade", "1.20.1", ModloaderFabric, "0.14.25")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := mgr.UpdateVersion(inst.ID, "1.21.1", ModloaderNeoForge, "21.1.72"); err != nil {
+		t.Fatalf("UpdateVersion failed: %v", err)
+	}
+
+	mgr2 := NewManager()
+	if err := mgr2.Load(); err != nil {
+		t.Fatal(err)
+	}
+	loaded, _ := mgr2.Get(inst.ID)
+	if loaded.GameVersion != "1.21.1" || loaded.ModloaderType != ModloaderNeoForge || loaded.ModloaderVersion != "21.1.72" {
+		t.Errorf("version change not persisted: %+v", loaded)
+	}
+
+	if err := mgr.UpdateVersion("missing", "1.21.1", ModloaderVanilla, ""); err == nil {
+		t.Error("expected error for unknown instance")
+	}
+}
@@ -1,14 +1,21 @@
 package instances
 
-import "time"
+import (
+	"NezordLauncher/pkg/modloader"
+	"time"
+)
 
 type ModloaderType string
 
 const (
-	ModloaderVanilla ModloaderType = "vanilla"
-	ModloaderFabric  ModloaderType = "fabric"
-	ModloaderQuilt   ModloaderType = "quilt"
-	ModloaderForge   ModloaderType = "forge"
+	ModloaderVanilla      ModloaderType = "vanilla"
+	ModloaderFabric       ModloaderType = "fabric"
+	ModloaderQuilt        ModloaderType = "quilt"
+	ModloaderLegacyFabric ModloaderType = "legacyfabric"
+	ModloaderBabric       ModloaderType = "babric"
+	ModloaderForge        ModloaderType = "forge"
+	ModloaderNeoForge     ModloaderType = "neoforge"
+	ModloaderOptiFine     ModloaderType = "optifine"
 )
 
 type Instance struct {
@@ -33,17 +40,13 @@ type InstanceSettings struct {
 	OverrideRam   bool   `json:"overrideRam"`
 	GpuPreference string `json:"gpuPreference"`
 	WrapperCommand string `json:"wrapperCommand"`
+	// AccountUUID pins the instance to an account; empty uses the active account.
+	AccountUUID string `json:"accountUuid,omitempty"`
 }
 
 func (i *Instance) GetLaunchVersionID() string {
-	if i.ModloaderType == ModloaderVanilla {
-		return i.GameVersion
-	}
-	if i.ModloaderType == ModloaderFabric {
-		return "fabric-loader-" + i.ModloaderVersion + "-" + i.GameVersion
-	}
-	if i.ModloaderType == ModloaderQuilt {
-		return "quilt-loader-" + i.ModloaderVersion + "-" + i.GameVersion
+	if provider, ok := modloader.Get(string(i.ModloaderType)); ok {
+		return provider.VersionID(i.GameVersion, i.ModloaderVersion)
 	}
 	return i.GameVersion
 }
@@ -11,4 +11,7 @@ const (
 	EventLaunchError      = "launch.error"
 	EventLaunchGameLog    = "launch.game.log"
 	EventLaunchExit       = "launch.exit"
+	EventAuthStatus       = "auth.status"
+	EventAuthDeviceCode   = "auth.device.code"
+	EventAuthError        = "auth.error"
 )
@@ -25,6 +25,8 @@ type LaunchOptions struct {
 	Width               int
 	Height              int
 	AuthlibInjectorPath string
+	AuthlibServer       string
+	AuthlibPrefetched   string
 	Fullscreen          bool
 	Borderless          bool
 }
@@ -41,7 +43,7 @@ func BuildArguments(version *models.VersionDetail, options LaunchOptions) ([]str
 		mcUserType = "msa"
 	case "offline":
 		mcUserType = "legacy"
-	case "elyby":
+	case "elyby", "yggdrasil":
 		mcUserType = "mojang"
 	}
 
@@ -55,28 +57,38 @@ func BuildArguments(version *models.VersionDetail, options LaunchOptions) ([]str
 		accessToken = "null"
 	}
 
+	// Pre-1.7 clients take the session as a single legacy "token:<access>:<uuid>" argument.
+	authSession := "-"
+	if accessToken != "null" {
+		authSession = "token:" + accessToken + ":" + options.UUID
+	}
+
 	clientID := accessToken
 	if clientID == "null" {
 		clientID = options.UUID
 	}
 
 	vars := map[string]string{
-		"${auth_player_name}":  options.PlayerName,
-		"${auth_uuid}":         options.UUID,
-		"${auth_access_token}": accessToken,
-		"${user_type}":         mcUserType,
-		"${user_properties}":   userProps,
-		"${version_name}":      options.VersionID,
-		"${game_directory}":    options.GameDir,
-		"${assets_root}":       options.AssetsDir,
-		"${assets_index_name}": assetIndexName(version),
-		"${auth_xuid}":         clientID,
-		"${clientid}":          clientID,
-		"${version_type}":      version.Type,
-		"${natives_directory}": options.NativesDir,
-		"${launcher_name}":     constants.AppName,
-		"${launcher_version}":  constants.Version,
-		"${classpath}":         classpath,
+		"${auth_player_name}":    options.PlayerName,
+		"${auth_uuid}":           options.UUID,
+		"${auth_access_token}":   accessToken,
+		"${user_type}":           mcUserType,
+		"${user_properties}":     userProps,
+		"${version_name}":        options.VersionID,
+		"${game_directory}":      options.GameDir,
+		"${assets_root}":         options.AssetsDir,
+		"${assets_index_name}":   assetIndexName(version),
+		"${auth_xuid}":           clientID,
+		"${clientid}":            clientID,
+		"${version_type}":        version.Type,
+		"${natives_directory}":   options.NativesDir,
+		"${launcher_name}":       constants.AppName,
+		"${launcher_version}":    constants.Version,
+		"${classpath}":           classpath,
+		"${library_directory}":   constants.GetLibrariesDir(),
+		"${classpath_separator}": system.GetClasspathSeparator(),
+		"${auth_session}":        authSession,
+		"${game_assets}":         options.AssetsDir,
 	}
 
 	var args []string
@@ -90,7 +102,14 @@ func BuildArguments(version *models.VersionDetail, options LaunchOptions) ([]str
 	args = append(args, fmt.Sprintf("-Duser.country=%s", "US"))
 
 	if options.AuthlibInjectorPath != "" {
-		args = append(args, fmt.Sprintf("-javaagent:%s=ely.by", options.AuthlibInjectorPath))
+		server := options.AuthlibServer
+		if server == "" {
+			server = "ely.by"
+		}
+		args = append(args, fmt.Sprintf("-javaagent:%s=%s", options.AuthlibInjectorPath, server))
+		if options.AuthlibPrefetched != "" {
+			args = append(args, fmt.Sprintf("-Dauthlibinjector.yggdrasil.prefetched=%s", options.AuthlibPrefetched))
+		}
 	}
 	if options.Borderless {
 		if isLegacyLWJGL2(version.ID) {
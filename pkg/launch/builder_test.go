@@ -85,6 +85,35 @@ func TestBuildArguments_Authlib(t *testing.T) {
 	}
 }
 
+func TestBuildArguments_AuthlibCustomServer(t *testing.T) {
+	version := &models.VersionDetail{
+		ID:        "1.20.1",
+		MainClass: models.MainClassData{Client: "net.minecraft.client.main.Main"},
+	}
+
+	opts := LaunchOptions{
+		PlayerName:          "SkinUser",
+		VersionID:           "1.20.1",
+		AuthlibInjectorPath: "/path/to/authlib.jar",
+		AuthlibServer:       "https://skins.example.org/api/yggdrasil",
+		AuthlibPrefetched:   "eyJtZXRhIjp7fX0=",
+	}
+
+	args, err := BuildArguments(version, opts)
+	if err != nil {
+		t.Fatalf("Failed to build args: %v", err)
+	}
+
+	argStr := strings.Join(args, " ")
+
+	if !strings.Contains(argStr, "-javaagent:/path/to/authlib.jar=https://skins.example.org/api/yggdrasil") {
+		t.Errorf("Missing custom server javaagent argument. Got: %s", argStr)
+	}
+	if !strings.Contains(argStr, "-Dauthlibinjector.yggdrasil.prefetched=eyJtZXRhIjp7fX0=") {
+		t.Errorf("Missing prefetched metadata argument. Got: %s", argStr)
+	}
+}
+
 func TestBuildArguments_Borderless(t *testing.T) {
 	version := &models.VersionDetail{
 		ID:        "1.20.1",
@@ -27,10 +27,9 @@ func MergeVersions(child, parent *models.VersionDetail) *models.VersionDetail {
 	result.Libraries = deduplicateLibraries(child.Libraries, parent.Libraries)
 	result.Arguments = mergeArguments(child.Arguments, parent.Arguments)
 
-	if result.MinecraftArguments == "" && parent.MinecraftArguments != "" {
+	// Legacy minecraftArguments are a complete argument string, so the child's replaces the parent's.
+	if result.MinecraftArguments == "" {
 		result.MinecraftArguments = parent.MinecraftArguments
-	} else if result.MinecraftArguments != "" && parent.MinecraftArguments != "" {
-		result.MinecraftArguments = result.MinecraftArguments + " " + parent.MinecraftArguments
 	}
 
 	return &result
@@ -135,3 +135,25 @@ func TestMergeVersions_PreserveNatives(t *testing.T) {
 		t.Error("Failed to preserve child classifiers for windows")
 	}
 }
+
+func TestMergeVersionsLegacyArgumentsOverride(t *testing.T) {
+	parent := &models.VersionDetail{
+		ID:                 "1.7.10",
+		MinecraftArguments: "--username ${auth_player_name} --version ${version_name}",
+	}
+	child := &models.VersionDetail{
+		ID:                 "1.7.10-forge-10.13.4.1614-1.7.10",
+		InheritsFrom:       "1.7.10",
+		MinecraftArguments: "--username ${auth_player_name} --version ${version_name} --tweakClass cpw.mods.fml.common.launcher.FMLTweaker",
+	}
+
+	result := MergeVersions(child, parent)
+	if result.MinecraftArguments != child.MinecraftArguments {
+		t.Errorf("child arguments should replace the parent's, got %q", result.MinecraftArguments)
+	}
+
+	result = MergeVersions(&models.VersionDetail{ID: "child", InheritsFrom: "1.7.10"}, parent)
+	if result.MinecraftArguments != parent.MinecraftArguments {
+		t.Errorf("child without arguments should inherit the parent's, got %q", result.MinecraftArguments)
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/url"
+	"os"
 	"path/filepath"
 	"runtime"
 	"strings"
@@ -44,6 +45,20 @@ type VersionDetail struct {
 	Type               string        `json:"type"`
 }
 
+// IsValidVersionFile reports whether path holds a parseable, launchable version JSON for versionID.
+func IsValidVersionFile(path, versionID string) bool {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return false
+	}
+
+	var detail VersionDetail
+	if err := json.Unmarshal(data, &detail); err != nil {
+		return false
+	}
+	return detail.ID == versionID && (detail.MainClass.Client != "" || detail.InheritsFrom != "")
+}
+
 type MainClassData struct {
 	Client string `json:"client,omitempty"`
 	Server string `json:"server,omitempty"`
@@ -212,7 +227,18 @@ func (l *Library) IsAllowed(osName string) bool {
 }
 
 func (l *Library) GetMavenPath() string {
-	parts := strings.Split(l.Name, ":")
+	return MavenPath(l.Name)
+}
+
+// MavenPath converts group:artifact:version[:classifier][@ext] into a repository path.
+func MavenPath(coordinate string) string {
+	ext := "jar"
+	if idx := strings.LastIndex(coordinate, "@"); idx != -1 {
+		ext = coordinate[idx+1:]
+		coordinate = coordinate[:idx]
+	}
+
+	parts := strings.Split(coordinate, ":")
 	if len(parts) < 3 {
 		return ""
 	}
@@ -221,7 +247,12 @@ func (l *Library) GetMavenPath() string {
 	name := parts[1]
 	version := parts[2]
 
-	return fmt.Sprintf("%s/%s/%s/%s-%s.jar", domain, name, version, name, version)
+	file := fmt.Sprintf("%s-%s", name, version)
+	if len(parts) > 3 && parts[3] != "" {
+		file += "-" + parts[3]
+	}
+
+	return fmt.Sprintf("%s/%s/%s/%s.%s", domain, name, version, file, ext)
 }
 
 func normalizeOSName(name string) string {
@@ -0,0 +1,105 @@
+package modloader
+
+import (
+	"NezordLauncher/pkg/constants"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+type InstallOptions struct {
+	// Java resolves the runtime used by installer processors. It is only called by loaders
+	// that need one, so fabric-style installs never trigger a Java scan.
+	Java func() (string, error)
+	// GameDir is the instance's game directory, for loaders that place files there.
+	GameDir  string
+	OnStatus func(string)
+}
+
+// Version is a loader build or a supported game version, newest first in listings.
+type Version struct {
+	Version string `json:"version"`
+	Stable  bool   `json:"stable"`
+	Build   int    `json:"build,omitempty"`
+	// Maven is the loader's maven coordinate, when the provider publishes one.
+	Maven string `json:"maven,omitempty"`
+}
+
+// Versions returns the bare version strings.
+func Versions(list []Version) []string {
+	result := make([]string, 0, len(list))
+	for _, v := range list {
+		result = append(result, v.Version)
+	}
+	return result
+}
+
+// Provider installs one modloader on top of a vanilla version.
+type Provider interface {
+	ID() string
+	Name() string
+	LoaderVersions(gameVersion string) ([]Version, error)
+	GameVersions() ([]Version, error)
+	// Install makes the loader launchable and returns the installed version ID.
+	Install(gameVersion, loaderVersion string, opts InstallOptions) (string, error)
+	VersionID(gameVersion, loaderVersion string) string
+	Uninstall(gameVersion, loaderVersion string) error
+}
+
+var (
+	registryMu sync.RWMutex
+	registry   = map[string]Provider{}
+	order      []string
+)
+
+// Register adds a provider, replacing any previous provider with the same ID.
+func Register(p Provider) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	if _, exists := registry[p.ID()]; !exists {
+		order = append(order, p.ID())
+	}
+	registry[p.ID()] = p
+}
+
+func Get(id string) (Provider, bool) {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	p, ok := registry[id]
+	return p, ok
+}
+
+// List returns the providers in registration order.
+func List() []Provider {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	result := make([]Provider, 0, len(order))
+	for _, id := range order {
+		result = append(result, registry[id])
+	}
+	return result
+}
+
+func Lookup(id string) (Provider, error) {
+	p, ok := Get(id)
+	if !ok {
+		return nil, fmt.Errorf("unknown modloader: %s", id)
+	}
+	return p, nil
+}
+
+// removeVersion deletes an installed loader version; libraries stay shared with other versions.
+func removeVersion(versionID string) error {
+	if versionID == "" {
+		return fmt.Errorf("version id is required")
+	}
+	dir := filepath.Join(constants.GetVersionsDir(), versionID)
+	if err := os.RemoveAll(dir); err != nil {
+		return fmt.Errorf("failed to remove %s: %w", versionID, err)
+	}
+	return nil
+}
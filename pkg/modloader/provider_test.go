@@ -0,0 +1,201 @@
+package modloader
+
+import (
+	"NezordLauncher/pkg/constants"
+	"NezordLauncher/pkg/fabric"
+	"NezordLauncher/pkg/models"
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+type stubProvider struct {
+	fabricProvider
+	id string
+}
+
+func (p stubProvider) ID() string { return p.id }
+
+func TestRegistry(t *testing.T) {
+	var ids []string
+	for _, p := range List() {
+		ids = append(ids, p.ID())
+	}
+	want := []string{"fabric", "quilt", "legacyfabric", "babric", "forge", "neoforge", "optifine"}
+	if len(ids) < len(want) {
+		t.Fatalf("expected built-in providers %v, got %v", want, ids)
+	}
+	for i, id := range want {
+		if ids[i] != id {
+			t.Errorf("provider %d: got %s, want %s", i, ids[i], id)
+		}
+	}
+
+	Register(stubProvider{id: "stub"})
+	if _, ok := Get("stub"); !ok {
+		t.Error("registered provider not found")
+	}
+	if _, err := Lookup("missing"); err == nil {
+		t.Error("expected error for unknown modloader")
+	}
+}
+
+func TestVersionIDs(t *testing.T) {
+	cases := []struct {
+		id, game, loader, want string
+	}{
+		{"fabric", "1.20.1", "0.15.0", "fabric-loader-0.15.0-1.20.1"},
+		{"quilt", "1.20.1", "0.23.0", "quilt-loader-0.23.0-1.20.1"},
+		{"legacyfabric", "1.8.9", "0.15.0", "legacyfabric-loader-0.15.0-1.8.9"},
+		{"babric", "b1.7.3", "0.15.0", "babric-loader-0.15.0-b1.7.3"},
+		{"forge", "1.20.1", "47.2.0", "1.20.1-forge-47.2.0"},
+		{"neoforge", "1.21.1", "21.1.72", "neoforge-21.1.72"},
+		{"neoforge", "1.20.1", "47.1.106", "1.20.1-forge-47.1.106"},
+		{"optifine", "1.20.1", "HD_U_I6", "1.20.1-OptiFine_HD_U_I6"},
+	}
+	for _, c := range cases {
+		p, err := Lookup(c.id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := p.VersionID(c.game, c.loader); got != c.want {
+			t.Errorf("%s VersionID(%s, %s) = %s, want %s", c.id, c.game, c.loader, got, c.want)
+		}
+	}
+}
+
+func TestFabricProvider(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v2/versions/loader/1.20.1":
+			w.Write([]byte(`[{"loader":{"version":"0.15.0","build":250,"maven":"net.fabricmc:fabric-loader:0.15.0","stable":true}},
+				{"loader":{"version":"0.16.0-beta.1","build":251}}]`))
+		case "/v2/versions/game":
+			w.Write([]byte(`[{"version":"1.20.1","stable":true},{"version":"23w31a","stable":false}]`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	p := fabricProvider{meta: fabric.Meta{Name: "fabric", URL: server.URL, API: "v2"}, name: "Fabric"}
+
+	loaders, err := p.LoaderVersions("1.20.1")
+	if err != nil || len(loaders) != 2 {
+		t.Fatalf("unexpected loader versions %v (%v)", loaders, err)
+	}
+	want := Version{Version: "0.15.0", Stable: true, Build: 250, Maven: "net.fabricmc:fabric-loader:0.15.0"}
+	if loaders[0] != want || loaders[1].Stable {
+		t.Errorf("unexpected loader versions %+v", loaders)
+	}
+
+	games, err := p.GameVersions()
+	if err != nil || len(games) != 2 || games[0] != (Version{Version: "1.20.1", Stable: true}) || games[1].Stable {
+		t.Errorf("unexpected game versions %+v (%v)", games, err)
+	}
+
+	// Quilt has no stable flag and tags its pre-releases instead.
+	p.meta.TaggedPrereleases = true
+	loaders, _ = p.LoaderVersions("1.20.1")
+	if !loaders[0].Stable || loaders[1].Stable {
+		t.Errorf("stability should follow version tags: %+v", loaders)
+	}
+}
+
+func TestLegacyLoaderInstall(t *testing.T) {
+	cases := []struct {
+		id, game, intermediary string
+	}{
+		{"legacyfabric", "1.8.9", "net.legacyfabric:intermediary:1.8.9"},
+		{"babric", "b1.7.3", "babric:intermediary:b1.7.3"},
+	}
+	for _, c := range cases {
+		t.Run(c.id, func(t *testing.T) {
+			t.Setenv("NEZORD_DATA_DIR", t.TempDir())
+
+			jarSHA1 := sha1.Sum([]byte("jar"))
+			var server *httptest.Server
+			server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				switch r.URL.Path {
+				case "/v2/versions/loader/" + c.game:
+					w.Write([]byte(`[{"loader":{"version":"0.15.0","stable":true}}]`))
+				case "/v2/versions/loader/" + c.game + "/0.15.0/profile/json":
+					fmt.Fprintf(w, `{"mainClass": "net.fabricmc.loader.impl.launch.knot.KnotClient",
+						"libraries": [{"name": "net.fabricmc:fabric-loader:0.15.0"}, {"name": %q}]}`, c.intermediary)
+				default:
+					if strings.HasPrefix(r.URL.Path, "/maven/") && strings.HasSuffix(r.URL.Path, ".jar.sha1") {
+						w.Write([]byte(hex.EncodeToString(jarSHA1[:])))
+						return
+					}
+					http.NotFound(w, r)
+				}
+			}))
+			defer server.Close()
+
+			registered, err := Lookup(c.id)
+			if err != nil {
+				t.Fatal(err)
+			}
+			p := registered.(fabricProvider)
+			p.meta.URL = server.URL
+			p.meta.MavenURL = server.URL + "/maven/"
+
+			id, err := p.Install(c.game, "latest", InstallOptions{})
+			if err != nil {
+				t.Fatalf("Install failed: %v", err)
+			}
+			if want := c.id + "-loader-0.15.0-" + c.game; id != want {
+				t.Errorf("got version %s, want %s", id, want)
+			}
+
+			data, err := os.ReadFile(filepath.Join(constants.GetVersionsDir(), id, id+".json"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			var version models.VersionDetail
+			if err := json.Unmarshal(data, &version); err != nil {
+				t.Fatal(err)
+			}
+			if version.InheritsFrom != c.game || len(version.Libraries) != 2 {
+				t.Fatalf("unexpected version json %+v", version)
+			}
+			for _, lib := range version.Libraries {
+				if lib.Downloads.Artifact.SHA1 != hex.EncodeToString(jarSHA1[:]) || !strings.HasPrefix(lib.Downloads.Artifact.URL, server.URL+"/maven/") {
+					t.Errorf("%s was not pinned to the loader maven: %+v", lib.Name, lib.Downloads.Artifact)
+				}
+			}
+		})
+	}
+}
+
+func TestMavenVersions(t *testing.T) {
+	got := mavenVersions([]string{"21.1.73-beta", "21.1.72", "10.13.4.1614-1.7.10", "1.7.10_pre4"}, nil)
+	stable := []bool{false, true, true, false}
+	for i, v := range got {
+		if v.Stable != stable[i] {
+			t.Errorf("%s: stable %v, want %v", v.Version, v.Stable, stable[i])
+		}
+	}
+}
+
+func TestUninstallRemovesVersion(t *testing.T) {
+	t.Setenv("NEZORD_DATA_DIR", t.TempDir())
+
+	p, _ := Get("fabric")
+	dir := filepath.Join(constants.GetVersionsDir(), p.VersionID("1.20.1", "0.15.0"))
+	os.MkdirAll(dir, 0755)
+
+	if err := p.Uninstall("1.20.1", "0.15.0"); err != nil {
+		t.Fatalf("Uninstall failed: %v", err)
+	}
+	if _, err := os.Stat(dir); !os.IsNotExist(err) {
+		t.Error("version directory should be removed")
+	}
+}
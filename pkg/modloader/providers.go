@@ -0,0 +1,275 @@
+package modloader
+
+import (
+	"NezordLauncher/pkg/constants"
+	"NezordLauncher/pkg/fabric"
+	"NezordLauncher/pkg/forge"
+	"NezordLauncher/pkg/models"
+	"NezordLauncher/pkg/neoforge"
+	"NezordLauncher/pkg/optifine"
+	"NezordLauncher/pkg/quilt"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+func init() {
+	Register(fabricProvider{meta: fabric.Fabric, name: "Fabric"})
+	Register(fabricProvider{meta: quilt.Meta, name: "Quilt"})
+	Register(fabricProvider{meta: fabric.LegacyFabric, name: "LegacyFabric"})
+	Register(fabricProvider{meta: fabric.Babric, name: "Babric"})
+	Register(forgeProvider{})
+	Register(neoForgeProvider{})
+	Register(optiFineProvider{})
+}
+
+// fabricProvider serves any Fabric-compatible metadata service.
+type fabricProvider struct {
+	meta fabric.Meta
+	name string
+}
+
+func (p fabricProvider) ID() string   { return p.meta.Name }
+func (p fabricProvider) Name() string { return p.name }
+
+func (p fabricProvider) LoaderVersions(gameVersion string) ([]Version, error) {
+	loaders, err := p.meta.LoaderVersions(gameVersion)
+	if err != nil {
+		return nil, err
+	}
+
+	var versions []Version
+	for _, l := range loaders {
+		versions = append(versions, Version{
+			Version: l.Loader.Version,
+			Stable:  p.meta.IsStable(l.Loader),
+			Build:   l.Loader.Build,
+			Maven:   l.Loader.Maven,
+		})
+	}
+	return versions, nil
+}
+
+func (p fabricProvider) GameVersions() ([]Version, error) {
+	games, err := p.meta.GameVersions()
+	if err != nil {
+		return nil, err
+	}
+
+	var versions []Version
+	for _, g := range games {
+		versions = append(versions, Version{Version: g.Version, Stable: g.Stable})
+	}
+	return versions, nil
+}
+
+func (p fabricProvider) Install(gameVersion, loaderVersion string, opts InstallOptions) (string, error) {
+	return p.meta.Install(gameVersion, loaderVersion)
+}
+
+func (p fabricProvider) VersionID(gameVersion, loaderVersion string) string {
+	return p.meta.VersionID(gameVersion, loaderVersion)
+}
+
+func (p fabricProvider) Uninstall(gameVersion, loaderVersion string) error {
+	return removeVersion(p.VersionID(gameVersion, loaderVersion))
+}
+
+type forgeProvider struct{}
+
+func (forgeProvider) ID() string   { return "forge" }
+func (forgeProvider) Name() string { return "Forge" }
+
+func (forgeProvider) LoaderVersions(gameVersion string) ([]Version, error) {
+	versions, err := forge.GetForgeVersions(gameVersion)
+	if err != nil {
+		return nil, err
+	}
+	return mavenVersions(versions, func(v string) string {
+		return "net.minecraftforge:forge:" + gameVersion + "-" + v
+	}), nil
+}
+
+func (forgeProvider) GameVersions() ([]Version, error) {
+	versions, err := forge.GetGameVersions()
+	if err != nil {
+		return nil, err
+	}
+	return mavenVersions(versions, nil), nil
+}
+
+func (forgeProvider) Install(gameVersion, loaderVersion string, opts InstallOptions) (string, error) {
+	javaPath, err := resolveJava(opts)
+	if err != nil {
+		return "", err
+	}
+	id, err := forge.InstallForge(gameVersion, loaderVersion, forge.InstallOptions{JavaPath: javaPath, OnStatus: opts.OnStatus})
+	if err != nil {
+		return "", err
+	}
+	if opts.GameDir != "" {
+		if err := forge.EnsureFMLLibraries(gameVersion, opts.GameDir); err != nil {
+			return "", fmt.Errorf("failed to install fml libraries: %w", err)
+		}
+	}
+	return id, nil
+}
+
+func (forgeProvider) VersionID(gameVersion, loaderVersion string) string {
+	return gameVersion + "-forge-" + strings.TrimPrefix(loaderVersion, gameVersion+"-")
+}
+
+func (p forgeProvider) Uninstall(gameVersion, loaderVersion string) error {
+	return removeVersion(p.VersionID(gameVersion, loaderVersion))
+}
+
+type neoForgeProvider struct{}
+
+func (neoForgeProvider) ID() string   { return "neoforge" }
+func (neoForgeProvider) Name() string { return "NeoForge" }
+
+func (neoForgeProvider) LoaderVersions(gameVersion string) ([]Version, error) {
+	versions, err := neoforge.GetNeoForgeVersions(gameVersion)
+	if err != nil {
+		return nil, err
+	}
+	return mavenVersions(versions, func(v string) string {
+		return neoforge.Coordinate(gameVersion, v)
+	}), nil
+}
+
+func (neoForgeProvider) GameVersions() ([]Version, error) {
+	versions, err := neoforge.GetGameVersions()
+	if err != nil {
+		return nil, err
+	}
+	return mavenVersions(versions, nil), nil
+}
+
+func (neoForgeProvider) Install(gameVersion, loaderVersion string, opts InstallOptions) (string, error) {
+	javaPath, err := resolveJava(opts)
+	if err != nil {
+		return "", err
+	}
+	return neoforge.InstallNeoForge(gameVersion, loaderVersion, forge.InstallOptions{JavaPath: javaPath, OnStatus: opts.OnStatus})
+}
+
+func (neoForgeProvider) VersionID(gameVersion, loaderVersion string) string {
+	return neoforge.VersionID(gameVersion, loaderVersion)
+}
+
+func (p neoForgeProvider) Uninstall(gameVersion, loaderVersion string) error {
+	return removeVersion(p.VersionID(gameVersion, loaderVersion))
+}
+
+// optiFineProvider resolves standalone OptiFine versions. They can only be created from a
+// user-supplied installer jar, so only installed builds are listed.
+type optiFineProvider struct{}
+
+func (optiFineProvider) ID() string   { return "optifine" }
+func (optiFineProvider) Name() string { return "OptiFine" }
+
+func (optiFineProvider) LoaderVersions(gameVersion string) ([]Version, error) {
+	prefix := optifine.VersionID(gameVersion, "")
+	var versions []string
+	for _, id := range installedVersions() {
+		if strings.HasPrefix(id, prefix) {
+			versions = append(versions, strings.TrimPrefix(id, prefix))
+		}
+	}
+	if len(versions) == 0 {
+		return nil, fmt.Errorf("no optifine builds installed for %s", gameVersion)
+	}
+	forge.SortVersionsDesc(versions)
+
+	result := make([]Version, 0, len(versions))
+	for _, v := range versions {
+		// Preview builds are named like HD_U_I6_pre1.
+		result = append(result, Version{Version: v, Stable: !strings.Contains(v, "_pre")})
+	}
+	return result, nil
+}
+
+func (optiFineProvider) GameVersions() ([]Version, error) {
+	seen := make(map[string]bool)
+	var versions []string
+	for _, id := range installedVersions() {
+		if game, _, ok := strings.Cut(id, "-OptiFine_"); ok && !seen[game] {
+			seen[game] = true
+			versions = append(versions, game)
+		}
+	}
+	forge.SortVersionsDesc(versions)
+	return mavenVersions(versions, nil), nil
+}
+
+func (p optiFineProvider) Install(gameVersion, loaderVersion string, opts InstallOptions) (string, error) {
+	if loaderVersion == "latest" || loaderVersion == "" {
+		versions, err := p.LoaderVersions(gameVersion)
+		if err != nil {
+			return "", err
+		}
+		loaderVersion = versions[0].Version
+	}
+
+	versionID := p.VersionID(gameVersion, loaderVersion)
+	if !models.IsValidVersionFile(filepath.Join(constants.GetVersionsDir(), versionID, versionID+".json"), versionID) {
+		return "", fmt.Errorf("optifine %s for %s is not installed, add its installer jar first", loaderVersion, gameVersion)
+	}
+	return versionID, nil
+}
+
+func (optiFineProvider) VersionID(gameVersion, loaderVersion string) string {
+	return optifine.VersionID(gameVersion, loaderVersion)
+}
+
+func (p optiFineProvider) Uninstall(gameVersion, loaderVersion string) error {
+	return removeVersion(p.VersionID(gameVersion, loaderVersion))
+}
+
+// mavenVersions builds records for maven-hosted versions, where stable builds carry no tag such
+// as -beta. coordinate may be nil when there is no artifact, e.g. for game versions.
+func mavenVersions(versions []string, coordinate func(string) string) []Version {
+	result := make([]Version, 0, len(versions))
+	for _, v := range versions {
+		record := Version{Version: v, Stable: isReleaseVersion(v)}
+		if coordinate != nil {
+			record.Maven = coordinate(v)
+		}
+		result = append(result, record)
+	}
+	return result
+}
+
+// isReleaseVersion reports whether every component of a dotted version is numeric.
+func isReleaseVersion(version string) bool {
+	for _, part := range strings.FieldsFunc(version, func(r rune) bool { return r == '.' || r == '-' }) {
+		if _, err := strconv.Atoi(part); err != nil {
+			return false
+		}
+	}
+	return version != ""
+}
+
+func installedVersions() []string {
+	entries, err := os.ReadDir(constants.GetVersionsDir())
+	if err != nil {
+		return nil
+	}
+	var ids []string
+	for _, e := range entries {
+		if e.IsDir() {
+			ids = append(ids, e.Name())
+		}
+	}
+	return ids
+}
+
+func resolveJava(opts InstallOptions) (string, error) {
+	if opts.Java == nil {
+		return "", nil
+	}
+	return opts.Java()
+}
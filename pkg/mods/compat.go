@@ -0,0 +1,106 @@
+package mods
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Issue is a mod that declares it will not work with the checked setup.
+type Issue struct {
+	File   string `json:"file"`
+	ModID  string `json:"modId"`
+	Name   string `json:"name"`
+	Reason string `json:"reason"`
+}
+
+type Report struct {
+	GameVersion   string  `json:"gameVersion"`
+	LoaderType    string  `json:"loaderType"`
+	LoaderVersion string  `json:"loaderVersion"`
+	Checked       int     `json:"checked"`
+	Issues        []Issue `json:"issues"`
+}
+
+func (r *Report) Compatible() bool {
+	return len(r.Issues) == 0
+}
+
+// loaderDependencyIDs maps a modloader to the dependency ID mods use to constrain its version.
+var loaderDependencyIDs = map[string]string{
+	"fabric":       "fabricloader",
+	"legacyfabric": "fabricloader",
+	"babric":       "fabricloader",
+	"quilt":        "quilt_loader",
+	"forge":        "forge",
+	"neoforge":     "neoforge",
+}
+
+// CheckDir reports the enabled mods in modsDir whose metadata rules out the given game version,
+// modloader or loader version. An empty loaderVersion skips the loader version check.
+func CheckDir(modsDir, gameVersion, loaderType, loaderVersion string) (*Report, error) {
+	report := &Report{GameVersion: gameVersion, LoaderType: loaderType, LoaderVersion: loaderVersion, Issues: []Issue{}}
+
+	entries, err := os.ReadDir(modsDir)
+	if os.IsNotExist(err) {
+		return report, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read mods folder: %w", err)
+	}
+
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(strings.ToLower(entry.Name()), ".jar") {
+			continue
+		}
+
+		md, err := ReadMetadata(filepath.Join(modsDir, entry.Name()))
+		if err != nil || md == nil {
+			continue
+		}
+		report.Checked++
+
+		if reason := Check(md, gameVersion, loaderType, loaderVersion); reason != "" {
+			name := md.Name
+			if name == "" {
+				name = md.ID
+			}
+			report.Issues = append(report.Issues, Issue{File: entry.Name(), ModID: md.ID, Name: name, Reason: reason})
+		}
+	}
+
+	sort.Slice(report.Issues, func(i, j int) bool { return report.Issues[i].File < report.Issues[j].File })
+	return report, nil
+}
+
+// Check returns why a mod cannot run with the given setup, or "" if nothing rules it out.
+func Check(md *Metadata, gameVersion, loaderType, loaderVersion string) string {
+	if !containsString(md.Loaders, loaderType) {
+		if _, modded := loaderDependencyIDs[loaderType]; !modded {
+			return fmt.Sprintf("requires %s", strings.Join(md.Loaders, " or "))
+		}
+		return fmt.Sprintf("made for %s, not %s", strings.Join(md.Loaders, " or "), loaderType)
+	}
+
+	loaderDep := loaderDependencyIDs[loaderType]
+	for _, dep := range md.Depends {
+		switch {
+		case dep.ID == "minecraft" && !dep.Matches(gameVersion):
+			return fmt.Sprintf("requires Minecraft %s", dep.Range())
+		case dep.ID == loaderDep && loaderVersion != "" && !dep.Matches(loaderVersion):
+			return fmt.Sprintf("requires %s %s", loaderType, dep.Range())
+		}
+	}
+	return ""
+}
+
+func containsString(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,128 @@
+package mods
+
+import (
+	"archive/zip"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writeJar(t *testing.T, dir, name string, files map[string]string) {
+	t.Helper()
+	f, err := os.Create(filepath.Join(dir, name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	zw := zip.NewWriter(f)
+	for entry, content := range files {
+		w, _ := zw.Create(entry)
+		w.Write([]byte(content))
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestCheckDir(t *testing.T) {
+	dir := t.TempDir()
+	writeJar(t, dir, "sodium.jar", map[string]string{"fabric.mod.json": `{
+		"id": "sodium", "name": "Sodium", "version": "0.5.3",
+		"depends": {"minecraft": ["1.20", "1.20.1"], "fabricloader": ">=0.12.0"}
+	}`})
+	writeJar(t, dir, "lithium.jar", map[string]string{"fabric.mod.json": `{
+		"id": "lithium", "depends": {"minecraft": "~1.20.1", "fabricloader": ">=0.16.0"}
+	}`})
+	writeJar(t, dir, "jei.jar", map[string]string{"META-INF/mods.toml": `
+modLoader="javafml" # comment
+[[mods]]
+modId="jei"
+displayName="Just Enough Items"
+[[dependencies.jei]]
+    modId="minecraft"
+    mandatory=true
+    versionRange="[1.20.1,1.20.2)"
+`})
+	writeJar(t, dir, "library.jar", map[string]string{"META-INF/MANIFEST.MF": "Manifest-Version: 1.0"})
+	writeJar(t, dir, "old.jar.disabled", map[string]string{"fabric.mod.json": `{"id": "old", "depends": {"minecraft": "1.8.9"}}`})
+
+	report, err := CheckDir(dir, "1.20.1", "fabric", "0.15.0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Checked != 3 {
+		t.Errorf("expected 3 mods with metadata, got %d", report.Checked)
+	}
+	want := map[string]string{
+		"jei.jar":     "made for forge or neoforge, not fabric",
+		"lithium.jar": "requires fabric >=0.16.0",
+	}
+	if len(report.Issues) != len(want) {
+		t.Fatalf("unexpected issues %+v", report.Issues)
+	}
+	for _, issue := range report.Issues {
+		if want[issue.File] != issue.Reason {
+			t.Errorf("%s: reason %q, want %q", issue.File, issue.Reason, want[issue.File])
+		}
+	}
+
+	report, _ = CheckDir(dir, "1.21", "forge", "51.0.0")
+	reasons := map[string]string{}
+	for _, issue := range report.Issues {
+		reasons[issue.ModID] = issue.Reason
+	}
+	if reasons["jei"] != "requires Minecraft [1.20.1,1.20.2)" || reasons["sodium"] == "" {
+		t.Errorf("unexpected forge report %+v", report.Issues)
+	}
+
+	report, _ = CheckDir(filepath.Join(dir, "missing"), "1.20.1", "fabric", "")
+	if !report.Compatible() {
+		t.Error("a missing mods folder has nothing to report")
+	}
+}
+
+func TestVersionPredicates(t *testing.T) {
+	cases := []struct {
+		version, predicate string
+		want               bool
+	}{
+		{"1.20.1", "*", true},
+		{"1.20.1", ">=1.20 <1.21", true},
+		{"1.21", ">=1.20 <1.21", false},
+		{"1.20.4", "1.20.x", true},
+		{"1.21.1", "1.20.x", false},
+		{"1.20.6", "~1.20.1", true},
+		{"1.21", "~1.20.1", false},
+		{"0.15.11+build.1", "^0.15.0", true},
+		{"1.0.0", "^0.15.0", false},
+		{"2.0.0", "^1.0.0", false},
+		{"1.20.1", "1.20.1", true},
+	}
+	for _, c := range cases {
+		if got := matchesPredicate(c.version, c.predicate); got != c.want {
+			t.Errorf("matchesPredicate(%s, %q) = %v, want %v", c.version, c.predicate, got, c.want)
+		}
+	}
+}
+
+func TestMavenRanges(t *testing.T) {
+	cases := []struct {
+		version, spec string
+		want          bool
+	}{
+		{"47.2.0", "[47,)", true},
+		{"46.0.1", "[47,)", false},
+		{"1.20.1", "[1.20,1.21)", true},
+		{"1.21", "[1.20,1.21)", false},
+		{"1.21", "[1.20,1.21]", true},
+		{"1.20.1", "[1.20.1]", true},
+		{"1.19.2", "[1.18,1.18.2],[1.19,1.20)", true},
+		{"1.18.2", "(1.18.2,1.20)", false},
+		{"1.16.5", "1.20.1", true},
+	}
+	for _, c := range cases {
+		if got := matchesMavenRange(c.version, c.spec); got != c.want {
+			t.Errorf("matchesMavenRange(%s, %q) = %v, want %v", c.version, c.spec, got, c.want)
+		}
+	}
+}
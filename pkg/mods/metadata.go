@@ -0,0 +1,234 @@
+package mods
+
+import (
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Dependency is a required mod, game or loader version declared by a mod.
+type Dependency struct {
+	ID       string
+	Versions []string
+	// maven marks mods.toml ranges; the others are fabric-style predicates.
+	maven bool
+}
+
+func (d Dependency) Matches(version string) bool {
+	if d.maven {
+		for _, spec := range d.Versions {
+			if !matchesMavenRange(version, spec) {
+				return false
+			}
+		}
+		return true
+	}
+	return matchesAny(version, d.Versions)
+}
+
+// Range is the declared version range in the mod's own syntax.
+func (d Dependency) Range() string {
+	return strings.Join(d.Versions, " || ")
+}
+
+// Metadata is what a mod jar declares about itself.
+type Metadata struct {
+	ID      string
+	Name    string
+	Version string
+	// Loaders lists the modloader IDs able to load the mod.
+	Loaders []string
+	Depends []Dependency
+}
+
+var fabricLoaders = []string{"fabric", "quilt", "legacyfabric", "babric"}
+
+// ReadMetadata reads the mod metadata of a jar. It returns nil without an error for jars that
+// carry no metadata we understand.
+func ReadMetadata(jarPath string) (*Metadata, error) {
+	zr, err := zip.OpenReader(jarPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open mod: %w", err)
+	}
+	defer zr.Close()
+
+	if data, ok := readEntry(&zr.Reader, "quilt.mod.json"); ok {
+		return parseQuiltMod(data)
+	}
+	if data, ok := readEntry(&zr.Reader, "fabric.mod.json"); ok {
+		return parseFabricMod(data)
+	}
+	if data, ok := readEntry(&zr.Reader, "META-INF/neoforge.mods.toml"); ok {
+		return parseModsTOML(data, []string{"neoforge"}), nil
+	}
+	if data, ok := readEntry(&zr.Reader, "META-INF/mods.toml"); ok {
+		return parseModsTOML(data, []string{"forge", "neoforge"}), nil
+	}
+	return nil, nil
+}
+
+func parseFabricMod(data []byte) (*Metadata, error) {
+	var mod struct {
+		ID      string                     `json:"id"`
+		Name    string                     `json:"name"`
+		Version string                     `json:"version"`
+		Depends map[string]json.RawMessage `json:"depends"`
+	}
+	if err := json.Unmarshal(data, &mod); err != nil {
+		return nil, fmt.Errorf("failed to parse fabric.mod.json: %w", err)
+	}
+
+	md := &Metadata{ID: mod.ID, Name: mod.Name, Version: mod.Version, Loaders: fabricLoaders}
+	for id, raw := range mod.Depends {
+		md.Depends = append(md.Depends, Dependency{ID: id, Versions: stringOrList(raw)})
+	}
+	return md, nil
+}
+
+func parseQuiltMod(data []byte) (*Metadata, error) {
+	var mod struct {
+		Loader struct {
+			ID       string `json:"id"`
+			Version  string `json:"version"`
+			Metadata struct {
+				Name string `json:"name"`
+			} `json:"metadata"`
+			Depends []json.RawMessage `json:"depends"`
+		} `json:"quilt_loader"`
+	}
+	if err := json.Unmarshal(data, &mod); err != nil {
+		return nil, fmt.Errorf("failed to parse quilt.mod.json: %w", err)
+	}
+
+	md := &Metadata{
+		ID:      mod.Loader.ID,
+		Name:    mod.Loader.Metadata.Name,
+		Version: mod.Loader.Version,
+		Loaders: []string{"quilt"},
+	}
+	for _, raw := range mod.Loader.Depends {
+		var dep struct {
+			ID       string          `json:"id"`
+			Versions json.RawMessage `json:"versions"`
+			Optional bool            `json:"optional"`
+		}
+		if json.Unmarshal(raw, &dep) != nil {
+			// A bare string only requires the mod to be present.
+			continue
+		}
+		if !dep.Optional {
+			md.Depends = append(md.Depends, Dependency{ID: dep.ID, Versions: stringOrList(dep.Versions)})
+		}
+	}
+	return md, nil
+}
+
+// stringOrList decodes a version field that is either one predicate or a list of alternatives.
+// Other forms are treated as unconstrained.
+func stringOrList(raw json.RawMessage) []string {
+	var single string
+	if json.Unmarshal(raw, &single) == nil {
+		return []string{single}
+	}
+	var list []string
+	if json.Unmarshal(raw, &list) == nil {
+		return list
+	}
+	return nil
+}
+
+// parseModsTOML reads the first mod and the jar's required dependencies from a Forge-style
+// mods.toml. Only the flat key = "value" subset used by these files is understood.
+func parseModsTOML(data []byte, loaders []string) *Metadata {
+	md := &Metadata{Loaders: loaders}
+
+	var section string
+	var dep map[string]string
+	flush := func() {
+		if dep == nil {
+			return
+		}
+		required := dep["mandatory"] != "false" && (dep["type"] == "" || strings.EqualFold(dep["type"], "required"))
+		if required && !strings.EqualFold(dep["side"], "SERVER") && dep["modid"] != "" {
+			md.Depends = append(md.Depends, Dependency{ID: dep["modid"], Versions: []string{dep["versionrange"]}, maven: true})
+		}
+		dep = nil
+	}
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	modsSeen := 0
+	for scanner.Scan() {
+		line := strings.TrimSpace(stripComment(scanner.Text()))
+		if line == "" {
+			continue
+		}
+
+		if strings.HasPrefix(line, "[") {
+			flush()
+			section = strings.Trim(line, "[] ")
+			if section == "mods" {
+				modsSeen++
+			}
+			if strings.HasPrefix(section, "dependencies.") {
+				dep = map[string]string{}
+			}
+			continue
+		}
+
+		key, value, ok := strings.Cut(line, "=")
+		if !ok {
+			continue
+		}
+		key = strings.ToLower(strings.TrimSpace(key))
+		value = strings.Trim(strings.TrimSpace(value), `"'`)
+
+		switch {
+		case dep != nil:
+			dep[key] = value
+		case section == "mods" && modsSeen == 1:
+			switch key {
+			case "modid":
+				md.ID = value
+			case "displayname":
+				md.Name = value
+			case "version":
+				md.Version = value
+			}
+		}
+	}
+	flush()
+	return md
+}
+
+func stripComment(line string) string {
+	inQuote := false
+	for i, r := range line {
+		switch {
+		case r == '"':
+			inQuote = !inQuote
+		case r == '#' && !inQuote:
+			return line[:i]
+		}
+	}
+	return line
+}
+
+func readEntry(zr *zip.Reader, name string) ([]byte, bool) {
+	for _, f := range zr.File {
+		if f.Name != name {
+			continue
+		}
+		rc, err := f.Open()
+		if err != nil {
+			return nil, false
+		}
+		defer rc.Close()
+		data, err := io.ReadAll(rc)
+		return data, err == nil
+	}
+	return nil, false
+}
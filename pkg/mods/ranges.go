@@ -0,0 +1,151 @@
+package mods
+
+import (
+	"NezordLauncher/pkg/forge"
+	"strconv"
+	"strings"
+)
+
+// compareVersions compares loader and game versions, ignoring semver build metadata.
+func compareVersions(a, b string) int {
+	a, _, _ = strings.Cut(a, "+")
+	b, _, _ = strings.Cut(b, "+")
+	return forge.CompareVersions(a, b)
+}
+
+// matchesAny reports whether version satisfies any of the fabric-style predicates.
+func matchesAny(version string, predicates []string) bool {
+	if len(predicates) == 0 {
+		return true
+	}
+	for _, p := range predicates {
+		if matchesPredicate(version, p) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchesPredicate checks a fabric.mod.json predicate such as ">=1.20 <1.21", "~1.20.1" or "1.20.x".
+// Space-separated terms must all match.
+func matchesPredicate(version, predicate string) bool {
+	for _, term := range strings.Fields(predicate) {
+		if !matchesTerm(version, term) {
+			return false
+		}
+	}
+	return true
+}
+
+func matchesTerm(version, term string) bool {
+	if term == "*" {
+		return true
+	}
+
+	op := ""
+	for _, candidate := range []string{">=", "<=", ">", "<", "=", "~", "^"} {
+		if strings.HasPrefix(term, candidate) {
+			op = candidate
+			term = strings.TrimPrefix(term, candidate)
+			break
+		}
+	}
+
+	if prefix, ok := wildcardPrefix(term); ok {
+		return version == strings.TrimSuffix(prefix, ".") || strings.HasPrefix(version, prefix)
+	}
+
+	c := compareVersions(version, term)
+	switch op {
+	case ">=":
+		return c >= 0
+	case "<=":
+		return c <= 0
+	case ">":
+		return c > 0
+	case "<":
+		return c < 0
+	case "~":
+		return c >= 0 && compareVersions(version, bump(term, 1)) < 0
+	case "^":
+		return c >= 0 && compareVersions(version, bump(term, 0)) < 0
+	default:
+		return c == 0
+	}
+}
+
+// wildcardPrefix turns "1.20.x" into "1.20." so matching versions can be found by prefix.
+func wildcardPrefix(term string) (string, bool) {
+	parts := strings.Split(term, ".")
+	for i, part := range parts {
+		if part == "x" || part == "X" || part == "*" {
+			return strings.Join(parts[:i], ".") + ".", true
+		}
+	}
+	return "", false
+}
+
+// bump returns the smallest version above every version sharing term's first index+1 components,
+// e.g. bump("1.20.1", 1) is "1.21".
+func bump(term string, index int) string {
+	parts := strings.Split(term, ".")
+	if index >= len(parts) {
+		index = len(parts) - 1
+	}
+	n, err := strconv.Atoi(parts[index])
+	if err != nil {
+		return term
+	}
+	parts[index] = strconv.Itoa(n + 1)
+	return strings.Join(parts[:index+1], ".")
+}
+
+// matchesMavenRange checks a mods.toml versionRange such as "[1.20,1.21)" or "[47,)". Several
+// ranges are alternatives; a bare version is only a recommendation and always matches.
+func matchesMavenRange(version, spec string) bool {
+	spec = strings.TrimSpace(spec)
+	if spec == "" || spec == "*" || (spec[0] != '[' && spec[0] != '(') {
+		return true
+	}
+
+	for len(spec) > 0 {
+		end := strings.IndexAny(spec, "])")
+		if end < 0 {
+			return true
+		}
+		if matchesMavenClause(version, spec[:end+1]) {
+			return true
+		}
+		spec = strings.TrimLeft(spec[end+1:], ", ")
+	}
+	return false
+}
+
+func matchesMavenClause(version, clause string) bool {
+	if len(clause) < 2 {
+		return true
+	}
+	lowerInclusive := clause[0] == '['
+	upperInclusive := clause[len(clause)-1] == ']'
+	body := clause[1 : len(clause)-1]
+
+	lower, upper, isRange := strings.Cut(body, ",")
+	lower, upper = strings.TrimSpace(lower), strings.TrimSpace(upper)
+	if !isRange {
+		return compareVersions(version, lower) == 0
+	}
+
+	if lower != "" {
+		c := compareVersions(version, lower)
+		if c < 0 || (c == 0 && !lowerInclusive) {
+			return false
+		}
+	}
+	if upper != "" {
+		c := compareVersions(version, upper)
+		if c > 0 || (c == 0 && !upperInclusive) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,127 @@
+package neoforge
+
+import (
+	"NezordLauncher/pkg/forge"
+	"NezordLauncher/pkg/network"
+	"encoding/xml"
+	"fmt"
+	"os"
+	"strings"
+)
+
+const MavenURL = "https://maven.neoforged.net/releases/"
+
+// legacyGameVersion is the only release NeoForge shipped under the old net.neoforged:forge artifact.
+const legacyGameVersion = "1.20.1"
+
+// mavenURL returns the NeoForge maven root, overridable via NEZORD_NEOFORGE_MAVEN_URL.
+func mavenURL() string {
+	if override := os.Getenv("NEZORD_NEOFORGE_MAVEN_URL"); override != "" {
+		return strings.TrimSuffix(override, "/") + "/"
+	}
+	return MavenURL
+}
+
+type mavenMetadata struct {
+	Versioning struct {
+		Versions []string `xml:"versions>version"`
+	} `xml:"versioning"`
+}
+
+func artifactPath(gameVersion string) string {
+	if gameVersion == legacyGameVersion {
+		return "net/neoforged/forge"
+	}
+	return "net/neoforged/neoforge"
+}
+
+// Coordinate is the maven coordinate of a NeoForge build.
+func Coordinate(gameVersion, neoForgeVersion string) string {
+	if gameVersion == legacyGameVersion {
+		return "net.neoforged:forge:" + gameVersion + "-" + neoForgeVersion
+	}
+	return "net.neoforged:neoforge:" + neoForgeVersion
+}
+
+// GetNeoForgeVersions lists the NeoForge versions for a game version, newest first.
+func GetNeoForgeVersions(gameVersion string) ([]string, error) {
+	all, err := fetchVersions(mavenURL() + artifactPath(gameVersion) + "/maven-metadata.xml")
+	if err != nil {
+		return nil, err
+	}
+
+	var versions []string
+	for _, v := range all {
+		if gameVersion == legacyGameVersion {
+			if strings.HasPrefix(v, legacyGameVersion+"-") {
+				versions = append(versions, strings.TrimPrefix(v, legacyGameVersion+"-"))
+			}
+		} else if GameVersionFor(v) == gameVersion {
+			versions = append(versions, v)
+		}
+	}
+
+	if len(versions) == 0 {
+		return nil, fmt.Errorf("no neoforge versions found for %s", gameVersion)
+	}
+
+	forge.SortVersionsDesc(versions)
+	return versions, nil
+}
+
+// GetGameVersions lists the game versions NeoForge has builds for, newest first.
+func GetGameVersions() ([]string, error) {
+	all, err := fetchVersions(mavenURL() + artifactPath("") + "/maven-metadata.xml")
+	if err != nil {
+		return nil, err
+	}
+
+	seen := map[string]bool{legacyGameVersion: true}
+	versions := []string{legacyGameVersion}
+	for _, v := range all {
+		if game := GameVersionFor(v); game != "" && !seen[game] {
+			seen[game] = true
+			versions = append(versions, game)
+		}
+	}
+
+	forge.SortVersionsDesc(versions)
+	return versions, nil
+}
+
+// GameVersionFor maps a NeoForge version to its Minecraft version: 20.4.80 targets 1.20.4,
+// 21.0.10 targets 1.21 and year-based 26.1.0.5 targets 26.1.
+func GameVersionFor(version string) string {
+	version, _, _ = strings.Cut(version, "-")
+	parts := strings.Split(version, ".")
+	if len(parts) < 3 {
+		return ""
+	}
+
+	if len(parts) >= 4 {
+		if parts[2] == "0" {
+			return parts[0] + "." + parts[1]
+		}
+		return parts[0] + "." + parts[1] + "." + parts[2]
+	}
+
+	if parts[1] == "0" {
+		return "1." + parts[0]
+	}
+	return "1." + parts[0] + "." + parts[1]
+}
+
+func fetchVersions(url string) ([]string, error) {
+	client := network.NewHttpClient()
+	data, err := client.GetCached(url, network.MetaTTL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch neoforge versions: %w", err)
+	}
+
+	var meta mavenMetadata
+	if err := xml.Unmarshal(data, &meta); err != nil {
+		network.InvalidateCached(url)
+		return nil, fmt.Errorf("failed to parse neoforge versions: %w", err)
+	}
+	return meta.Versioning.Versions, nil
+}
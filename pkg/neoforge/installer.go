@@ -0,0 +1,59 @@
+package neoforge
+
+import (
+	"NezordLauncher/pkg/constants"
+	"NezordLauncher/pkg/forge"
+	"NezordLauncher/pkg/models"
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// VersionID is the ID NeoForge installers give their version JSON.
+func VersionID(gameVersion, neoForgeVersion string) string {
+	if gameVersion == legacyGameVersion {
+		return fmt.Sprintf("%s-forge-%s", gameVersion, neoForgeVersion)
+	}
+	return "neoforge-" + neoForgeVersion
+}
+
+// InstallNeoForge installs a NeoForge version and returns its version ID. opts.JavaPath runs
+// the installer processors; GameVersion and Mirrors are filled in.
+func InstallNeoForge(gameVersion, neoForgeVersion string, opts forge.InstallOptions) (string, error) {
+	if neoForgeVersion == "latest" || neoForgeVersion == "" {
+		versions, err := GetNeoForgeVersions(gameVersion)
+		if err != nil {
+			return "", err
+		}
+		neoForgeVersion = versions[0]
+		for _, v := range versions {
+			if !strings.Contains(v, "-") {
+				neoForgeVersion = v
+				break
+			}
+		}
+	}
+	neoForgeVersion = strings.TrimPrefix(neoForgeVersion, gameVersion+"-")
+
+	versionID := VersionID(gameVersion, neoForgeVersion)
+	if models.IsValidVersionFile(filepath.Join(constants.GetVersionsDir(), versionID, versionID+".json"), versionID) {
+		return versionID, nil
+	}
+
+	artifact := artifactPath(gameVersion)
+	full := neoForgeVersion
+	if gameVersion == legacyGameVersion {
+		full = gameVersion + "-" + neoForgeVersion
+	}
+	name := filepath.Base(artifact)
+	relPath := fmt.Sprintf("%s/%s/%s-%s-installer.jar", artifact, full, name, full)
+	installerPath := filepath.Join(constants.GetLibrariesDir(), filepath.FromSlash(relPath))
+
+	if err := forge.EnsureInstaller(mavenURL()+relPath, installerPath); err != nil {
+		return "", fmt.Errorf("failed to download neoforge installer: %w", err)
+	}
+
+	opts.GameVersion = gameVersion
+	opts.Mirrors = map[string]string{MavenURL: mavenURL()}
+	return forge.InstallFromJar(installerPath, opts)
+}
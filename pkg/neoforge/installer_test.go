@@ -0,0 +1,129 @@
+package neoforge
+
+import (
+	"NezordLauncher/pkg/constants"
+	"NezordLauncher/pkg/forge"
+	"NezordLauncher/pkg/models"
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func buildInstaller(t *testing.T, version string) []byte {
+	t.Helper()
+	profile := map[string]interface{}{
+		"spec":      1,
+		"minecraft": "1.21.1",
+		"json":      "/version.json",
+		"data":      map[string]interface{}{},
+	}
+	detail := map[string]interface{}{
+		"id":           "neoforge-" + version,
+		"inheritsFrom": "1.21.1",
+		"mainClass":    "cpw.mods.bootstraplauncher.BootstrapLauncher",
+		"libraries": []map[string]interface{}{
+			{
+				"name": "net.neoforged.fancymodloader:loader:4.0.24",
+				"downloads": map[string]interface{}{"artifact": map[string]interface{}{
+					"path": "net/neoforged/fancymodloader/loader/4.0.24/loader-4.0.24.jar",
+					"url":  MavenURL + "net/neoforged/fancymodloader/loader/4.0.24/loader-4.0.24.jar",
+				}},
+			},
+		},
+	}
+
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for name, value := range map[string]interface{}{"install_profile.json": profile, "version.json": detail} {
+		w, _ := zw.Create(name)
+		json.NewEncoder(w).Encode(value)
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestInstallNeoForge(t *testing.T) {
+	t.Setenv("NEZORD_DATA_DIR", t.TempDir())
+
+	var installerRequests []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/net/neoforged/neoforge/maven-metadata.xml":
+			w.Write([]byte(`<metadata><versioning><versions>
+				<version>20.4.80-beta</version>
+				<version>21.0.10</version>
+				<version>21.1.72</version>
+				<version>21.1.73-beta</version>
+			</versions></versioning></metadata>`))
+		case strings.HasSuffix(r.URL.Path, "-installer.jar"):
+			installerRequests = append(installerRequests, r.URL.Path)
+			w.Write(buildInstaller(t, "21.1.72"))
+		case strings.HasSuffix(r.URL.Path, "loader-4.0.24.jar"):
+			w.Write([]byte("fml"))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+	t.Setenv("NEZORD_NEOFORGE_MAVEN_URL", server.URL)
+
+	versions, err := GetNeoForgeVersions("1.21.1")
+	if err != nil {
+		t.Fatalf("GetNeoForgeVersions failed: %v", err)
+	}
+	if len(versions) != 2 || versions[0] != "21.1.73-beta" {
+		t.Errorf("unexpected versions: %v", versions)
+	}
+
+	id, err := InstallNeoForge("1.21.1", "latest", forge.InstallOptions{})
+	if err != nil {
+		t.Fatalf("InstallNeoForge failed: %v", err)
+	}
+	if id != "neoforge-21.1.72" {
+		t.Errorf("latest should prefer the newest stable build, got %s", id)
+	}
+	if len(installerRequests) != 1 || installerRequests[0] != "/net/neoforged/neoforge/21.1.72/neoforge-21.1.72-installer.jar" {
+		t.Errorf("unexpected installer requests: %v", installerRequests)
+	}
+
+	data, err := os.ReadFile(filepath.Join(constants.GetVersionsDir(), id, id+".json"))
+	if err != nil {
+		t.Fatalf("version json not written: %v", err)
+	}
+	var version models.VersionDetail
+	if err := json.Unmarshal(data, &version); err != nil {
+		t.Fatal(err)
+	}
+	if version.InheritsFrom != "1.21.1" {
+		t.Errorf("version should inherit from vanilla, got %q", version.InheritsFrom)
+	}
+
+	lib := filepath.Join(constants.GetLibrariesDir(), "net/neoforged/fancymodloader/loader/4.0.24/loader-4.0.24.jar")
+	if _, err := os.Stat(lib); err != nil {
+		t.Errorf("library was not fetched from the overridden maven: %v", err)
+	}
+}
+
+func TestGameVersionFor(t *testing.T) {
+	cases := map[string]string{
+		"20.4.80-beta": "1.20.4",
+		"21.0.10":      "1.21",
+		"21.1.72":      "1.21.1",
+		"26.1.0.5":     "26.1",
+		"26.1.1.2":     "26.1.1",
+		"bogus":        "",
+	}
+	for version, want := range cases {
+		if got := GameVersionFor(version); got != want {
+			t.Errorf("GameVersionFor(%s) = %q, want %q", version, got, want)
+		}
+	}
+}
@@ -0,0 +1,62 @@
+package network
+
+import (
+	"NezordLauncher/pkg/constants"
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// MetaTTL is how long loader metadata is served from disk before it is refreshed.
+const MetaTTL = time.Hour
+
+func metaCachePath(url string) string {
+	sum := sha1.Sum([]byte(url))
+	return filepath.Join(constants.GetDataDir(), "cache", "meta", hex.EncodeToString(sum[:]))
+}
+
+// GetCached returns the cached response for url while it is younger than ttl, and otherwise
+// refreshes it. When the refresh fails, a stale copy is served instead of the error so
+// installed content keeps working offline.
+func (c *HttpClient) GetCached(url string, ttl time.Duration) ([]byte, error) {
+	path := metaCachePath(url)
+
+	if info, err := os.Stat(path); err == nil && time.Since(info.ModTime()) < ttl {
+		if data, err := os.ReadFile(path); err == nil {
+			return data, nil
+		}
+	}
+
+	data, err := c.Get(url)
+	if err != nil {
+		if stale, readErr := os.ReadFile(path); readErr == nil {
+			fmt.Printf("Network failed, using stale cache for %s: %v\n", url, err)
+			return stale, nil
+		}
+		return nil, err
+	}
+
+	if err := writeCache(path, data); err != nil {
+		fmt.Printf("Failed to write metadata cache: %v\n", err)
+	}
+	return data, nil
+}
+
+// InvalidateCached drops the cached response for url, e.g. after it turned out to be unparseable.
+func InvalidateCached(url string) {
+	os.Remove(metaCachePath(url))
+}
+
+func writeCache(path string, data []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
@@ -0,0 +1,66 @@
+package network
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestGetCached(t *testing.T) {
+	t.Setenv("NEZORD_DATA_DIR", t.TempDir())
+
+	requests := 0
+	body := "v1"
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Write([]byte(body))
+	}))
+	defer ts.Close()
+
+	client := NewHttpClient()
+	for i := 0; i < 2; i++ {
+		data, err := client.GetCached(ts.URL, time.Hour)
+		if err != nil || string(data) != "v1" {
+			t.Fatalf("unexpected response %q (%v)", data, err)
+		}
+	}
+	if requests != 1 {
+		t.Errorf("fresh cache should not hit the network, got %d requests", requests)
+	}
+
+	// Expire the entry and make sure it is refreshed.
+	old := time.Now().Add(-2 * time.Hour)
+	os.Chtimes(metaCachePath(ts.URL), old, old)
+	body = "v2"
+	data, err := client.GetCached(ts.URL, time.Hour)
+	if err != nil || string(data) != "v2" || requests != 2 {
+		t.Errorf("expired cache was not refreshed: %q (%v, %d requests)", data, err, requests)
+	}
+}
+
+func TestGetCachedServesStaleOnError(t *testing.T) {
+	t.Setenv("NEZORD_DATA_DIR", t.TempDir())
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("cached"))
+	}))
+	url := ts.URL
+
+	client := NewHttpClient()
+	if _, err := client.GetCached(url, time.Hour); err != nil {
+		t.Fatal(err)
+	}
+	ts.Close()
+
+	data, err := client.GetCached(url, 0)
+	if err != nil || string(data) != "cached" {
+		t.Errorf("expected stale response while offline, got %q (%v)", data, err)
+	}
+
+	InvalidateCached(url)
+	if _, err := client.GetCached(url, 0); err == nil {
+		t.Error("expected error without network or cache")
+	}
+}
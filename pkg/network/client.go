@@ -3,30 +3,43 @@ package network
 import (
 	"NezordLauncher/pkg/constants"
 	"bytes"
+	"context"
 	"fmt"
 	"io"
 	"math"
+	"net"
 	"net/http"
 	"runtime"
 	"time"
 )
 
+// DefaultIdleTimeout is how long a response body may stall before the request is aborted.
+const DefaultIdleTimeout = 30 * time.Second
+
 type HttpClient struct {
 	client *http.Client
+	// IdleTimeout replaces a total request timeout: bodies may stream for as long as data keeps
+	// arriving, so large files on slow links are not cut off mid-transfer.
+	IdleTimeout time.Duration
 }
 
 func NewHttpClient() *HttpClient {
 	t := &http.Transport{
-		MaxIdleConns:        100,
-		MaxIdleConnsPerHost: 20,
-		IdleConnTimeout:     90 * time.Second,
+		Proxy: http.ProxyFromEnvironment,
+		DialContext: (&net.Dialer{
+			Timeout:   15 * time.Second,
+			KeepAlive: 30 * time.Second,
+		}).DialContext,
+		TLSHandshakeTimeout:   15 * time.Second,
+		ResponseHeaderTimeout: 30 * time.Second,
+		MaxIdleConns:          100,
+		MaxIdleConnsPerHost:   20,
+		IdleConnTimeout:       90 * time.Second,
 	}
 
 	return &HttpClient{
-		client: &http.Client{
-			Transport: t,
-			Timeout:   30 * time.Second,
-		},
+		client:      &http.Client{Transport: t},
+		IdleTimeout: DefaultIdleTimeout,
 	}
 }
 
@@ -42,7 +55,7 @@ func (c *HttpClient) doGet(url string) ([]byte, int, error) {
 
 	req.Header.Set("User-Agent", c.getUserAgent())
 
-	resp, err := c.client.Do(req)
+	resp, err := c.Do(req)
 	if err != nil {
 		return nil, 0, err
 	}
@@ -56,7 +69,38 @@ func (c *HttpClient) doGet(url string) ([]byte, int, error) {
 	return body, resp.StatusCode, nil
 }
 
+// Get fetches url, falling back through its configured mirrors in order. When every candidate
+// fails, the upstream's error is returned so callers can still tell a 404 apart.
 func (c *HttpClient) Get(url string) ([]byte, error) {
+	var lastErr, upstreamErr error
+	for _, candidate := range Candidates(url) {
+		body, err := c.getWithRetry(candidate)
+		if err == nil {
+			return body, nil
+		}
+		if candidate == url {
+			upstreamErr = err
+		} else if IsMirrorFault(err) {
+			ReportMirrorFailure(candidate)
+		}
+		lastErr = err
+	}
+	if upstreamErr != nil {
+		return nil, upstreamErr
+	}
+	return nil, lastErr
+}
+
+// StatusError is a non-200 answer to a GET.
+type StatusError struct {
+	StatusCode int
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("request failed with status: %d", e.StatusCode)
+}
+
+func (c *HttpClient) getWithRetry(url string) ([]byte, error) {
 	var lastErr error
 	maxRetries := 3
 
@@ -72,7 +116,7 @@ func (c *HttpClient) Get(url string) ([]byte, error) {
 		}
 
 		if statusCode != http.StatusOK {
-			lastErr = fmt.Errorf("request failed with status: %d", statusCode)
+			lastErr = &StatusError{StatusCode: statusCode}
 			if statusCode >= 500 {
 				continue
 			}
@@ -86,37 +130,58 @@ func (c *HttpClient) Get(url string) ([]byte, error) {
 }
 
 func (c *HttpClient) PostJSON(url string, body []byte) ([]byte, error) {
-	req, err := http.NewRequest("POST", url, bytes.NewBuffer(body))
+	data, statusCode, err := c.PostJSONWithStatus(url, body)
 	if err != nil {
 		return nil, err
 	}
 
+	if statusCode != http.StatusOK {
+		return nil, fmt.Errorf("request failed with status %d: %s", statusCode, string(data))
+	}
+
+	return data, nil
+}
+
+func (c *HttpClient) PostJSONWithStatus(url string, body []byte) ([]byte, int, error) {
+	req, err := http.NewRequest("POST", url, bytes.NewBuffer(body))
+	if err != nil {
+		return nil, 0, err
+	}
+
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("User-Agent", c.getUserAgent())
 
-	resp, err := c.client.Do(req)
+	resp, err := c.Do(req)
 	if err != nil {
-		return nil, err
+		return nil, 0, err
 	}
 	defer resp.Body.Close()
 
 	data, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return nil, err
-	}
-
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("request failed with status %d: %s", resp.StatusCode, string(data))
+		return nil, resp.StatusCode, err
 	}
 
-	return data, nil
+	return data, resp.StatusCode, nil
 }
 
+// Do sends req. The response body aborts with ErrIdleTimeout when it stalls for IdleTimeout.
 func (c *HttpClient) Do(req *http.Request) (*http.Response, error) {
 	if req.Header.Get("User-Agent") == "" {
 		req.Header.Set("User-Agent", c.getUserAgent())
 	}
-	return c.client.Do(req)
+	if c.IdleTimeout <= 0 {
+		return c.client.Do(req)
+	}
+
+	ctx, cancel := context.WithCancel(req.Context())
+	resp, err := c.client.Do(req.WithContext(ctx))
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	resp.Body = watchIdle(resp.Body, cancel, c.IdleTimeout)
+	return resp, nil
 }
 
 func (c *HttpClient) DoWithRetry(req *http.Request) (*http.Response, error) {
@@ -0,0 +1,51 @@
+package network
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sync/atomic"
+	"time"
+)
+
+// ErrIdleTimeout is returned by response body reads after no bytes arrived for the client's
+// IdleTimeout.
+var ErrIdleTimeout = errors.New("connection stalled")
+
+// idleBody cancels its request once reads stall, so a slow but steady transfer may take as long
+// as it needs while a dead one is dropped.
+type idleBody struct {
+	body     io.ReadCloser
+	cancel   context.CancelFunc
+	timer    *time.Timer
+	timeout  time.Duration
+	timedOut atomic.Bool
+}
+
+func watchIdle(body io.ReadCloser, cancel context.CancelFunc, timeout time.Duration) *idleBody {
+	b := &idleBody{body: body, cancel: cancel, timeout: timeout}
+	b.timer = time.AfterFunc(timeout, func() {
+		b.timedOut.Store(true)
+		cancel()
+	})
+	return b
+}
+
+func (b *idleBody) Read(p []byte) (int, error) {
+	n, err := b.body.Read(p)
+	if n > 0 {
+		b.timer.Reset(b.timeout)
+	}
+	if err != nil && err != io.EOF && b.timedOut.Load() {
+		err = fmt.Errorf("%w: no data for %s", ErrIdleTimeout, b.timeout)
+	}
+	return n, err
+}
+
+func (b *idleBody) Close() error {
+	b.timer.Stop()
+	err := b.body.Close()
+	b.cancel()
+	return err
+}
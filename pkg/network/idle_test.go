@@ -0,0 +1,69 @@
+package network
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func streamChunks(chunks int, gap time.Duration) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		for i := 0; i < chunks; i++ {
+			w.Write([]byte("chunk"))
+			w.(http.Flusher).Flush()
+			select {
+			case <-time.After(gap):
+			case <-r.Context().Done():
+				return
+			}
+		}
+	}
+}
+
+func TestIdleTimeoutAllowsSlowStreams(t *testing.T) {
+	ts := httptest.NewServer(streamChunks(5, 50*time.Millisecond))
+	defer ts.Close()
+
+	client := NewHttpClient()
+	client.IdleTimeout = 200 * time.Millisecond
+
+	req, _ := http.NewRequest("GET", ts.URL, nil)
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatalf("Do failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	// The whole transfer outlasts the idle timeout, but data never stops for that long.
+	data, err := io.ReadAll(resp.Body)
+	if err != nil || len(data) != 25 {
+		t.Fatalf("expected full body, got %d bytes (%v)", len(data), err)
+	}
+}
+
+func TestIdleTimeoutAbortsStalledStreams(t *testing.T) {
+	ts := httptest.NewServer(streamChunks(2, 10*time.Second))
+	defer ts.Close()
+
+	client := NewHttpClient()
+	client.IdleTimeout = 100 * time.Millisecond
+
+	req, _ := http.NewRequest("GET", ts.URL, nil)
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatalf("Do failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	start := time.Now()
+	_, err = io.ReadAll(resp.Body)
+	if !errors.Is(err, ErrIdleTimeout) {
+		t.Fatalf("expected idle timeout, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("stalled read took %s to abort", elapsed)
+	}
+}
@@ -0,0 +1,145 @@
+package network
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"time"
+)
+
+// MirrorRule sends requests for an upstream URL prefix to mirrors, tried in order.
+type MirrorRule struct {
+	// Upstream is the mirrored prefix, e.g. https://libraries.minecraft.net.
+	Upstream string   `json:"upstream"`
+	Mirrors  []string `json:"mirrors"`
+	// Exclusive drops the upstream as the last fallback, e.g. to run against a local test repository.
+	Exclusive bool `json:"exclusive,omitempty"`
+}
+
+const bmclapiURL = "https://bmclapi2.bangbang93.com"
+
+// MirrorPresets are the built-in mirror sets selectable by name.
+var MirrorPresets = map[string][]MirrorRule{
+	"bmclapi": {
+		{Upstream: "https://piston-meta.mojang.com", Mirrors: []string{bmclapiURL}},
+		{Upstream: "https://launchermeta.mojang.com", Mirrors: []string{bmclapiURL}},
+		{Upstream: "https://piston-data.mojang.com", Mirrors: []string{bmclapiURL}},
+		{Upstream: "https://launcher.mojang.com", Mirrors: []string{bmclapiURL}},
+		{Upstream: "https://resources.download.minecraft.net", Mirrors: []string{bmclapiURL + "/assets"}},
+		{Upstream: "https://libraries.minecraft.net", Mirrors: []string{bmclapiURL + "/maven"}},
+		{Upstream: "https://meta.fabricmc.net", Mirrors: []string{bmclapiURL + "/fabric-meta"}},
+		{Upstream: "https://maven.fabricmc.net", Mirrors: []string{bmclapiURL + "/maven"}},
+		{Upstream: "https://maven.minecraftforge.net", Mirrors: []string{bmclapiURL + "/maven"}},
+		{Upstream: "https://maven.neoforged.net/releases", Mirrors: []string{bmclapiURL + "/maven"}},
+	},
+}
+
+// mirrorCooldown is how long a failing mirror is moved behind the other candidates.
+const mirrorCooldown = 5 * time.Minute
+
+var (
+	mirrorMu    sync.RWMutex
+	mirrorRules []MirrorRule
+	mirrorDown  = map[string]time.Time{}
+)
+
+// SetMirrors replaces the active rules. Rules listed first win over later rules for the same
+// upstream, so custom rules can be placed ahead of a preset.
+func SetMirrors(rules []MirrorRule) {
+	normalized := make([]MirrorRule, 0, len(rules))
+	for _, r := range rules {
+		r.Upstream = strings.TrimRight(strings.TrimSpace(r.Upstream), "/")
+		if r.Upstream == "" {
+			continue
+		}
+		mirrors := make([]string, 0, len(r.Mirrors))
+		for _, m := range r.Mirrors {
+			if m = strings.TrimRight(strings.TrimSpace(m), "/"); m != "" {
+				mirrors = append(mirrors, m)
+			}
+		}
+		r.Mirrors = mirrors
+		normalized = append(normalized, r)
+	}
+
+	mirrorMu.Lock()
+	defer mirrorMu.Unlock()
+	mirrorRules = normalized
+	mirrorDown = map[string]time.Time{}
+}
+
+// Candidates returns the URLs to try for url: the mirrors of the longest matching rule, then url
+// itself unless the rule is exclusive, then mirrors that failed recently.
+func Candidates(url string) []string {
+	mirrorMu.RLock()
+	defer mirrorMu.RUnlock()
+
+	rule, ok := matchRule(url)
+	if !ok {
+		return []string{url}
+	}
+
+	rest := url[len(rule.Upstream):]
+	var healthy, down []string
+	for _, m := range rule.Mirrors {
+		if since, failed := mirrorDown[m]; failed && time.Since(since) < mirrorCooldown {
+			down = append(down, m+rest)
+		} else {
+			healthy = append(healthy, m+rest)
+		}
+	}
+	candidates := healthy
+	if !rule.Exclusive || len(rule.Mirrors) == 0 {
+		candidates = append(candidates, url)
+	}
+	return append(candidates, down...)
+}
+
+// ReportMirrorFailure marks the mirror serving candidate as failing, e.g. after a hash mismatch.
+// Upstream URLs are ignored.
+func ReportMirrorFailure(candidate string) {
+	mirrorMu.Lock()
+	defer mirrorMu.Unlock()
+	for _, r := range mirrorRules {
+		for _, m := range r.Mirrors {
+			if hasURLPrefix(candidate, m) {
+				mirrorDown[m] = time.Now()
+				return
+			}
+		}
+	}
+}
+
+// IsMirrorFault reports whether err says something about the host that served it: network
+// errors, 5xx answers and bad content do, while cancellation and 4xx answers do not.
+func IsMirrorFault(err error) bool {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	var statusErr *StatusError
+	if errors.As(err, &statusErr) {
+		return statusErr.StatusCode >= 500
+	}
+	return true
+}
+
+func matchRule(url string) (MirrorRule, bool) {
+	var best MirrorRule
+	found := false
+	for _, r := range mirrorRules {
+		if hasURLPrefix(url, r.Upstream) && (!found || len(r.Upstream) > len(best.Upstream)) {
+			best, found = r, true
+		}
+	}
+	return best, found
+}
+
+// hasURLPrefix matches whole path segments, so https://a.net/maven does not match https://a.net/mavenx.
+func hasURLPrefix(url, prefix string) bool {
+	if !strings.HasPrefix(url, prefix) {
+		return false
+	}
+	rest := url[len(prefix):]
+	return rest == "" || rest[0] == '/' || rest[0] == '?'
+}
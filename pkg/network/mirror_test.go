@@ -0,0 +1,120 @@
+package network
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestCandidates(t *testing.T) {
+	t.Cleanup(func() { SetMirrors(nil) })
+	SetMirrors([]MirrorRule{
+		{Upstream: "https://libraries.minecraft.net/", Mirrors: []string{"https://a.example/maven/", "https://b.example/maven"}},
+		{Upstream: "https://maven.example/releases", Mirrors: []string{"http://localhost:8080"}, Exclusive: true},
+		{Upstream: "https://maven.example", Mirrors: []string{"https://c.example"}},
+	})
+
+	tests := []struct {
+		url  string
+		want []string
+	}{
+		{"https://libraries.minecraft.net/org/lwjgl/lwjgl.jar", []string{
+			"https://a.example/maven/org/lwjgl/lwjgl.jar",
+			"https://b.example/maven/org/lwjgl/lwjgl.jar",
+			"https://libraries.minecraft.net/org/lwjgl/lwjgl.jar",
+		}},
+		// The longest upstream wins, and exclusive rules never reach the upstream.
+		{"https://maven.example/releases/a.jar", []string{"http://localhost:8080/a.jar"}},
+		{"https://maven.example/snapshots/a.jar", []string{"https://c.example/snapshots/a.jar", "https://maven.example/snapshots/a.jar"}},
+		{"https://maven.example.org/a.jar", []string{"https://maven.example.org/a.jar"}},
+	}
+	for _, tt := range tests {
+		if got := Candidates(tt.url); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Candidates(%q) = %v, want %v", tt.url, got, tt.want)
+		}
+	}
+
+	ReportMirrorFailure("https://a.example/maven/org/lwjgl/lwjgl.jar")
+	got := Candidates("https://libraries.minecraft.net/x.jar")
+	want := []string{"https://b.example/maven/x.jar", "https://libraries.minecraft.net/x.jar", "https://a.example/maven/x.jar"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("failed mirror should be tried last, got %v", got)
+	}
+}
+
+func TestGetFallsBackThroughMirrors(t *testing.T) {
+	t.Cleanup(func() { SetMirrors(nil) })
+
+	broken := httptest.NewServer(http.NotFoundHandler())
+	defer broken.Close()
+	mirror := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("mirrored " + r.URL.Path))
+	}))
+	defer mirror.Close()
+
+	SetMirrors([]MirrorRule{{
+		Upstream:  "https://piston-meta.mojang.com",
+		Mirrors:   []string{broken.URL, mirror.URL},
+		Exclusive: true,
+	}})
+
+	data, err := NewHttpClient().Get("https://piston-meta.mojang.com/mc/game/version_manifest_v2.json")
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	if string(data) != "mirrored /mc/game/version_manifest_v2.json" {
+		t.Errorf("unexpected response %q", data)
+	}
+}
+
+func TestGetPrefersUpstreamError(t *testing.T) {
+	t.Cleanup(func() { SetMirrors(nil) })
+
+	upstream := httptest.NewServer(http.NotFoundHandler())
+	defer upstream.Close()
+	mirror := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	defer mirror.Close()
+
+	SetMirrors([]MirrorRule{{Upstream: upstream.URL, Mirrors: []string{mirror.URL}}})
+
+	_, err := NewHttpClient().Get(upstream.URL + "/a.json")
+	var statusErr *StatusError
+	if !errors.As(err, &statusErr) || statusErr.StatusCode != http.StatusNotFound {
+		t.Fatalf("expected the upstream's 404, got %v", err)
+	}
+	// A 4xx is about the file, not the mirror, so it stays first in line.
+	if got := Candidates(upstream.URL + "/b.json"); got[0] != mirror.URL+"/b.json" {
+		t.Errorf("mirror answering 4xx should not be cooled down, got %v", got)
+	}
+
+	// Once the mirror is cooled down it is tried after the upstream and must not mask its 404.
+	ReportMirrorFailure(mirror.URL)
+	_, err = NewHttpClient().Get(upstream.URL + "/a.json")
+	if !errors.As(err, &statusErr) || statusErr.StatusCode != http.StatusNotFound {
+		t.Fatalf("expected the upstream's 404, got %v", err)
+	}
+}
+
+func TestIsMirrorFault(t *testing.T) {
+	tests := []struct {
+		err  error
+		want bool
+	}{
+		{context.Canceled, false},
+		{fmt.Errorf("read body: %w", context.DeadlineExceeded), false},
+		{&StatusError{StatusCode: http.StatusNotFound}, false},
+		{&StatusError{StatusCode: http.StatusBadGateway}, true},
+		{errors.New("connection reset by peer"), true},
+	}
+	for _, tt := range tests {
+		if got := IsMirrorFault(tt.err); got != tt.want {
+			t.Errorf("IsMirrorFault(%v) = %v, want %v", tt.err, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,241 @@
+package optifine
+
+import (
+	"NezordLauncher/pkg/constants"
+	"NezordLauncher/pkg/downloader"
+	"NezordLauncher/pkg/models"
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+const (
+	launchWrapperMain  = "net.minecraft.launchwrapper.Launch"
+	tweakClass         = "optifine.OptiFineTweaker"
+	vanillaWrapperName = "net.minecraft:launchwrapper:1.12"
+)
+
+// configClasses hold the OptiFine version constants, in newer and older package layouts.
+var configClasses = []string{"net/optifine/Config.class", "Config.class"}
+
+var versionPattern = regexp.MustCompile(`OptiFine_([0-9][0-9.]*)_(HD_U|HD|L)_([A-Za-z0-9_]+)`)
+
+// Info identifies an OptiFine build, e.g. 1.20.1 HD_U I6.
+type Info struct {
+	GameVersion string
+	Edition     string
+	Release     string
+}
+
+// Version is the loader version stored on instances, e.g. HD_U_I6.
+func (i Info) Version() string {
+	return i.Edition + "_" + i.Release
+}
+
+// VersionID is the ID of the generated standalone version JSON.
+func VersionID(gameVersion, version string) string {
+	return fmt.Sprintf("%s-OptiFine_%s", gameVersion, version)
+}
+
+// runPatcher applies the installer's class patches to the vanilla jar using OptiFine's own patcher.
+var runPatcher = func(javaPath, installerPath, baseJar, outJar string) error {
+	cmd := exec.Command(javaPath, "-cp", installerPath, "optifine.Patcher", baseJar, installerPath, outJar)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("optifine patcher failed: %w: %s", err, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+// Detect reads the target game version and build from an OptiFine installer jar.
+func Detect(installerPath string) (*Info, error) {
+	zr, err := zip.OpenReader(installerPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open optifine installer: %w", err)
+	}
+	defer zr.Close()
+
+	for _, name := range configClasses {
+		data, err := readEntry(&zr.Reader, name)
+		if err != nil {
+			continue
+		}
+		if m := versionPattern.FindSubmatch(data); m != nil {
+			return &Info{GameVersion: string(m[1]), Edition: string(m[2]), Release: string(m[3])}, nil
+		}
+	}
+	return nil, fmt.Errorf("not an optifine installer: %s", filepath.Base(installerPath))
+}
+
+type InstallOptions struct {
+	// JavaPath runs the patcher; builds without one ship their classes unpatched.
+	JavaPath string
+}
+
+// Install sets OptiFine up as a standalone version inheriting from vanilla and returns its ID.
+// The vanilla version must already be downloaded.
+func Install(installerPath string, opts InstallOptions) (string, error) {
+	info, err := Detect(installerPath)
+	if err != nil {
+		return "", err
+	}
+
+	zr, err := zip.OpenReader(installerPath)
+	if err != nil {
+		return "", fmt.Errorf("failed to open optifine installer: %w", err)
+	}
+	defer zr.Close()
+
+	gameVersion := info.GameVersion
+	versionID := VersionID(gameVersion, info.Version())
+	libDir := constants.GetLibrariesDir()
+
+	parentPath := filepath.Join(constants.GetVersionsDir(), gameVersion, gameVersion+".json")
+	parentData, err := os.ReadFile(parentPath)
+	if err != nil {
+		return "", fmt.Errorf("vanilla %s is not installed: %w", gameVersion, err)
+	}
+	var parent models.VersionDetail
+	if err := json.Unmarshal(parentData, &parent); err != nil {
+		return "", fmt.Errorf("failed to parse vanilla version: %w", err)
+	}
+
+	libName := fmt.Sprintf("optifine:OptiFine:%s_%s", gameVersion, info.Version())
+	libPath := filepath.Join(libDir, filepath.FromSlash(models.MavenPath(libName)))
+	if zipHasEntry(&zr.Reader, "optifine/Patcher.class") {
+		if opts.JavaPath == "" {
+			return "", fmt.Errorf("java is required to patch optifine")
+		}
+		if err := os.MkdirAll(filepath.Dir(libPath), 0755); err != nil {
+			return "", err
+		}
+		baseJar := filepath.Join(constants.GetVersionsDir(), gameVersion, gameVersion+".jar")
+		if err := runPatcher(opts.JavaPath, installerPath, baseJar, libPath); err != nil {
+			os.Remove(libPath)
+			return "", err
+		}
+	} else if err := copyFile(installerPath, libPath); err != nil {
+		return "", fmt.Errorf("failed to install optifine library: %w", err)
+	}
+
+	libraries := []map[string]interface{}{libraryEntry(libName)}
+	wrapper, err := extractLaunchWrapper(&zr.Reader, libDir)
+	if err != nil {
+		return "", err
+	}
+	if wrapper != "" {
+		libraries = append(libraries, libraryEntry(wrapper))
+	} else {
+		libraries = append(libraries, map[string]interface{}{"name": vanillaWrapperName})
+	}
+
+	version := map[string]interface{}{
+		"id":           versionID,
+		"inheritsFrom": gameVersion,
+		"type":         parent.Type,
+		"mainClass":    launchWrapperMain,
+		"libraries":    libraries,
+	}
+	// minecraftArguments replace the parent's string; modern argument lists are merged instead.
+	if parent.MinecraftArguments != "" {
+		version["minecraftArguments"] = parent.MinecraftArguments + " --tweakClass " + tweakClass
+	} else {
+		version["arguments"] = map[string]interface{}{"game": []string{"--tweakClass", tweakClass}}
+	}
+
+	out, err := json.MarshalIndent(version, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal version json: %w", err)
+	}
+	jsonPath := filepath.Join(constants.GetVersionsDir(), versionID, versionID+".json")
+	if err := downloader.AtomicWriteFile(jsonPath, out); err != nil {
+		return "", fmt.Errorf("failed to write version json: %w", err)
+	}
+	return versionID, nil
+}
+
+// InstallAsMod copies the installer into a Forge instance's mods folder, where it loads as a mod.
+func InstallAsMod(installerPath, modsDir string) (string, error) {
+	info, err := Detect(installerPath)
+	if err != nil {
+		return "", err
+	}
+
+	name := fmt.Sprintf("OptiFine_%s_%s.jar", info.GameVersion, info.Version())
+	if err := copyFile(installerPath, filepath.Join(modsDir, name)); err != nil {
+		return "", fmt.Errorf("failed to copy optifine into mods: %w", err)
+	}
+	return name, nil
+}
+
+// extractLaunchWrapper installs the launchwrapper fork bundled with newer builds and returns its
+// library name, or "" when the build relies on the vanilla launchwrapper.
+func extractLaunchWrapper(zr *zip.Reader, libDir string) (string, error) {
+	data, err := readEntry(zr, "launchwrapper-of.txt")
+	if err != nil {
+		return "", nil
+	}
+	version := strings.TrimSpace(string(data))
+
+	jar, err := readEntry(zr, fmt.Sprintf("launchwrapper-of-%s.jar", version))
+	if err != nil {
+		return "", fmt.Errorf("optifine installer is missing launchwrapper-of %s: %w", version, err)
+	}
+
+	name := "optifine:launchwrapper-of:" + version
+	if err := downloader.AtomicWriteFile(filepath.Join(libDir, filepath.FromSlash(models.MavenPath(name))), jar); err != nil {
+		return "", fmt.Errorf("failed to install launchwrapper: %w", err)
+	}
+	return name, nil
+}
+
+// libraryEntry describes a locally installed library; with a path and no URL the downloader leaves it alone.
+func libraryEntry(name string) map[string]interface{} {
+	return map[string]interface{}{
+		"name": name,
+		"downloads": map[string]interface{}{
+			"artifact": map[string]interface{}{"path": models.MavenPath(name)},
+		},
+	}
+}
+
+func readEntry(zr *zip.Reader, name string) ([]byte, error) {
+	for _, f := range zr.File {
+		if f.Name != name {
+			continue
+		}
+		rc, err := f.Open()
+		if err != nil {
+			return nil, err
+		}
+		defer rc.Close()
+		return io.ReadAll(rc)
+	}
+	return nil, fmt.Errorf("%s not found", name)
+}
+
+func zipHasEntry(zr *zip.Reader, name string) bool {
+	for _, f := range zr.File {
+		if f.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+func copyFile(src, dst string) error {
+	data, err := os.ReadFile(src)
+	if err != nil {
+		return err
+	}
+	if existing, err := os.ReadFile(dst); err == nil && bytes.Equal(existing, data) {
+		return nil
+	}
+	return downloader.AtomicWriteFile(dst, data)
+}
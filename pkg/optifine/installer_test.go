@@ -0,0 +1,167 @@
+package optifine
+
+import (
+	"NezordLauncher/pkg/constants"
+	"NezordLauncher/pkg/launch"
+	"NezordLauncher/pkg/models"
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func buildInstaller(t *testing.T, files map[string][]byte) string {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for name, data := range files {
+		w, err := zw.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		w.Write(data)
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "OptiFine.jar")
+	os.WriteFile(path, buf.Bytes(), 0644)
+	return path
+}
+
+// configClass mimics the constant pool of OptiFine's Config class.
+func configClass(version string) []byte {
+	return []byte("\xca\xfe\xba\xbe\x01\x00\x17" + version + "\x01\x00\x06HD_U_I")
+}
+
+func writeParent(t *testing.T, gameVersion string, detail map[string]interface{}) {
+	t.Helper()
+	detail["id"] = gameVersion
+	data, _ := json.Marshal(detail)
+	dir := filepath.Join(constants.GetVersionsDir(), gameVersion)
+	os.MkdirAll(dir, 0755)
+	os.WriteFile(filepath.Join(dir, gameVersion+".json"), data, 0644)
+}
+
+func readVersion(t *testing.T, id string) *models.VersionDetail {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join(constants.GetVersionsDir(), id, id+".json"))
+	if err != nil {
+		t.Fatalf("version json not written: %v", err)
+	}
+	var v models.VersionDetail
+	if err := json.Unmarshal(data, &v); err != nil {
+		t.Fatal(err)
+	}
+	return &v
+}
+
+func TestDetect(t *testing.T) {
+	path := buildInstaller(t, map[string][]byte{"net/optifine/Config.class": configClass("OptiFine_1.20.1_HD_U_I6")})
+	info, err := Detect(path)
+	if err != nil {
+		t.Fatalf("Detect failed: %v", err)
+	}
+	if info.GameVersion != "1.20.1" || info.Version() != "HD_U_I6" {
+		t.Errorf("unexpected info %+v", info)
+	}
+
+	if _, err := Detect(buildInstaller(t, map[string][]byte{"Other.class": []byte("x")})); err == nil {
+		t.Error("expected error for a jar without OptiFine config")
+	}
+}
+
+func TestInstallStandalone(t *testing.T) {
+	t.Setenv("NEZORD_DATA_DIR", t.TempDir())
+	writeParent(t, "1.20.1", map[string]interface{}{
+		"mainClass": "net.minecraft.client.main.Main",
+		"type":      "release",
+		"arguments": map[string]interface{}{"game": []string{"--username", "${auth_player_name}"}},
+	})
+
+	var patched []string
+	original := runPatcher
+	runPatcher = func(javaPath, installerPath, baseJar, outJar string) error {
+		patched = append(patched, baseJar)
+		return os.WriteFile(outJar, []byte("patched"), 0644)
+	}
+	t.Cleanup(func() { runPatcher = original })
+
+	path := buildInstaller(t, map[string][]byte{
+		"net/optifine/Config.class": configClass("OptiFine_1.20.1_HD_U_I6"),
+		"optifine/Patcher.class":    []byte("patcher"),
+		"launchwrapper-of.txt":      []byte("2.3\n"),
+		"launchwrapper-of-2.3.jar":  []byte("wrapper"),
+	})
+
+	id, err := Install(path, InstallOptions{JavaPath: "java"})
+	if err != nil {
+		t.Fatalf("Install failed: %v", err)
+	}
+	if id != "1.20.1-OptiFine_HD_U_I6" {
+		t.Errorf("unexpected version id %s", id)
+	}
+	if len(patched) != 1 || !strings.HasSuffix(patched[0], filepath.Join("1.20.1", "1.20.1.jar")) {
+		t.Errorf("patcher should run against the vanilla jar, got %v", patched)
+	}
+
+	child := readVersion(t, id)
+	merged := launch.MergeVersions(child, readVersion(t, "1.20.1"))
+	if merged.MainClass.Client != launchWrapperMain || merged.Jar != "1.20.1" {
+		t.Errorf("unexpected merged version: main %s, jar %s", merged.MainClass.Client, merged.Jar)
+	}
+	if len(merged.Arguments.Game) != 4 || merged.Arguments.Game[1].Values[0] != tweakClass {
+		t.Errorf("tweak class not merged into game arguments: %+v", merged.Arguments.Game)
+	}
+
+	for _, name := range []string{"optifine:OptiFine:1.20.1_HD_U_I6", "optifine:launchwrapper-of:2.3"} {
+		lib := filepath.Join(constants.GetLibrariesDir(), models.MavenPath(name))
+		if _, err := os.Stat(lib); err != nil {
+			t.Errorf("%s not installed: %v", name, err)
+		}
+	}
+}
+
+func TestInstallLegacyStandalone(t *testing.T) {
+	t.Setenv("NEZORD_DATA_DIR", t.TempDir())
+	writeParent(t, "1.7.10", map[string]interface{}{
+		"mainClass":          "net.minecraft.client.main.Main",
+		"minecraftArguments": "--username ${auth_player_name}",
+	})
+
+	path := buildInstaller(t, map[string][]byte{"Config.class": configClass("OptiFine_1.7.10_HD_U_E7")})
+	id, err := Install(path, InstallOptions{})
+	if err != nil {
+		t.Fatalf("Install failed: %v", err)
+	}
+
+	child := readVersion(t, id)
+	if child.MinecraftArguments != "--username ${auth_player_name} --tweakClass "+tweakClass {
+		t.Errorf("unexpected minecraftArguments %q", child.MinecraftArguments)
+	}
+	if len(child.Libraries) != 2 || child.Libraries[1].Name != vanillaWrapperName {
+		t.Errorf("expected vanilla launchwrapper, got %+v", child.Libraries)
+	}
+
+	// Builds without a patcher are used as the library directly.
+	lib := filepath.Join(constants.GetLibrariesDir(), models.MavenPath("optifine:OptiFine:1.7.10_HD_U_E7"))
+	if _, err := os.Stat(lib); err != nil {
+		t.Errorf("optifine library not installed: %v", err)
+	}
+}
+
+func TestInstallAsMod(t *testing.T) {
+	modsDir := filepath.Join(t.TempDir(), "mods")
+	path := buildInstaller(t, map[string][]byte{"net/optifine/Config.class": configClass("OptiFine_1.20.1_HD_U_I6")})
+
+	name, err := InstallAsMod(path, modsDir)
+	if err != nil {
+		t.Fatalf("InstallAsMod failed: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(modsDir, name)); err != nil || name != "OptiFine_1.20.1_HD_U_I6.jar" {
+		t.Errorf("mod not copied as %s: %v", name, err)
+	}
+}
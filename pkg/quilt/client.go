@@ -1,31 +1,21 @@
 package quilt
 
 import (
-	"NezordLauncher/pkg/network"
-	"encoding/json"
-	"fmt"
+	"NezordLauncher/pkg/fabric"
 )
 
 const MetaURL = "https://meta.quiltmc.org"
 
-func GetLoaderVersions(gameVersion string) ([]LoaderVersion, error) {
-	client := network.NewHttpClient()
-	// Quilt uses v3 API structure
-	url := fmt.Sprintf("%s/v3/versions/loader/%s", MetaURL, gameVersion)
-
-	data, err := client.Get(url)
-	if err != nil {
-		return nil, fmt.Errorf("failed to fetch quilt versions: %w", err)
-	}
-
-	var versions []LoaderVersion
-	if err := json.Unmarshal(data, &versions); err != nil {
-		return nil, fmt.Errorf("failed to parse quilt versions: %w", err)
-	}
+// Meta is the Quilt metadata service; Quilt uses the v3 API structure.
+var Meta = fabric.Meta{
+	Name:     "quilt",
+	URL:      MetaURL,
+	API:      "v3",
+	MavenURL: "https://maven.quiltmc.org/repository/release/",
 
-	if len(versions) == 0 {
-		return nil, fmt.Errorf("no quilt loader versions found for %s", gameVersion)
-	}
+	TaggedPrereleases: true,
+}
 
-	return versions, nil
+func GetLoaderVersions(gameVersion string) ([]LoaderVersion, error) {
+	return Meta.LoaderVersions(gameVersion)
 }
@@ -0,0 +1,622 @@
+package services
+
+import (
+	"NezordLauncher/pkg/auth"
+	"NezordLauncher/pkg/skins"
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha1"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	LocalYggdrasilName = "Nezord Local"
+	localKeyBits       = 2048
+	joinTTL            = 30 * time.Second
+	maxGuestProfiles   = 32
+	guestRequestLimit  = 1 << 20
+)
+
+var (
+	textureIDPattern = regexp.MustCompile(`^[0-9a-f]{40}$`)
+	guestIDPattern   = regexp.MustCompile(`^[0-9a-f]{32}$`)
+	guestNamePattern = regexp.MustCompile(`^[A-Za-z0-9_]{1,16}$`)
+)
+
+// LocalProfile is an offline account published by the local Yggdrasil server.
+type LocalProfile struct {
+	UUID   string
+	Name   string
+	SkinID string
+	Model  string
+}
+
+// GuestProfile is an offline profile a LAN guest publishes to the host's server, with its skin.
+type GuestProfile struct {
+	ID    string `json:"id"`
+	Name  string `json:"name"`
+	Model string `json:"model,omitempty"`
+	Skin  []byte `json:"skin,omitempty"`
+}
+
+type joinRecord struct {
+	profile  LocalProfile
+	joinedAt time.Time
+}
+
+type guestRecord struct {
+	profile LocalProfile
+	skin    []byte
+	seen    time.Time
+}
+
+// LocalYggdrasil is an in-process authlib-injector server for offline accounts. It serves skins
+// from the local library with locally signed properties. By default it only listens on loopback;
+// a LAN host listens on all interfaces and accepts guests' profiles, so every game pointed at
+// the host's server sees everyone's skins.
+type LocalYggdrasil struct {
+	mu           sync.Mutex
+	key          *rsa.PrivateKey
+	server       *http.Server
+	addr         string
+	baseURL      string
+	acceptGuests bool
+	joins        map[string]joinRecord
+	guests       map[string]guestRecord
+	Profiles     func() []LocalProfile
+	Texture      func(id string) ([]byte, error)
+}
+
+func NewLocalYggdrasil(key *rsa.PrivateKey, profiles func() []LocalProfile, texture func(id string) ([]byte, error)) *LocalYggdrasil {
+	return &LocalYggdrasil{
+		key:      key,
+		joins:    make(map[string]joinRecord),
+		guests:   make(map[string]guestRecord),
+		Profiles: profiles,
+		Texture:  texture,
+	}
+}
+
+// LoadOrCreateSigningKey keeps the key across restarts so cached signatures stay valid.
+func LoadOrCreateSigningKey(path string) (*rsa.PrivateKey, error) {
+	if data, err := os.ReadFile(path); err == nil {
+		block, _ := pem.Decode(data)
+		if block == nil {
+			return nil, fmt.Errorf("invalid signing key file: %s", path)
+		}
+		key, err := x509.ParsePKCS1PrivateKey(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse signing key: %w", err)
+		}
+		return key, nil
+	} else if !os.IsNotExist(err) {
+		return nil, err
+	}
+
+	key, err := rsa.GenerateKey(rand.Reader, localKeyBits)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate signing key: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, err
+	}
+	data := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		return nil, fmt.Errorf("failed to write signing key: %w", err)
+	}
+	return key, nil
+}
+
+// Start listens on addr, or on a random loopback port when addr is empty, and returns the
+// loopback URL for this machine's games. A server running on another address is restarted.
+func (s *LocalYggdrasil) Start(addr string) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.server != nil && s.addr == addr {
+		return s.baseURL, nil
+	}
+	if s.server != nil {
+		s.server.Close()
+		s.server = nil
+		s.baseURL = ""
+	}
+
+	listenAddr := addr
+	if listenAddr == "" {
+		listenAddr = "127.0.0.1:0"
+	}
+	listener, err := net.Listen("tcp", listenAddr)
+	if err != nil {
+		return "", fmt.Errorf("failed to start local yggdrasil server: %w", err)
+	}
+	_, port, err := net.SplitHostPort(listener.Addr().String())
+	if err != nil {
+		listener.Close()
+		return "", err
+	}
+
+	s.addr = addr
+	s.baseURL = "http://127.0.0.1:" + port
+	s.server = &http.Server{Handler: s.Handler(), ReadHeaderTimeout: 10 * time.Second}
+	go s.server.Serve(listener)
+
+	return s.baseURL, nil
+}
+
+// SetAcceptGuests allows LAN guests to publish their profiles to this server.
+func (s *LocalYggdrasil) SetAcceptGuests(accept bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.acceptGuests = accept
+	if !accept {
+		s.guests = make(map[string]guestRecord)
+	}
+}
+
+// LANURLs lists the addresses guests can reach the server at, or nil when it only listens on
+// loopback.
+func (s *LocalYggdrasil) LANURLs() []string {
+	s.mu.Lock()
+	addr, baseURL := s.addr, s.baseURL
+	s.mu.Unlock()
+	if addr == "" || baseURL == "" {
+		return nil
+	}
+
+	_, port, err := net.SplitHostPort(strings.TrimPrefix(baseURL, "http://"))
+	if err != nil {
+		return nil
+	}
+	addrs, err := net.InterfaceAddrs()
+	if err != nil {
+		return nil
+	}
+	var urls []string
+	for _, a := range addrs {
+		ipnet, ok := a.(*net.IPNet)
+		if !ok || ipnet.IP.To4() == nil || ipnet.IP.IsLoopback() || ipnet.IP.IsLinkLocalUnicast() {
+			continue
+		}
+		urls = append(urls, "http://"+net.JoinHostPort(ipnet.IP.String(), port))
+	}
+	return urls
+}
+
+func (s *LocalYggdrasil) URL() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.baseURL
+}
+
+func (s *LocalYggdrasil) Stop(ctx context.Context) error {
+	s.mu.Lock()
+	server := s.server
+	s.server = nil
+	s.baseURL = ""
+	s.mu.Unlock()
+
+	if server == nil {
+		return nil
+	}
+	return server.Shutdown(ctx)
+}
+
+// Metadata is the API root document; it is also passed to the game as prefetched metadata.
+// skinDomains lists the hosts texture URLs may point at besides loopback.
+func (s *LocalYggdrasil) Metadata(skinDomains ...string) ([]byte, error) {
+	pub, err := x509.MarshalPKIXPublicKey(&s.key.PublicKey)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(map[string]interface{}{
+		"meta": map[string]interface{}{
+			"serverName":              LocalYggdrasilName,
+			"implementationName":      "NezordLauncher",
+			"feature.non_email_login": true,
+		},
+		"skinDomains":        append([]string{"127.0.0.1", "localhost"}, skinDomains...),
+		"signaturePublickey": string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: pub})),
+	})
+}
+
+// PrefetchedMetadata is the value for -Dauthlibinjector.yggdrasil.prefetched.
+func (s *LocalYggdrasil) PrefetchedMetadata() string {
+	data, err := s.Metadata()
+	if err != nil {
+		return ""
+	}
+	return base64.StdEncoding.EncodeToString(data)
+}
+
+func (s *LocalYggdrasil) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", s.handleMetadata)
+	mux.HandleFunc("/authserver/validate", s.handleNoContent)
+	mux.HandleFunc("/authserver/invalidate", s.handleNoContent)
+	mux.HandleFunc("/sessionserver/session/minecraft/join", s.handleJoin)
+	mux.HandleFunc("/sessionserver/session/minecraft/hasJoined", s.handleHasJoined)
+	mux.HandleFunc("/sessionserver/session/minecraft/profile/", s.handleProfile)
+	mux.HandleFunc("/api/profiles/minecraft", s.handleProfilesByName)
+	mux.HandleFunc("/textures/", s.handleTexture)
+	mux.HandleFunc("/nezord/guests", s.handleGuest)
+	return mux
+}
+
+func (s *LocalYggdrasil) handleMetadata(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != "/" {
+		writeYggdrasilError(w, http.StatusNotFound, "Not Found", "no such endpoint")
+		return
+	}
+	// Texture URLs are built from the address the client used, so that host must be allowed.
+	var domains []string
+	if host := requestHostname(r); host != "127.0.0.1" && host != "localhost" {
+		domains = append(domains, host)
+	}
+	data, err := s.Metadata(domains...)
+	if err != nil {
+		writeYggdrasilError(w, http.StatusInternalServerError, "InternalError", err.Error())
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(data)
+}
+
+func (s *LocalYggdrasil) handleNoContent(w http.ResponseWriter, r *http.Request) {
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *LocalYggdrasil) handleJoin(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	var req struct {
+		AccessToken     string `json:"accessToken"`
+		SelectedProfile string `json:"selectedProfile"`
+		ServerID        string `json:"serverId"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.ServerID == "" {
+		writeYggdrasilError(w, http.StatusBadRequest, "IllegalArgumentException", "invalid join request")
+		return
+	}
+
+	profile, ok := s.findProfile(func(p LocalProfile) bool { return compactUUID(p.UUID) == compactUUID(req.SelectedProfile) })
+	if !ok {
+		writeYggdrasilError(w, http.StatusForbidden, "ForbiddenOperationException", "Invalid token.")
+		return
+	}
+
+	s.mu.Lock()
+	for id, rec := range s.joins {
+		if time.Since(rec.joinedAt) > joinTTL {
+			delete(s.joins, id)
+		}
+	}
+	s.joins[req.ServerID] = joinRecord{profile: profile, joinedAt: time.Now()}
+	s.mu.Unlock()
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handleHasJoined answers the integrated/LAN server. Offline mode has no real identity, so
+// players that neither joined through this server nor published a guest profile get their
+// offline UUID without textures instead of a rejection.
+func (s *LocalYggdrasil) handleHasJoined(w http.ResponseWriter, r *http.Request) {
+	username := r.URL.Query().Get("username")
+	serverID := r.URL.Query().Get("serverId")
+	if username == "" {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	s.mu.Lock()
+	rec, ok := s.joins[serverID]
+	s.mu.Unlock()
+
+	var profile LocalProfile
+	if ok && strings.EqualFold(rec.profile.Name, username) {
+		profile = rec.profile
+	} else if known, found := s.findProfile(func(p LocalProfile) bool { return strings.EqualFold(p.Name, username) }); found {
+		profile = known
+	} else {
+		profile = LocalProfile{UUID: auth.GenerateOfflineUUID(username), Name: username}
+	}
+
+	s.writeProfile(w, r, profile, true)
+}
+
+func (s *LocalYggdrasil) handleProfile(w http.ResponseWriter, r *http.Request) {
+	id := strings.TrimPrefix(r.URL.Path, "/sessionserver/session/minecraft/profile/")
+	profile, ok := s.findProfile(func(p LocalProfile) bool { return compactUUID(p.UUID) == compactUUID(id) })
+	if !ok {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	s.writeProfile(w, r, profile, r.URL.Query().Get("unsigned") == "false")
+}
+
+func (s *LocalYggdrasil) handleProfilesByName(w http.ResponseWriter, r *http.Request) {
+	var names []string
+	if err := json.NewDecoder(r.Body).Decode(&names); err != nil {
+		writeYggdrasilError(w, http.StatusBadRequest, "IllegalArgumentException", "invalid request body")
+		return
+	}
+
+	result := []map[string]string{}
+	for _, name := range names {
+		if p, ok := s.findProfile(func(p LocalProfile) bool { return strings.EqualFold(p.Name, name) }); ok {
+			result = append(result, map[string]string{"id": compactUUID(p.UUID), "name": p.Name})
+		}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(result)
+}
+
+func (s *LocalYggdrasil) handleTexture(w http.ResponseWriter, r *http.Request) {
+	id := strings.TrimPrefix(r.URL.Path, "/textures/")
+	if !textureIDPattern.MatchString(id) {
+		http.NotFound(w, r)
+		return
+	}
+	data := s.guestTexture(id)
+	if data == nil && s.Texture != nil {
+		data, _ = s.Texture(id)
+	}
+	if data == nil {
+		http.NotFound(w, r)
+		return
+	}
+	w.Header().Set("Content-Type", "image/png")
+	w.Write(data)
+}
+
+// handleGuest stores a LAN guest's profile and skin, replacing any earlier copy. Guests cannot
+// take over the host's own profiles.
+func (s *LocalYggdrasil) handleGuest(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	s.mu.Lock()
+	accept := s.acceptGuests
+	s.mu.Unlock()
+	if !accept {
+		writeYggdrasilError(w, http.StatusForbidden, "ForbiddenOperationException", "this server does not accept LAN guests")
+		return
+	}
+
+	var guest GuestProfile
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, guestRequestLimit)).Decode(&guest); err != nil {
+		writeYggdrasilError(w, http.StatusBadRequest, "IllegalArgumentException", "invalid guest profile")
+		return
+	}
+	id := compactUUID(guest.ID)
+	if !guestIDPattern.MatchString(id) || !guestNamePattern.MatchString(guest.Name) {
+		writeYggdrasilError(w, http.StatusBadRequest, "IllegalArgumentException", "invalid guest id or name")
+		return
+	}
+	if s.findLocalProfile(func(p LocalProfile) bool {
+		return compactUUID(p.UUID) == id || strings.EqualFold(p.Name, guest.Name)
+	}) {
+		writeYggdrasilError(w, http.StatusConflict, "ForbiddenOperationException", "profile belongs to the host")
+		return
+	}
+
+	profile := LocalProfile{UUID: id, Name: guest.Name}
+	if len(guest.Skin) > 0 {
+		if _, err := skins.ValidateSkinPNG(guest.Skin, guest.Model, false); err != nil {
+			writeYggdrasilError(w, http.StatusBadRequest, "IllegalArgumentException", err.Error())
+			return
+		}
+		sum := sha1.Sum(guest.Skin)
+		profile.SkinID = hex.EncodeToString(sum[:])
+		profile.Model = guest.Model
+	}
+
+	s.mu.Lock()
+	if _, exists := s.guests[id]; !exists && len(s.guests) >= maxGuestProfiles {
+		oldest := ""
+		for gid, g := range s.guests {
+			if oldest == "" || g.seen.Before(s.guests[oldest].seen) {
+				oldest = gid
+			}
+		}
+		delete(s.guests, oldest)
+	}
+	s.guests[id] = guestRecord{profile: profile, skin: guest.Skin, seen: time.Now()}
+	s.mu.Unlock()
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *LocalYggdrasil) guestTexture(id string) []byte {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, g := range s.guests {
+		if g.profile.SkinID == id {
+			return g.skin
+		}
+	}
+	return nil
+}
+
+func (s *LocalYggdrasil) writeProfile(w http.ResponseWriter, r *http.Request, profile LocalProfile, signed bool) {
+	body := map[string]interface{}{
+		"id":   compactUUID(profile.UUID),
+		"name": profile.Name,
+	}
+
+	value, err := s.texturesProperty(profile, baseURLFor(r))
+	if err != nil {
+		writeYggdrasilError(w, http.StatusInternalServerError, "InternalError", err.Error())
+		return
+	}
+	prop := map[string]string{"name": "textures", "value": value}
+	if signed {
+		signature, err := s.sign(value)
+		if err != nil {
+			writeYggdrasilError(w, http.StatusInternalServerError, "InternalError", err.Error())
+			return
+		}
+		prop["signature"] = signature
+	}
+	body["properties"] = []map[string]string{prop}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(body)
+}
+
+func (s *LocalYggdrasil) texturesProperty(profile LocalProfile, baseURL string) (string, error) {
+	textures := map[string]interface{}{}
+	if profile.SkinID != "" {
+		skin := map[string]interface{}{"url": baseURL + "/textures/" + profile.SkinID}
+		if profile.Model == "slim" {
+			skin["metadata"] = map[string]string{"model": "slim"}
+		}
+		textures["SKIN"] = skin
+	}
+
+	data, err := json.Marshal(map[string]interface{}{
+		"timestamp":   time.Now().UnixMilli(),
+		"profileId":   compactUUID(profile.UUID),
+		"profileName": profile.Name,
+		"textures":    textures,
+	})
+	if err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(data), nil
+}
+
+// sign produces the SHA1withRSA signature authlib expects on profile properties.
+func (s *LocalYggdrasil) sign(value string) (string, error) {
+	sum := sha1.Sum([]byte(value))
+	sig, err := rsa.SignPKCS1v15(rand.Reader, s.key, crypto.SHA1, sum[:])
+	if err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(sig), nil
+}
+
+// findProfile looks through the host's own profiles first, then the published guests.
+func (s *LocalYggdrasil) findProfile(match func(LocalProfile) bool) (LocalProfile, bool) {
+	if s.Profiles != nil {
+		for _, p := range s.Profiles() {
+			if match(p) {
+				return p, true
+			}
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, g := range s.guests {
+		if match(g.profile) {
+			return g.profile, true
+		}
+	}
+	return LocalProfile{}, false
+}
+
+func (s *LocalYggdrasil) findLocalProfile(match func(LocalProfile) bool) bool {
+	if s.Profiles == nil {
+		return false
+	}
+	for _, p := range s.Profiles() {
+		if match(p) {
+			return true
+		}
+	}
+	return false
+}
+
+// PublishGuestProfile publishes profile to a LAN host's server and returns the host's metadata
+// for -Dauthlibinjector.yggdrasil.prefetched.
+func PublishGuestProfile(ctx context.Context, hostURL string, profile GuestProfile) (string, error) {
+	hostURL = strings.TrimRight(hostURL, "/")
+	client := &http.Client{Timeout: 5 * time.Second}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, hostURL+"/", nil)
+	if err != nil {
+		return "", err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to reach LAN skin server: %w", err)
+	}
+	metadata, err := io.ReadAll(io.LimitReader(resp.Body, guestRequestLimit))
+	resp.Body.Close()
+	if err != nil {
+		return "", fmt.Errorf("failed to read LAN skin server metadata: %w", err)
+	}
+	var meta struct {
+		SignaturePublickey string `json:"signaturePublickey"`
+	}
+	if resp.StatusCode != http.StatusOK || json.Unmarshal(metadata, &meta) != nil || meta.SignaturePublickey == "" {
+		return "", fmt.Errorf("%s is not a Yggdrasil server", hostURL)
+	}
+
+	body, err := json.Marshal(profile)
+	if err != nil {
+		return "", err
+	}
+	req, err = http.NewRequestWithContext(ctx, http.MethodPost, hostURL+"/nezord/guests", bytes.NewReader(body))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err = client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to publish profile to LAN skin server: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusNoContent {
+		var yggErr struct {
+			ErrorMessage string `json:"errorMessage"`
+		}
+		json.NewDecoder(io.LimitReader(resp.Body, 4096)).Decode(&yggErr)
+		return "", fmt.Errorf("LAN skin server rejected profile (status %d): %s", resp.StatusCode, yggErr.ErrorMessage)
+	}
+
+	return base64.StdEncoding.EncodeToString(metadata), nil
+}
+
+func baseURLFor(r *http.Request) string {
+	return "http://" + r.Host
+}
+
+func requestHostname(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.Host)
+	if err != nil {
+		return r.Host
+	}
+	return host
+}
+
+func compactUUID(id string) string {
+	return strings.ToLower(strings.ReplaceAll(id, "-", ""))
+}
+
+func writeYggdrasilError(w http.ResponseWriter, status int, errType, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]string{"error": errType, "errorMessage": message})
+}
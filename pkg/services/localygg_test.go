@@ -0,0 +1,255 @@
+package services
+
+import (
+	"NezordLauncher/pkg/skins"
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/rsa"
+	"crypto/sha1"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"image"
+	"image/color"
+	"image/draw"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+type testProfileResponse struct {
+	ID         string `json:"id"`
+	Name       string `json:"name"`
+	Properties []struct {
+		Name      string `json:"name"`
+		Value     string `json:"value"`
+		Signature string `json:"signature"`
+	} `json:"properties"`
+}
+
+func TestLocalYggdrasil_SignedProfileAndJoin(t *testing.T) {
+	key, err := LoadOrCreateSigningKey(filepath.Join(t.TempDir(), "key.pem"))
+	if err != nil {
+		t.Fatalf("LoadOrCreateSigningKey failed: %v", err)
+	}
+
+	skinID := strings.Repeat("ab", 20)
+	profiles := func() []LocalProfile {
+		return []LocalProfile{{UUID: "01234567-89ab-cdef-0123-456789abcdef", Name: "Steve", SkinID: skinID, Model: "slim"}}
+	}
+	texture := func(id string) ([]byte, error) { return []byte("png-bytes"), nil }
+
+	local := NewLocalYggdrasil(key, profiles, texture)
+	server := httptest.NewServer(local.Handler())
+	defer server.Close()
+
+	resp, err := http.Get(server.URL + "/")
+	if err != nil {
+		t.Fatalf("metadata request failed: %v", err)
+	}
+	var meta struct {
+		SkinDomains        []string `json:"skinDomains"`
+		SignaturePublickey string   `json:"signaturePublickey"`
+	}
+	json.NewDecoder(resp.Body).Decode(&meta)
+	resp.Body.Close()
+
+	block, _ := pem.Decode([]byte(meta.SignaturePublickey))
+	if block == nil {
+		t.Fatal("metadata has no public key")
+	}
+	pub, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		t.Fatalf("invalid public key: %v", err)
+	}
+
+	join := `{"accessToken":"null","selectedProfile":"0123456789abcdef0123456789abcdef","serverId":"abc"}`
+	resp, err = http.Post(server.URL+"/sessionserver/session/minecraft/join", "application/json", strings.NewReader(join))
+	if err != nil || resp.StatusCode != http.StatusNoContent {
+		t.Fatalf("join failed: %v %v", err, resp.StatusCode)
+	}
+
+	resp, err = http.Get(server.URL + "/sessionserver/session/minecraft/hasJoined?username=Steve&serverId=abc")
+	if err != nil {
+		t.Fatalf("hasJoined failed: %v", err)
+	}
+	var profile testProfileResponse
+	json.NewDecoder(resp.Body).Decode(&profile)
+	resp.Body.Close()
+
+	if profile.ID != "0123456789abcdef0123456789abcdef" || len(profile.Properties) != 1 {
+		t.Fatalf("Unexpected profile: %+v", profile)
+	}
+	prop := profile.Properties[0]
+	sig, _ := base64.StdEncoding.DecodeString(prop.Signature)
+	sum := sha1.Sum([]byte(prop.Value))
+	if err := rsa.VerifyPKCS1v15(pub.(*rsa.PublicKey), crypto.SHA1, sum[:], sig); err != nil {
+		t.Errorf("textures signature does not verify: %v", err)
+	}
+
+	raw, _ := base64.StdEncoding.DecodeString(prop.Value)
+	if !bytes.Contains(raw, []byte(server.URL+"/textures/"+skinID)) || !bytes.Contains(raw, []byte(`"slim"`)) {
+		t.Errorf("Unexpected textures payload: %s", raw)
+	}
+
+	resp, err = http.Get(server.URL + "/textures/" + skinID)
+	if err != nil {
+		t.Fatalf("texture request failed: %v", err)
+	}
+	data, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if string(data) != "png-bytes" {
+		t.Errorf("Unexpected texture body %q", data)
+	}
+
+	resp, _ = http.Get(server.URL + "/textures/../key.pem")
+	if resp.StatusCode == http.StatusOK {
+		t.Error("Expected invalid texture id to be rejected")
+	}
+}
+
+func TestLocalYggdrasil_UnknownLANPlayerGetsOfflineProfile(t *testing.T) {
+	key, err := LoadOrCreateSigningKey(filepath.Join(t.TempDir(), "key.pem"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	local := NewLocalYggdrasil(key, func() []LocalProfile { return nil }, nil)
+	server := httptest.NewServer(local.Handler())
+	defer server.Close()
+
+	resp, err := http.Get(server.URL + "/sessionserver/session/minecraft/hasJoined?username=Alex&serverId=zzz")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var profile testProfileResponse
+	json.NewDecoder(resp.Body).Decode(&profile)
+	resp.Body.Close()
+
+	if profile.Name != "Alex" || len(profile.ID) != 32 {
+		t.Errorf("Unexpected profile: %+v", profile)
+	}
+}
+
+func TestLoadOrCreateSigningKey_Persists(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "key.pem")
+	first, err := LoadOrCreateSigningKey(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := LoadOrCreateSigningKey(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !first.Equal(second) {
+		t.Error("Expected the stored key to be reused")
+	}
+}
+
+func TestLocalYggdrasil_LANGuests(t *testing.T) {
+	key, err := LoadOrCreateSigningKey(filepath.Join(t.TempDir(), "key.pem"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	host := NewLocalYggdrasil(key, func() []LocalProfile {
+		return []LocalProfile{{UUID: "01234567-89ab-cdef-0123-456789abcdef", Name: "Steve"}}
+	}, nil)
+	server := httptest.NewServer(host.Handler())
+	defer server.Close()
+
+	img := image.NewNRGBA(image.Rect(0, 0, 64, 64))
+	draw.Draw(img, img.Bounds(), image.NewUniform(color.NRGBA{R: 200, A: 255}), image.Point{}, draw.Src)
+	skin, err := skins.EncodePNG(img)
+	if err != nil {
+		t.Fatal(err)
+	}
+	guest := GuestProfile{ID: "fedcba98-7654-3210-fedc-ba9876543210", Name: "Alex", Model: "slim", Skin: skin}
+
+	if _, err := PublishGuestProfile(context.Background(), server.URL, guest); err == nil {
+		t.Fatal("expected a host that does not accept guests to reject the profile")
+	}
+
+	host.SetAcceptGuests(true)
+	prefetched, err := PublishGuestProfile(context.Background(), server.URL, guest)
+	if err != nil {
+		t.Fatalf("PublishGuestProfile failed: %v", err)
+	}
+
+	// Texture URLs point at the address the guest used, which the metadata must allow.
+	hostname := strings.Split(strings.TrimPrefix(server.URL, "http://"), ":")[0]
+	meta, _ := base64.StdEncoding.DecodeString(prefetched)
+	if !bytes.Contains(meta, []byte(`"`+hostname+`"`)) || !bytes.Contains(meta, []byte("signaturePublickey")) {
+		t.Errorf("unexpected host metadata: %s", meta)
+	}
+
+	resp, err := http.Get(server.URL + "/sessionserver/session/minecraft/profile/fedcba9876543210fedcba9876543210")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var profile testProfileResponse
+	json.NewDecoder(resp.Body).Decode(&profile)
+	resp.Body.Close()
+	if profile.Name != "Alex" || len(profile.Properties) != 1 {
+		t.Fatalf("guest profile not served: %+v", profile)
+	}
+	raw, _ := base64.StdEncoding.DecodeString(profile.Properties[0].Value)
+	sum := sha1.Sum(skin)
+	textureURL := server.URL + "/textures/" + hex.EncodeToString(sum[:])
+	if !bytes.Contains(raw, []byte(textureURL)) || !bytes.Contains(raw, []byte(`"slim"`)) {
+		t.Errorf("unexpected guest textures: %s", raw)
+	}
+
+	resp, err = http.Get(textureURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if !bytes.Equal(data, skin) {
+		t.Error("guest skin not served")
+	}
+
+	impostor := GuestProfile{ID: "11111111111111111111111111111111", Name: "steve"}
+	if _, err := PublishGuestProfile(context.Background(), server.URL, impostor); err == nil {
+		t.Error("guests must not take over the host's profiles")
+	}
+	bad := GuestProfile{ID: "22222222222222222222222222222222", Name: "Bad", Skin: []byte("<html>")}
+	if _, err := PublishGuestProfile(context.Background(), server.URL, bad); err == nil {
+		t.Error("expected an invalid skin to be rejected")
+	}
+}
+
+func TestLocalYggdrasil_StartOnLAN(t *testing.T) {
+	key, err := LoadOrCreateSigningKey(filepath.Join(t.TempDir(), "key.pem"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	local := NewLocalYggdrasil(key, nil, nil)
+	defer local.Stop(context.Background())
+
+	loopback, err := local.Start("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if local.LANURLs() != nil {
+		t.Error("loopback server should have no LAN URLs")
+	}
+
+	url, err := local.Start("0.0.0.0:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if url == loopback || !strings.HasPrefix(url, "http://127.0.0.1:") {
+		t.Errorf("expected a restarted server with a loopback URL, got %s", url)
+	}
+	resp, err := http.Get(url + "/")
+	if err != nil {
+		t.Fatalf("LAN server not reachable on loopback: %v", err)
+	}
+	resp.Body.Close()
+}
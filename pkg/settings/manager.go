@@ -2,6 +2,7 @@ package settings
 
 import (
 	"NezordLauncher/pkg/constants"
+	"NezordLauncher/pkg/network"
 	"encoding/json"
 	"os"
 	"path/filepath"
@@ -22,8 +23,30 @@ type LauncherSettings struct {
 	AutoUpdateEnabled  bool   `json:"autoUpdateEnabled"`
 	GpuPreference      string `json:"gpuPreference"`
 	WrapperCommand     string `json:"wrapperCommand"`
+	CredentialBackend  string `json:"credentialBackend"`
+	// LocalSkinServer points offline launches at the built-in skin server through authlib-injector.
+	LocalSkinServer bool `json:"localSkinServer"`
+	// LocalSkinServerLAN opens the skin server on LocalSkinServerPort to LAN guests, who publish
+	// their profiles to it so every player sees everyone's skins.
+	LocalSkinServerLAN  bool `json:"localSkinServerLan"`
+	LocalSkinServerPort int  `json:"localSkinServerPort"`
+	// LocalSkinServerHost is a LAN host's skin server that offline launches join instead of the
+	// local one.
+	LocalSkinServerHost string `json:"localSkinServerHost"`
+
+	// MaxConcurrentDownloads limits how many download jobs run at once; 0 means the default.
+	MaxConcurrentDownloads int `json:"maxConcurrentDownloads"`
+
+	// MirrorPreset names a built-in mirror set from network.MirrorPresets; "" uses the official servers.
+	MirrorPreset string `json:"mirrorPreset"`
+	// Mirrors are custom rewrite rules, applied ahead of the preset.
+	Mirrors []network.MirrorRule `json:"mirrors"`
 }
 
+const DefaultConcurrentDownloads = 2
+
+const DefaultLocalSkinServerPort = 25590
+
 type Manager struct {
 	mu       sync.RWMutex
 	filePath string
@@ -44,6 +67,9 @@ func NewManager() *Manager {
 			DefaultResolutionH: 480,
 			AutoUpdateEnabled:  true,
 			GpuPreference:      "auto",
+			CredentialBackend:  "auto",
+
+			MaxConcurrentDownloads: DefaultConcurrentDownloads,
 		},
 	}
 }
@@ -0,0 +1,256 @@
+package skins
+
+import (
+	"NezordLauncher/pkg/auth"
+	"NezordLauncher/pkg/constants"
+	"NezordLauncher/pkg/network"
+	"bytes"
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"image"
+	"image/png"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+const (
+	DefaultProfileTTL = 1 * time.Hour
+	DefaultTextureTTL = 24 * time.Hour
+	maxTextureSize    = 1 << 20
+)
+
+type cachedProfile struct {
+	Textures  Textures  `json:"textures"`
+	FetchedAt time.Time `json:"fetchedAt"`
+}
+
+type cachedTexture struct {
+	File      string    `json:"file"`
+	ETag      string    `json:"etag,omitempty"`
+	FetchedAt time.Time `json:"fetchedAt"`
+}
+
+type cacheIndex struct {
+	Profiles map[string]cachedProfile `json:"profiles"`
+	Textures map[string]cachedTexture `json:"textures"`
+}
+
+// Cache stores resolved profile textures and their PNGs under <data>/skins/cache.
+type Cache struct {
+	mu         sync.Mutex
+	dir        string
+	ProfileTTL time.Duration
+	TextureTTL time.Duration
+	index      cacheIndex
+	loaded     bool
+	// Resolve looks up the textures of an account; defaults to its session server.
+	Resolve func(acc auth.Account) (*Textures, error)
+}
+
+func NewCache() *Cache {
+	return NewCacheAt(filepath.Join(constants.GetSkinsDir(), "cache"))
+}
+
+func NewCacheAt(dir string) *Cache {
+	return &Cache{
+		dir:        dir,
+		ProfileTTL: DefaultProfileTTL,
+		TextureTTL: DefaultTextureTTL,
+		index: cacheIndex{
+			Profiles: make(map[string]cachedProfile),
+			Textures: make(map[string]cachedTexture),
+		},
+		Resolve: func(acc auth.Account) (*Textures, error) {
+			return FetchTextures(SessionServerFor(acc), acc.UUID)
+		},
+	}
+}
+
+// ProfileTextures returns cached textures while fresh, and falls back to stale data on errors.
+func (c *Cache) ProfileTextures(acc auth.Account, force bool) (*Textures, error) {
+	c.mu.Lock()
+	c.loadInternal()
+	cached, ok := c.index.Profiles[acc.UUID]
+	c.mu.Unlock()
+
+	if ok && !force && time.Since(cached.FetchedAt) < c.ProfileTTL {
+		t := cached.Textures
+		return &t, nil
+	}
+
+	textures, err := c.Resolve(acc)
+	if err != nil {
+		if ok {
+			t := cached.Textures
+			return &t, nil
+		}
+		return nil, err
+	}
+
+	c.mu.Lock()
+	c.index.Profiles[acc.UUID] = cachedProfile{Textures: *textures, FetchedAt: time.Now()}
+	c.saveInternal()
+	c.mu.Unlock()
+
+	return textures, nil
+}
+
+// Texture returns the PNG behind a texture URL, revalidating with ETag once the TTL has passed.
+func (c *Cache) Texture(url string, force bool) ([]byte, error) {
+	c.mu.Lock()
+	c.loadInternal()
+	cached, ok := c.index.Textures[url]
+	c.mu.Unlock()
+
+	var existing []byte
+	if ok {
+		if data, err := os.ReadFile(filepath.Join(c.dir, cached.File)); err == nil {
+			existing = data
+		} else {
+			ok = false
+		}
+	}
+
+	if ok && !force && time.Since(cached.FetchedAt) < c.TextureTTL {
+		return existing, nil
+	}
+
+	etag := ""
+	if ok {
+		etag = cached.ETag
+	}
+
+	data, newETag, notModified, err := fetchTexture(url, etag)
+	if err != nil {
+		if ok {
+			return existing, nil
+		}
+		return nil, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if notModified && ok {
+		cached.FetchedAt = time.Now()
+		c.index.Textures[url] = cached
+		c.saveInternal()
+		return existing, nil
+	}
+
+	sum := sha1.Sum([]byte(url))
+	file := hex.EncodeToString(sum[:]) + ".png"
+	if err := os.MkdirAll(c.dir, 0755); err != nil {
+		return nil, err
+	}
+	if err := os.WriteFile(filepath.Join(c.dir, file), data, 0644); err != nil {
+		return nil, err
+	}
+
+	c.index.Textures[url] = cachedTexture{File: file, ETag: newETag, FetchedAt: time.Now()}
+	c.saveInternal()
+	return data, nil
+}
+
+// Skin returns the decoded skin image and its model for an account.
+func (c *Cache) Skin(acc auth.Account, force bool) (image.Image, string, error) {
+	textures, err := c.ProfileTextures(acc, force)
+	if err != nil {
+		return nil, "", err
+	}
+	if textures.SkinURL == "" {
+		return nil, "", ErrNoSkin
+	}
+
+	data, err := c.Texture(textures.SkinURL, force)
+	if err != nil {
+		return nil, "", err
+	}
+
+	img, err := png.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to decode skin: %w", err)
+	}
+	return img, textures.Model, nil
+}
+
+func (c *Cache) Invalidate(uuid string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.loadInternal()
+	delete(c.index.Profiles, uuid)
+	c.saveInternal()
+}
+
+func (c *Cache) loadInternal() {
+	if c.loaded {
+		return
+	}
+	c.loaded = true
+
+	data, err := os.ReadFile(filepath.Join(c.dir, "index.json"))
+	if err != nil {
+		return
+	}
+	var idx cacheIndex
+	if err := json.Unmarshal(data, &idx); err != nil {
+		return
+	}
+	if idx.Profiles != nil {
+		c.index.Profiles = idx.Profiles
+	}
+	if idx.Textures != nil {
+		c.index.Textures = idx.Textures
+	}
+}
+
+func (c *Cache) saveInternal() {
+	if err := os.MkdirAll(c.dir, 0755); err != nil {
+		return
+	}
+	data, err := json.MarshalIndent(c.index, "", "  ")
+	if err != nil {
+		return
+	}
+	_ = os.WriteFile(filepath.Join(c.dir, "index.json"), data, 0644)
+}
+
+func fetchTexture(url, etag string) ([]byte, string, bool, error) {
+	req, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		return nil, "", false, err
+	}
+	if etag != "" {
+		req.Header.Set("If-None-Match", etag)
+	}
+
+	client := network.NewHttpClient()
+	resp, err := client.DoWithRetry(req)
+	if err != nil {
+		return nil, "", false, fmt.Errorf("failed to download texture: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotModified {
+		return nil, etag, true, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, "", false, fmt.Errorf("texture download failed with status %d", resp.StatusCode)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxTextureSize+1))
+	if err != nil {
+		return nil, "", false, err
+	}
+	if len(data) > maxTextureSize {
+		return nil, "", false, fmt.Errorf("texture exceeds %d bytes", maxTextureSize)
+	}
+
+	return data, resp.Header.Get("ETag"), false, nil
+}
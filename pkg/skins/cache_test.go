@@ -0,0 +1,90 @@
+package skins
+
+import (
+	"NezordLauncher/pkg/auth"
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"image"
+	"image/png"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestCache_SkinFromSessionServer(t *testing.T) {
+	var buf bytes.Buffer
+	png.Encode(&buf, image.NewNRGBA(image.Rect(0, 0, 64, 64)))
+	skinPNG := buf.Bytes()
+
+	var profileHits, textureHits, notModified int32
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/session/minecraft/profile/0123456789abcdef0123456789abcdef":
+			atomic.AddInt32(&profileHits, 1)
+			textures := fmt.Sprintf(`{"textures":{"SKIN":{"url":"%s/skin.png","metadata":{"model":"slim"}}}}`, server.URL)
+			fmt.Fprintf(w, `{"id":"0123456789abcdef0123456789abcdef","name":"Steve","properties":[{"name":"textures","value":"%s"}]}`,
+				base64.StdEncoding.EncodeToString([]byte(textures)))
+		case "/skin.png":
+			atomic.AddInt32(&textureHits, 1)
+			if r.Header.Get("If-None-Match") == `"v1"` {
+				atomic.AddInt32(&notModified, 1)
+				w.WriteHeader(http.StatusNotModified)
+				return
+			}
+			w.Header().Set("ETag", `"v1"`)
+			w.Write(skinPNG)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	t.Setenv("NEZORD_MOJANG_SESSION_URL", server.URL)
+
+	cache := NewCacheAt(t.TempDir())
+	acc := auth.Account{UUID: "01234567-89ab-cdef-0123-456789abcdef", Type: auth.AccountTypeMicrosoft}
+
+	img, model, err := cache.Skin(acc, false)
+	if err != nil {
+		t.Fatalf("Skin failed: %v", err)
+	}
+	if model != ModelSlim || img.Bounds().Dx() != 64 {
+		t.Errorf("Unexpected skin: model=%s bounds=%v", model, img.Bounds())
+	}
+
+	if _, _, err := cache.Skin(acc, false); err != nil {
+		t.Fatalf("Cached Skin failed: %v", err)
+	}
+	if profileHits != 1 || textureHits != 1 {
+		t.Errorf("Expected cache hits, got profile=%d texture=%d", profileHits, textureHits)
+	}
+
+	cache.TextureTTL = time.Nanosecond
+	if _, _, err := cache.Skin(acc, false); err != nil {
+		t.Fatalf("Revalidated Skin failed: %v", err)
+	}
+	if notModified != 1 {
+		t.Errorf("Expected conditional request with ETag, got %d", notModified)
+	}
+
+	reloaded := NewCacheAt(cache.dir)
+	reloaded.Resolve = func(auth.Account) (*Textures, error) {
+		return nil, fmt.Errorf("offline")
+	}
+	reloaded.ProfileTTL = time.Nanosecond
+	if _, _, err := reloaded.Skin(acc, false); err != nil {
+		t.Errorf("Expected stale cache on error, got %v", err)
+	}
+}
+
+func TestCache_OfflineAccountHasNoSkin(t *testing.T) {
+	cache := NewCacheAt(t.TempDir())
+	_, _, err := cache.Skin(auth.Account{UUID: "x", Type: auth.AccountTypeOffline}, false)
+	if err != ErrNoSkin {
+		t.Errorf("Expected ErrNoSkin, got %v", err)
+	}
+}
@@ -0,0 +1,205 @@
+package skins
+
+import (
+	"NezordLauncher/pkg/constants"
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+type LibrarySkin struct {
+	ID      string    `json:"id"`
+	Name    string    `json:"name"`
+	Model   string    `json:"model"`
+	File    string    `json:"file"`
+	AddedAt time.Time `json:"addedAt"`
+}
+
+type LibraryData struct {
+	Skins []LibrarySkin `json:"skins"`
+}
+
+// Library is the user's personal collection of skins under <data>/skins/library.
+type Library struct {
+	mu       sync.RWMutex
+	dir      string
+	filePath string
+	Data     LibraryData
+}
+
+func NewLibrary() *Library {
+	return NewLibraryAt(filepath.Join(constants.GetSkinsDir(), "library"))
+}
+
+func NewLibraryAt(dir string) *Library {
+	return &Library{
+		dir:      dir,
+		filePath: filepath.Join(dir, "library.json"),
+		Data:     LibraryData{Skins: []LibrarySkin{}},
+	}
+}
+
+func (l *Library) Load() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if err := os.MkdirAll(l.dir, 0755); err != nil {
+		return err
+	}
+
+	data, err := os.ReadFile(l.filePath)
+	if os.IsNotExist(err) {
+		return l.saveInternal()
+	}
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(data, &l.Data)
+}
+
+func (l *Library) List() []LibrarySkin {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	result := make([]LibrarySkin, len(l.Data.Skins))
+	copy(result, l.Data.Skins)
+	return result
+}
+
+// Add validates and stores a skin. Identical PNGs are deduplicated by content hash.
+func (l *Library) Add(name string, data []byte, model string) (*LibrarySkin, error) {
+	if model == "" {
+		model = ModelClassic
+	}
+	if _, err := ValidateSkinPNG(data, model, true); err != nil {
+		return nil, err
+	}
+
+	sum := sha1.Sum(data)
+	id := hex.EncodeToString(sum[:])
+
+	name = strings.TrimSpace(name)
+	if name == "" {
+		name = "Skin " + id[:8]
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	for i, skin := range l.Data.Skins {
+		if skin.ID == id {
+			l.Data.Skins[i].Name = name
+			l.Data.Skins[i].Model = model
+			updated := l.Data.Skins[i]
+			return &updated, l.saveInternal()
+		}
+	}
+
+	skin := LibrarySkin{
+		ID:      id,
+		Name:    name,
+		Model:   model,
+		File:    id + ".png",
+		AddedAt: time.Now(),
+	}
+	if err := os.MkdirAll(l.dir, 0755); err != nil {
+		return nil, err
+	}
+	if err := os.WriteFile(filepath.Join(l.dir, skin.File), data, 0644); err != nil {
+		return nil, err
+	}
+
+	l.Data.Skins = append(l.Data.Skins, skin)
+	if err := l.saveInternal(); err != nil {
+		return nil, err
+	}
+	return &skin, nil
+}
+
+// Read returns the entry and its PNG bytes.
+func (l *Library) Read(id string) (*LibrarySkin, []byte, error) {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	for _, skin := range l.Data.Skins {
+		if skin.ID == id {
+			data, err := os.ReadFile(filepath.Join(l.dir, skin.File))
+			if err != nil {
+				return nil, nil, err
+			}
+			entry := skin
+			return &entry, data, nil
+		}
+	}
+	return nil, nil, fmt.Errorf("skin %s not found in library", id)
+}
+
+func (l *Library) Remove(id string) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	for i, skin := range l.Data.Skins {
+		if skin.ID == id {
+			l.Data.Skins = append(l.Data.Skins[:i], l.Data.Skins[i+1:]...)
+			if err := os.Remove(filepath.Join(l.dir, skin.File)); err != nil && !os.IsNotExist(err) {
+				return err
+			}
+			return l.saveInternal()
+		}
+	}
+	return fmt.Errorf("skin %s not found in library", id)
+}
+
+func (l *Library) saveInternal() error {
+	data, err := json.MarshalIndent(l.Data, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(l.filePath, data, 0644)
+}
+
+type exportedSkin struct {
+	Name  string `json:"name"`
+	Model string `json:"model"`
+	PNG   []byte `json:"png"`
+}
+
+// Export serializes the whole library, PNGs included, for account bundles.
+func (l *Library) Export() ([]byte, error) {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	exported := make([]exportedSkin, 0, len(l.Data.Skins))
+	for _, skin := range l.Data.Skins {
+		data, err := os.ReadFile(filepath.Join(l.dir, skin.File))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read skin %s: %w", skin.Name, err)
+		}
+		exported = append(exported, exportedSkin{Name: skin.Name, Model: skin.Model, PNG: data})
+	}
+	return json.Marshal(exported)
+}
+
+// Import adds the skins of an exported library and returns how many were stored.
+func (l *Library) Import(data []byte) (int, error) {
+	var exported []exportedSkin
+	if err := json.Unmarshal(data, &exported); err != nil {
+		return 0, fmt.Errorf("failed to parse skin library: %w", err)
+	}
+
+	count := 0
+	for _, skin := range exported {
+		if _, err := l.Add(skin.Name, skin.PNG, skin.Model); err != nil {
+			return count, err
+		}
+		count++
+	}
+	return count, nil
+}
@@ -0,0 +1,195 @@
+package skins
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+)
+
+type region struct {
+	X, Y, W, H int
+}
+
+// Front faces in 64x64 skin coordinates.
+var (
+	headFront     = region{8, 8, 8, 8}
+	hatFront      = region{40, 8, 8, 8}
+	bodyFront     = region{20, 20, 8, 12}
+	jacketFront   = region{20, 36, 8, 12}
+	rightArmFront = region{44, 20, 4, 12}
+	rightSleeve   = region{44, 36, 4, 12}
+	leftArmFront  = region{36, 52, 4, 12}
+	leftSleeve    = region{52, 52, 4, 12}
+	rightLegFront = region{4, 20, 4, 12}
+	rightPants    = region{4, 36, 4, 12}
+	leftLegFront  = region{20, 52, 4, 12}
+	leftPants     = region{4, 52, 4, 12}
+)
+
+// IsLegacySkin reports whether the skin uses the pre-1.8 64x32 layout.
+func IsLegacySkin(skin image.Image) bool {
+	b := skin.Bounds()
+	return b.Dx() == b.Dy()*2
+}
+
+func ValidateSkinImage(skin image.Image) error {
+	b := skin.Bounds()
+	if b.Dx() < 64 || b.Dx()%64 != 0 {
+		return fmt.Errorf("skin width must be a multiple of 64, got %d", b.Dx())
+	}
+	if b.Dy() != b.Dx() && b.Dy()*2 != b.Dx() {
+		return fmt.Errorf("skin must be %dx%d or %dx%d, got %dx%d", b.Dx(), b.Dx(), b.Dx(), b.Dx()/2, b.Dx(), b.Dy())
+	}
+	return nil
+}
+
+// RenderHead draws the face with the hat overlay, scaled to size x size.
+func RenderHead(skin image.Image, size int) (*image.NRGBA, error) {
+	if err := ValidateSkinImage(skin); err != nil {
+		return nil, err
+	}
+	if size < 8 {
+		size = 8
+	}
+
+	scale := skin.Bounds().Dx() / 64
+	legacy := IsLegacySkin(skin)
+
+	head := image.NewNRGBA(image.Rect(0, 0, 8*scale, 8*scale))
+	blit(head, 0, 0, skin, headFront, scale, false, false)
+	if !legacy || hasTransparency(skin, hatFront, scale) {
+		blit(head, 0, 0, skin, hatFront, scale, true, false)
+	}
+
+	return resizeNearest(head, size, size), nil
+}
+
+// RenderBody draws the front view of the player, 16x32 skin pixels scaled by scale.
+func RenderBody(skin image.Image, slim bool, scale int) (*image.NRGBA, error) {
+	if err := ValidateSkinImage(skin); err != nil {
+		return nil, err
+	}
+	if scale < 1 {
+		scale = 1
+	}
+
+	factor := skin.Bounds().Dx() / 64
+	legacy := IsLegacySkin(skin)
+
+	armW := 4
+	if slim {
+		armW = 3
+	}
+	rightArm := region{rightArmFront.X, rightArmFront.Y, armW, 12}
+	leftArm := region{leftArmFront.X, leftArmFront.Y, armW, 12}
+
+	canvas := image.NewNRGBA(image.Rect(0, 0, 16*factor, 32*factor))
+	px := func(v int) int { return v * factor }
+
+	blit(canvas, px(4), 0, skin, headFront, factor, false, false)
+	blit(canvas, px(4), px(8), skin, bodyFront, factor, false, false)
+	blit(canvas, px(4-armW), px(8), skin, rightArm, factor, false, false)
+	blit(canvas, px(4), px(20), skin, rightLegFront, factor, false, false)
+
+	if legacy {
+		blit(canvas, px(12), px(8), skin, rightArm, factor, false, true)
+		blit(canvas, px(8), px(20), skin, rightLegFront, factor, false, true)
+		if hasTransparency(skin, hatFront, factor) {
+			blit(canvas, px(4), 0, skin, hatFront, factor, true, false)
+		}
+	} else {
+		blit(canvas, px(12), px(8), skin, leftArm, factor, false, false)
+		blit(canvas, px(8), px(20), skin, leftLegFront, factor, false, false)
+
+		blit(canvas, px(4), 0, skin, hatFront, factor, true, false)
+		blit(canvas, px(4), px(8), skin, jacketFront, factor, true, false)
+		blit(canvas, px(4-armW), px(8), skin, region{rightSleeve.X, rightSleeve.Y, armW, 12}, factor, true, false)
+		blit(canvas, px(12), px(8), skin, region{leftSleeve.X, leftSleeve.Y, armW, 12}, factor, true, false)
+		blit(canvas, px(4), px(20), skin, rightPants, factor, true, false)
+		blit(canvas, px(8), px(20), skin, leftPants, factor, true, false)
+	}
+
+	return resizeNearest(canvas, 16*scale, 32*scale), nil
+}
+
+func EncodePNG(img image.Image) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// blit copies a skin region to dst. Base layers are drawn opaque like the game does;
+// overlays are alpha-composited. mirror flips the region horizontally.
+func blit(dst *image.NRGBA, dx, dy int, skin image.Image, r region, factor int, overlay, mirror bool) {
+	origin := skin.Bounds().Min
+	w, h := r.W*factor, r.H*factor
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			sx := x
+			if mirror {
+				sx = w - 1 - x
+			}
+			c := color.NRGBAModel.Convert(skin.At(origin.X+r.X*factor+sx, origin.Y+r.Y*factor+y)).(color.NRGBA)
+			if !overlay {
+				c.A = 255
+				dst.SetNRGBA(dx+x, dy+y, c)
+				continue
+			}
+			if c.A == 0 {
+				continue
+			}
+			dst.SetNRGBA(dx+x, dy+y, blend(dst.NRGBAAt(dx+x, dy+y), c))
+		}
+	}
+}
+
+func blend(dst, src color.NRGBA) color.NRGBA {
+	if src.A == 255 {
+		return src
+	}
+	sa := uint32(src.A)
+	da := uint32(dst.A) * (255 - sa) / 255
+	outA := sa + da
+	if outA == 0 {
+		return color.NRGBA{}
+	}
+	mix := func(s, d uint8) uint8 {
+		return uint8((uint32(s)*sa + uint32(d)*da) / outA)
+	}
+	return color.NRGBA{R: mix(src.R, dst.R), G: mix(src.G, dst.G), B: mix(src.B, dst.B), A: uint8(outA)}
+}
+
+// hasTransparency mirrors the game's legacy skin handling: a 64x32 hat layer that is
+// completely opaque is treated as unused.
+func hasTransparency(skin image.Image, r region, factor int) bool {
+	origin := skin.Bounds().Min
+	for y := 0; y < r.H*factor; y++ {
+		for x := 0; x < r.W*factor; x++ {
+			_, _, _, a := skin.At(origin.X+r.X*factor+x, origin.Y+r.Y*factor+y).RGBA()
+			if a < 0x8000 {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func resizeNearest(src *image.NRGBA, w, h int) *image.NRGBA {
+	b := src.Bounds()
+	if b.Dx() == w && b.Dy() == h {
+		return src
+	}
+	dst := image.NewNRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		sy := b.Min.Y + y*b.Dy()/h
+		for x := 0; x < w; x++ {
+			sx := b.Min.X + x*b.Dx()/w
+			dst.SetNRGBA(x, y, src.NRGBAAt(sx, sy))
+		}
+	}
+	return dst
+}
@@ -0,0 +1,96 @@
+package skins
+
+import (
+	"image"
+	"image/color"
+	"testing"
+)
+
+func fillRegion(img *image.NRGBA, r region, c color.NRGBA) {
+	for y := r.Y; y < r.Y+r.H; y++ {
+		for x := r.X; x < r.X+r.W; x++ {
+			img.SetNRGBA(x, y, c)
+		}
+	}
+}
+
+func TestRenderHead_HatOverlay(t *testing.T) {
+	skin := image.NewNRGBA(image.Rect(0, 0, 64, 64))
+	red := color.NRGBA{255, 0, 0, 255}
+	blue := color.NRGBA{0, 0, 255, 255}
+	fillRegion(skin, headFront, red)
+	skin.SetNRGBA(hatFront.X, hatFront.Y, blue)
+
+	head, err := RenderHead(skin, 32)
+	if err != nil {
+		t.Fatalf("RenderHead failed: %v", err)
+	}
+	if head.Bounds().Dx() != 32 || head.Bounds().Dy() != 32 {
+		t.Fatalf("Unexpected size %v", head.Bounds())
+	}
+	if head.NRGBAAt(0, 0) != blue {
+		t.Errorf("Expected hat pixel on top, got %v", head.NRGBAAt(0, 0))
+	}
+	if head.NRGBAAt(31, 31) != red {
+		t.Errorf("Expected face pixel, got %v", head.NRGBAAt(31, 31))
+	}
+}
+
+func TestRenderHead_LegacyOpaqueHatIgnored(t *testing.T) {
+	skin := image.NewNRGBA(image.Rect(0, 0, 64, 32))
+	red := color.NRGBA{255, 0, 0, 255}
+	fillRegion(skin, headFront, red)
+	fillRegion(skin, hatFront, color.NRGBA{0, 0, 0, 255})
+
+	head, err := RenderHead(skin, 8)
+	if err != nil {
+		t.Fatalf("RenderHead failed: %v", err)
+	}
+	if head.NRGBAAt(4, 4) != red {
+		t.Errorf("Opaque legacy hat should be skipped, got %v", head.NRGBAAt(4, 4))
+	}
+}
+
+func TestRenderBody_LegacyMirrorsLimbs(t *testing.T) {
+	skin := image.NewNRGBA(image.Rect(0, 0, 64, 32))
+	green := color.NRGBA{0, 255, 0, 255}
+	skin.SetNRGBA(rightArmFront.X, rightArmFront.Y, green)
+
+	body, err := RenderBody(skin, false, 1)
+	if err != nil {
+		t.Fatalf("RenderBody failed: %v", err)
+	}
+	if body.Bounds().Dx() != 16 || body.Bounds().Dy() != 32 {
+		t.Fatalf("Unexpected size %v", body.Bounds())
+	}
+	if body.NRGBAAt(0, 8) != green {
+		t.Errorf("Right arm not drawn, got %v", body.NRGBAAt(0, 8))
+	}
+	if body.NRGBAAt(15, 8) != green {
+		t.Errorf("Left arm should mirror the right arm, got %v", body.NRGBAAt(15, 8))
+	}
+}
+
+func TestRenderBody_SlimArms(t *testing.T) {
+	skin := image.NewNRGBA(image.Rect(0, 0, 64, 64))
+
+	body, err := RenderBody(skin, true, 2)
+	if err != nil {
+		t.Fatalf("RenderBody failed: %v", err)
+	}
+	if body.NRGBAAt(0, 20).A != 0 {
+		t.Errorf("Slim arm should leave the outer column empty")
+	}
+	if body.NRGBAAt(2, 20).A != 255 {
+		t.Errorf("Slim arm should be drawn from x=1")
+	}
+}
+
+func TestValidateSkinImage(t *testing.T) {
+	if err := ValidateSkinImage(image.NewNRGBA(image.Rect(0, 0, 64, 48))); err == nil {
+		t.Error("Expected error for 64x48 skin")
+	}
+	if err := ValidateSkinImage(image.NewNRGBA(image.Rect(0, 0, 128, 128))); err != nil {
+		t.Errorf("HD skin rejected: %v", err)
+	}
+}
@@ -0,0 +1,119 @@
+package skins
+
+import (
+	"NezordLauncher/pkg/auth"
+	"NezordLauncher/pkg/network"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+const (
+	MojangSessionServerURL = "https://sessionserver.mojang.com"
+	ElyBySessionServerURL  = "https://authserver.ely.by/api/authlib-injector/sessionserver"
+
+	ModelClassic = "classic"
+	ModelSlim    = "slim"
+)
+
+var ErrNoSkin = errors.New("account has no custom skin")
+
+type Textures struct {
+	SkinURL string `json:"skinUrl,omitempty"`
+	CapeURL string `json:"capeUrl,omitempty"`
+	Model   string `json:"model"`
+}
+
+type sessionProfile struct {
+	ID         string `json:"id"`
+	Name       string `json:"name"`
+	Properties []struct {
+		Name  string `json:"name"`
+		Value string `json:"value"`
+	} `json:"properties"`
+}
+
+type texturesPayload struct {
+	Textures map[string]struct {
+		URL      string `json:"url"`
+		Metadata struct {
+			Model string `json:"model"`
+		} `json:"metadata"`
+	} `json:"textures"`
+}
+
+// SessionServerFor returns the session server root that publishes textures for the account.
+func SessionServerFor(acc auth.Account) string {
+	switch acc.Type {
+	case auth.AccountTypeMicrosoft:
+		if override := os.Getenv("NEZORD_MOJANG_SESSION_URL"); override != "" {
+			return override
+		}
+		return MojangSessionServerURL
+	case auth.AccountTypeElyBy:
+		if override := os.Getenv("NEZORD_ELYBY_SESSION_URL"); override != "" {
+			return override
+		}
+		return ElyBySessionServerURL
+	case auth.AccountTypeYggdrasil:
+		if acc.Server != nil {
+			return acc.Server.SessionServerURL()
+		}
+	}
+	return ""
+}
+
+func FetchTextures(sessionServer, uuid string) (*Textures, error) {
+	if sessionServer == "" {
+		return nil, ErrNoSkin
+	}
+
+	compact := strings.ReplaceAll(uuid, "-", "")
+	url := fmt.Sprintf("%s/session/minecraft/profile/%s", strings.TrimSuffix(sessionServer, "/"), compact)
+
+	client := network.NewHttpClient()
+	data, err := client.Get(url)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch profile textures: %w", err)
+	}
+
+	var profile sessionProfile
+	if err := json.Unmarshal(data, &profile); err != nil {
+		return nil, fmt.Errorf("failed to parse session profile: %w", err)
+	}
+
+	for _, prop := range profile.Properties {
+		if prop.Name == "textures" {
+			return DecodeTexturesProperty(prop.Value)
+		}
+	}
+	return &Textures{Model: ModelClassic}, nil
+}
+
+// DecodeTexturesProperty parses the base64 "textures" profile property.
+func DecodeTexturesProperty(value string) (*Textures, error) {
+	raw, err := base64.StdEncoding.DecodeString(value)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode textures property: %w", err)
+	}
+
+	var payload texturesPayload
+	if err := json.Unmarshal(raw, &payload); err != nil {
+		return nil, fmt.Errorf("failed to parse textures property: %w", err)
+	}
+
+	textures := &Textures{Model: ModelClassic}
+	if skin, ok := payload.Textures["SKIN"]; ok {
+		textures.SkinURL = skin.URL
+		if skin.Metadata.Model == ModelSlim {
+			textures.Model = ModelSlim
+		}
+	}
+	if cape, ok := payload.Textures["CAPE"]; ok {
+		textures.CapeURL = cape.URL
+	}
+	return textures, nil
+}
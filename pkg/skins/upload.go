@@ -0,0 +1,261 @@
+package skins
+
+import (
+	"NezordLauncher/pkg/auth"
+	"NezordLauncher/pkg/network"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"os"
+	"strings"
+)
+
+const ElyByAPIRoot = "https://authserver.ely.by/api/authlib-injector"
+
+var (
+	ErrTexturesUnsupported = errors.New("this account type does not support changing textures")
+	ErrCapeUnsupported     = errors.New("this account type does not support this cape operation")
+)
+
+// ServicesProfile is the Minecraft services view of a Microsoft account profile.
+type ServicesProfile struct {
+	ID    string         `json:"id"`
+	Name  string         `json:"name"`
+	Skins []ServicesSkin `json:"skins"`
+	Capes []ServicesCape `json:"capes"`
+}
+
+type ServicesSkin struct {
+	ID      string `json:"id"`
+	State   string `json:"state"`
+	URL     string `json:"url"`
+	Variant string `json:"variant"`
+}
+
+type ServicesCape struct {
+	ID    string `json:"id"`
+	State string `json:"state"`
+	URL   string `json:"url"`
+	Alias string `json:"alias"`
+}
+
+// TextureError is a non-2xx answer from a profile or texture endpoint.
+type TextureError struct {
+	StatusCode int
+	Message    string
+}
+
+func (e *TextureError) Error() string {
+	if e.Message != "" {
+		return fmt.Sprintf("texture request failed (%d): %s", e.StatusCode, e.Message)
+	}
+	return fmt.Sprintf("texture request failed with status %d", e.StatusCode)
+}
+
+func servicesProfileURL() string {
+	if override := os.Getenv("NEZORD_MC_PROFILE_URL"); override != "" {
+		return strings.TrimSuffix(override, "/")
+	}
+	return auth.MinecraftProfileURL
+}
+
+// yggdrasilTextureURL points at the authlib-injector texture upload endpoint for the account.
+func yggdrasilTextureURL(acc auth.Account, textureType string) (string, error) {
+	root := ""
+	switch acc.Type {
+	case auth.AccountTypeElyBy:
+		root = ElyByAPIRoot
+		if override := os.Getenv("NEZORD_ELYBY_API_URL"); override != "" {
+			root = override
+		}
+	case auth.AccountTypeYggdrasil:
+		if acc.Server == nil {
+			return "", fmt.Errorf("account has no Yggdrasil server configured")
+		}
+		root = acc.Server.APIRoot
+	default:
+		return "", ErrTexturesUnsupported
+	}
+	compact := strings.ReplaceAll(acc.UUID, "-", "")
+	return fmt.Sprintf("%s/api/user/profile/%s/%s", strings.TrimSuffix(root, "/"), compact, textureType), nil
+}
+
+func FetchServicesProfile(ctx context.Context, acc auth.Account) (*ServicesProfile, error) {
+	if acc.Type != auth.AccountTypeMicrosoft {
+		return nil, ErrCapeUnsupported
+	}
+	data, err := textureRequest(ctx, "GET", servicesProfileURL(), acc.AccessToken, "", nil)
+	if err != nil {
+		return nil, err
+	}
+	var profile ServicesProfile
+	if err := json.Unmarshal(data, &profile); err != nil {
+		return nil, fmt.Errorf("failed to parse profile: %w", err)
+	}
+	return &profile, nil
+}
+
+func UploadSkin(ctx context.Context, acc auth.Account, data []byte, model string) error {
+	if model == "" {
+		model = ModelClassic
+	}
+	if _, err := ValidateSkinPNG(data, model, acc.Type != auth.AccountTypeMicrosoft); err != nil {
+		return err
+	}
+
+	switch acc.Type {
+	case auth.AccountTypeMicrosoft:
+		body, contentType, err := multipartTexture(map[string]string{"variant": model}, data)
+		if err != nil {
+			return err
+		}
+		_, err = textureRequest(ctx, "POST", servicesProfileURL()+"/skins", acc.AccessToken, contentType, body)
+		return err
+	default:
+		endpoint, err := yggdrasilTextureURL(acc, "skin")
+		if err != nil {
+			return err
+		}
+		// authlib-injector uses an empty model for the classic (Steve) arms.
+		fields := map[string]string{"model": ""}
+		if model == ModelSlim {
+			fields["model"] = ModelSlim
+		}
+		body, contentType, err := multipartTexture(fields, data)
+		if err != nil {
+			return err
+		}
+		_, err = textureRequest(ctx, "PUT", endpoint, acc.AccessToken, contentType, body)
+		return err
+	}
+}
+
+func ResetSkin(ctx context.Context, acc auth.Account) error {
+	if acc.Type == auth.AccountTypeMicrosoft {
+		_, err := textureRequest(ctx, "DELETE", servicesProfileURL()+"/skins/active", acc.AccessToken, "", nil)
+		return err
+	}
+	endpoint, err := yggdrasilTextureURL(acc, "skin")
+	if err != nil {
+		return err
+	}
+	_, err = textureRequest(ctx, "DELETE", endpoint, acc.AccessToken, "", nil)
+	return err
+}
+
+// EquipCape activates one of the capes owned by a Microsoft account.
+func EquipCape(ctx context.Context, acc auth.Account, capeID string) error {
+	if acc.Type != auth.AccountTypeMicrosoft {
+		return ErrCapeUnsupported
+	}
+	payload, err := json.Marshal(map[string]string{"capeId": capeID})
+	if err != nil {
+		return err
+	}
+	_, err = textureRequest(ctx, "PUT", servicesProfileURL()+"/capes/active", acc.AccessToken, "application/json", payload)
+	return err
+}
+
+func HideCape(ctx context.Context, acc auth.Account) error {
+	if acc.Type == auth.AccountTypeMicrosoft {
+		_, err := textureRequest(ctx, "DELETE", servicesProfileURL()+"/capes/active", acc.AccessToken, "", nil)
+		return err
+	}
+	endpoint, err := yggdrasilTextureURL(acc, "cape")
+	if err != nil {
+		return err
+	}
+	_, err = textureRequest(ctx, "DELETE", endpoint, acc.AccessToken, "", nil)
+	return err
+}
+
+// UploadCape sets a custom cape; only Yggdrasil-compatible servers allow this.
+func UploadCape(ctx context.Context, acc auth.Account, data []byte) error {
+	if acc.Type == auth.AccountTypeMicrosoft {
+		return ErrCapeUnsupported
+	}
+	if err := ValidateCapePNG(data); err != nil {
+		return err
+	}
+	endpoint, err := yggdrasilTextureURL(acc, "cape")
+	if err != nil {
+		return err
+	}
+	body, contentType, err := multipartTexture(nil, data)
+	if err != nil {
+		return err
+	}
+	_, err = textureRequest(ctx, "PUT", endpoint, acc.AccessToken, contentType, body)
+	return err
+}
+
+func multipartTexture(fields map[string]string, data []byte) ([]byte, string, error) {
+	var buf bytes.Buffer
+	writer := multipart.NewWriter(&buf)
+	for key, value := range fields {
+		if err := writer.WriteField(key, value); err != nil {
+			return nil, "", err
+		}
+	}
+	part, err := writer.CreateFormFile("file", "texture.png")
+	if err != nil {
+		return nil, "", err
+	}
+	if _, err := part.Write(data); err != nil {
+		return nil, "", err
+	}
+	if err := writer.Close(); err != nil {
+		return nil, "", err
+	}
+	return buf.Bytes(), writer.FormDataContentType(), nil
+}
+
+func textureRequest(ctx context.Context, method, endpoint, bearer, contentType string, body []byte) ([]byte, error) {
+	var reader io.Reader
+	if body != nil {
+		reader = bytes.NewReader(body)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, endpoint, reader)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+bearer)
+	req.Header.Set("Accept", "application/json")
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+
+	resp, err := network.NewHttpClient().Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("texture request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, &TextureError{StatusCode: resp.StatusCode, Message: textureErrorMessage(data)}
+	}
+	return data, nil
+}
+
+func textureErrorMessage(data []byte) string {
+	var payload struct {
+		ErrorMessage string `json:"errorMessage"`
+		Error        string `json:"error"`
+	}
+	if json.Unmarshal(data, &payload) == nil {
+		if payload.ErrorMessage != "" {
+			return payload.ErrorMessage
+		}
+		return payload.Error
+	}
+	return ""
+}
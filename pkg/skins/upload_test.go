@@ -0,0 +1,197 @@
+package skins
+
+import (
+	"NezordLauncher/pkg/auth"
+	"bytes"
+	"context"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func testSkinPNG(t *testing.T, w, h int) []byte {
+	t.Helper()
+	img := image.NewNRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			img.SetNRGBA(x, y, color.NRGBA{120, 80, 40, 255})
+		}
+	}
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestUploadSkin_Microsoft(t *testing.T) {
+	skin := testSkinPNG(t, 64, 64)
+	var gotVariant string
+	var gotFile []byte
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer mc-token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		switch {
+		case r.Method == "POST" && r.URL.Path == "/minecraft/profile/skins":
+			gotVariant = r.FormValue("variant")
+			file, _, err := r.FormFile("file")
+			if err != nil {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			gotFile, _ = io.ReadAll(file)
+			w.Write([]byte(`{}`))
+		case r.Method == "PUT" && r.URL.Path == "/minecraft/profile/capes/active":
+			w.Write([]byte(`{}`))
+		case r.Method == "DELETE" && r.URL.Path == "/minecraft/profile/capes/active":
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"errorMessage":"cape locked"}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	t.Setenv("NEZORD_MC_PROFILE_URL", server.URL+"/minecraft/profile")
+	acc := auth.Account{UUID: "01234567-89ab-cdef-0123-456789abcdef", Type: auth.AccountTypeMicrosoft, AccessToken: "mc-token"}
+
+	if err := UploadSkin(context.Background(), acc, skin, ModelSlim); err != nil {
+		t.Fatalf("UploadSkin failed: %v", err)
+	}
+	if gotVariant != ModelSlim || !bytes.Equal(gotFile, skin) {
+		t.Errorf("Unexpected upload: variant=%s size=%d", gotVariant, len(gotFile))
+	}
+
+	if err := EquipCape(context.Background(), acc, "cape-1"); err != nil {
+		t.Errorf("EquipCape failed: %v", err)
+	}
+
+	err := HideCape(context.Background(), acc)
+	texErr, ok := err.(*TextureError)
+	if !ok || texErr.Message != "cape locked" {
+		t.Errorf("Expected TextureError, got %v", err)
+	}
+
+	if err := UploadSkin(context.Background(), acc, testSkinPNG(t, 128, 128), ModelClassic); err == nil {
+		t.Error("Expected HD skin to be rejected for Microsoft accounts")
+	}
+}
+
+func TestUploadSkin_Yggdrasil(t *testing.T) {
+	var gotModel, gotMethod string
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/yggdrasil/api/user/profile/0123456789abcdef0123456789abcdef/skin" {
+			http.NotFound(w, r)
+			return
+		}
+		gotMethod = r.Method
+		if r.Method == "PUT" {
+			gotModel = r.FormValue("model")
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	acc := auth.Account{
+		UUID:        "01234567-89ab-cdef-0123-456789abcdef",
+		Type:        auth.AccountTypeYggdrasil,
+		AccessToken: "ygg-token",
+		Server:      &auth.YggdrasilServer{APIRoot: server.URL + "/api/yggdrasil"},
+	}
+
+	if err := UploadSkin(context.Background(), acc, testSkinPNG(t, 64, 64), ModelClassic); err != nil {
+		t.Fatalf("UploadSkin failed: %v", err)
+	}
+	if gotMethod != "PUT" || gotModel != "" {
+		t.Errorf("Unexpected request: method=%s model=%q", gotMethod, gotModel)
+	}
+
+	if err := ResetSkin(context.Background(), acc); err != nil || gotMethod != "DELETE" {
+		t.Errorf("ResetSkin failed: %v (%s)", err, gotMethod)
+	}
+
+	if err := EquipCape(context.Background(), acc, "cape"); err != ErrCapeUnsupported {
+		t.Errorf("Expected ErrCapeUnsupported, got %v", err)
+	}
+	if err := UploadSkin(context.Background(), auth.Account{Type: auth.AccountTypeOffline}, testSkinPNG(t, 64, 64), ""); err != ErrTexturesUnsupported {
+		t.Errorf("Expected ErrTexturesUnsupported, got %v", err)
+	}
+}
+
+func TestValidateSkinPNG(t *testing.T) {
+	report, err := ValidateSkinPNG(testSkinPNG(t, 64, 32), ModelClassic, false)
+	if err != nil {
+		t.Fatalf("Legacy skin rejected: %v", err)
+	}
+	if !report.Legacy || len(report.Warnings) != 0 {
+		t.Errorf("Unexpected report: %+v", report)
+	}
+
+	var buf bytes.Buffer
+	png.Encode(&buf, image.NewNRGBA(image.Rect(0, 0, 64, 64)))
+	if _, err := ValidateSkinPNG(buf.Bytes(), ModelClassic, false); err != ErrEmptySkin {
+		t.Errorf("Expected ErrEmptySkin, got %v", err)
+	}
+
+	partial := image.NewNRGBA(image.Rect(0, 0, 64, 64))
+	fillRegion(partial, region{0, 0, 64, 32}, color.NRGBA{10, 10, 10, 255})
+	buf.Reset()
+	png.Encode(&buf, partial)
+	report, err = ValidateSkinPNG(buf.Bytes(), ModelClassic, false)
+	if err != nil {
+		t.Fatalf("ValidateSkinPNG failed: %v", err)
+	}
+	if len(report.Warnings) != 1 {
+		t.Errorf("Expected a transparency warning for missing left limbs, got %v", report.Warnings)
+	}
+
+	if _, err := ValidateSkinPNG([]byte("not a png"), "", false); err == nil {
+		t.Error("Expected error for invalid PNG")
+	}
+}
+
+func TestLibrary_AddDedupAndRemove(t *testing.T) {
+	lib := NewLibraryAt(t.TempDir())
+	if err := lib.Load(); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+
+	skin := testSkinPNG(t, 64, 64)
+	first, err := lib.Add("Knight", skin, ModelClassic)
+	if err != nil {
+		t.Fatalf("Add failed: %v", err)
+	}
+	if _, err := lib.Add("Knight (slim)", skin, ModelSlim); err != nil {
+		t.Fatalf("Re-add failed: %v", err)
+	}
+	if len(lib.List()) != 1 {
+		t.Fatalf("Expected duplicate PNG to be merged, got %d entries", len(lib.List()))
+	}
+
+	reloaded := NewLibraryAt(lib.dir)
+	if err := reloaded.Load(); err != nil {
+		t.Fatalf("Reload failed: %v", err)
+	}
+	entry, data, err := reloaded.Read(first.ID)
+	if err != nil {
+		t.Fatalf("Read failed: %v", err)
+	}
+	if entry.Model != ModelSlim || entry.Name != "Knight (slim)" || !bytes.Equal(data, skin) {
+		t.Errorf("Unexpected entry: %+v", entry)
+	}
+
+	if err := reloaded.Remove(first.ID); err != nil {
+		t.Fatalf("Remove failed: %v", err)
+	}
+	if len(reloaded.List()) != 0 {
+		t.Error("Expected empty library after remove")
+	}
+}
@@ -0,0 +1,140 @@
+package skins
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/png"
+)
+
+const (
+	MaxSkinFileSize = 256 * 1024
+	MaxCapeFileSize = 256 * 1024
+)
+
+var ErrEmptySkin = errors.New("skin has no visible pixels")
+
+// SkinReport describes a skin that passed local validation. Warnings do not block an upload.
+type SkinReport struct {
+	Width    int      `json:"width"`
+	Height   int      `json:"height"`
+	Legacy   bool     `json:"legacy"`
+	Warnings []string `json:"warnings,omitempty"`
+}
+
+// box is a cuboid in skin UV space: the six faces unwrap around (U, V).
+type box struct {
+	U, V, W, H, D int
+}
+
+func (b box) faces() []region {
+	return []region{
+		{b.U + b.D, b.V, b.W, b.D},
+		{b.U + b.D + b.W, b.V, b.W, b.D},
+		{b.U, b.V + b.D, b.D, b.H},
+		{b.U + b.D, b.V + b.D, b.W, b.H},
+		{b.U + b.D + b.W, b.V + b.D, b.D, b.H},
+		{b.U + 2*b.D + b.W, b.V + b.D, b.W, b.H},
+	}
+}
+
+func baseLayer(legacy, slim bool) []box {
+	armW := 4
+	if slim {
+		armW = 3
+	}
+	boxes := []box{
+		{0, 0, 8, 8, 8},
+		{16, 16, 8, 12, 4},
+		{40, 16, armW, 12, 4},
+		{0, 16, 4, 12, 4},
+	}
+	if !legacy {
+		boxes = append(boxes, box{32, 48, armW, 12, 4}, box{16, 48, 4, 12, 4})
+	}
+	return boxes
+}
+
+// ValidateSkinPNG checks file size, format, dimensions and base-layer transparency.
+// Mojang only accepts 64x64 and 64x32 skins, so HD sizes are opt-in.
+func ValidateSkinPNG(data []byte, model string, allowHD bool) (*SkinReport, error) {
+	if len(data) == 0 {
+		return nil, fmt.Errorf("skin file is empty")
+	}
+	if len(data) > MaxSkinFileSize {
+		return nil, fmt.Errorf("skin file is %d bytes, limit is %d", len(data), MaxSkinFileSize)
+	}
+	if model != "" && model != ModelClassic && model != ModelSlim {
+		return nil, fmt.Errorf("unknown skin model: %s", model)
+	}
+
+	img, err := png.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("skin is not a valid PNG: %w", err)
+	}
+	if err := ValidateSkinImage(img); err != nil {
+		return nil, err
+	}
+
+	b := img.Bounds()
+	if !allowHD && b.Dx() != 64 {
+		return nil, fmt.Errorf("skin must be 64x64 or 64x32, got %dx%d", b.Dx(), b.Dy())
+	}
+
+	report := &SkinReport{Width: b.Dx(), Height: b.Dy(), Legacy: IsLegacySkin(img)}
+	factor := b.Dx() / 64
+
+	if countTransparent(img, region{0, 0, 64, b.Dy() / factor}, factor) == (b.Dx() * b.Dy()) {
+		return nil, ErrEmptySkin
+	}
+
+	transparent := 0
+	for _, bx := range baseLayer(report.Legacy, model == ModelSlim) {
+		for _, face := range bx.faces() {
+			transparent += countTransparent(img, face, factor)
+		}
+	}
+	if transparent > 0 {
+		report.Warnings = append(report.Warnings,
+			fmt.Sprintf("%d transparent pixels in the base layer will render as solid black", transparent))
+	}
+	if report.Legacy && model == ModelSlim {
+		report.Warnings = append(report.Warnings, "legacy 64x32 skins have no slim arm layout")
+	}
+
+	return report, nil
+}
+
+// ValidateCapePNG accepts the 64x32 cape layout and its HD multiples.
+func ValidateCapePNG(data []byte) error {
+	if len(data) == 0 {
+		return fmt.Errorf("cape file is empty")
+	}
+	if len(data) > MaxCapeFileSize {
+		return fmt.Errorf("cape file is %d bytes, limit is %d", len(data), MaxCapeFileSize)
+	}
+
+	cfg, err := png.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("cape is not a valid PNG: %w", err)
+	}
+	if cfg.Width < 64 || cfg.Width%64 != 0 || cfg.Height*2 != cfg.Width {
+		return fmt.Errorf("cape must be 64x32 or a multiple of it, got %dx%d", cfg.Width, cfg.Height)
+	}
+	return nil
+}
+
+func countTransparent(img image.Image, r region, factor int) int {
+	origin := img.Bounds().Min
+	count := 0
+	for y := 0; y < r.H*factor; y++ {
+		for x := 0; x < r.W*factor; x++ {
+			_, _, _, a := img.At(origin.X+r.X*factor+x, origin.Y+r.Y*factor+y).RGBA()
+			if a == 0 {
+				count++
+			}
+		}
+	}
+	return count
+}